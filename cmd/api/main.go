@@ -10,16 +10,23 @@ import (
 	"time"
 
 	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/auth"
 	"github.com/danielMensah/user-management/internal/config"
 	"github.com/danielMensah/user-management/internal/handler"
+	"github.com/danielMensah/user-management/internal/mail"
+	"github.com/danielMensah/user-management/internal/pagination"
+	"github.com/danielMensah/user-management/internal/purge"
 	mongoRepo "github.com/danielMensah/user-management/internal/repository/mongo"
 	"github.com/deepmap/oapi-codegen/pkg/middleware"
+	"github.com/getkin/kin-openapi/openapi3filter"
 	"github.com/labstack/echo/v4"
 	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+const baseURL = "/api/v1"
+
 func main() {
 	cfg, err := config.New()
 	if err != nil {
@@ -28,6 +35,11 @@ func main() {
 
 	router := echo.New()
 	router.HideBanner = true
+	router.HTTPErrorHandler = handler.HTTPErrorHandler
+
+	if router.IPExtractor, err = handler.IPExtractor(cfg); err != nil {
+		logrus.WithError(err).Fatal("failed to create ip extractor")
+	}
 
 	router.GET("/_healthz", func(c echo.Context) error {
 		return c.String(http.StatusOK, "ok")
@@ -48,11 +60,58 @@ func main() {
 		}
 	}()
 
+	tokens, err := auth.New(cfg)
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to create token manager")
+	}
+
+	totp, err := auth.NewTOTP(cfg)
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to create totp manager")
+	}
+
+	passwords, err := auth.NewPasswordHasher(cfg)
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to create password hasher")
+	}
+
+	policy, err := auth.NewPasswordPolicy(cfg)
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to create password policy")
+	}
+
+	cursors, err := pagination.NewCursorCodec(cfg)
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to create cursor codec")
+	}
+
+	mailer, err := mail.New(cfg)
+	if err != nil {
+		logrus.WithError(err).Fatal("failed to create mail sender")
+	}
+
 	repo := mongoRepo.New(conn.Database(cfg.MongoDB))
-	handlers := handler.New(repo)
+	if err = repo.EnsureIndexes(context.Background(), cfg.UniqueNicknames); err != nil {
+		logrus.WithError(err).Fatal("failed to create mongo indexes")
+	}
+
+	purgeCtx, stopPurging := context.WithCancel(context.Background())
+	defer stopPurging()
+	go purge.New(repo, cfg).Run(purgeCtx)
+
+	handlers := handler.New(repo, tokens, totp, passwords, policy, cursors, mailer, cfg)
+
+	validator := middleware.OapiRequestValidatorWithOptions(swagger, &middleware.Options{
+		ErrorHandler: handler.ValidationErrorHandler,
+		Options: openapi3filter.Options{
+			AuthenticationFunc: auth.NewAuthenticationFunc(tokens),
+			MultiError:         true,
+		},
+	})
 
-	apiGroup := router.Group("", middleware.OapiRequestValidator(swagger))
-	api.RegisterHandlersWithBaseURL(apiGroup, handlers, "/api/v1")
+	router.Pre(handler.CustomMethods(baseURL))
+	apiGroup := router.Group("", handler.LoginRateLimiter(cfg, baseURL), handler.ImportBodyLimit(baseURL), validator)
+	api.RegisterHandlersWithBaseURL(apiGroup, handlers, baseURL)
 
 	go func() {
 		addr := fmt.Sprintf("%s:%s", cfg.APIHost, cfg.APIPort)
@@ -19,6 +19,41 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	BearerAuthScopes = "bearerAuth.Scopes"
+)
+
+// Defines values for ImportRowResultStatus.
+const (
+	Created ImportRowResultStatus = "created"
+	Failed  ImportRowResultStatus = "failed"
+	Valid   ImportRowResultStatus = "valid"
+)
+
+// Defines values for JSONPatchOperationOp.
+const (
+	Add     JSONPatchOperationOp = "add"
+	Copy    JSONPatchOperationOp = "copy"
+	Move    JSONPatchOperationOp = "move"
+	Remove  JSONPatchOperationOp = "remove"
+	Replace JSONPatchOperationOp = "replace"
+	Test    JSONPatchOperationOp = "test"
+)
+
+// Defines values for PasswordViolationRule.
+const (
+	Breached         PasswordViolationRule = "breached"
+	CharacterClasses PasswordViolationRule = "character_classes"
+	MaxLength        PasswordViolationRule = "max_length"
+	MinLength        PasswordViolationRule = "min_length"
+	PersonalInfo     PasswordViolationRule = "personal_info"
+)
+
+// ConfirmTotpRequest defines model for ConfirmTotpRequest.
+type ConfirmTotpRequest struct {
+	Code string `json:"code"`
+}
+
 // Country defines model for Country.
 type Country = string
 
@@ -31,33 +66,246 @@ type CreateUserResponse struct {
 type CreatedAt = time.Time
 
 // Email defines model for Email.
-type Email = string
+type Email string
+
+// EmailRequest defines model for EmailRequest.
+type EmailRequest struct {
+	Email Email `bson:"email,omitempty" json:"email"`
+}
 
-// Error defines model for Error.
+// EnrollTotpResponse defines model for EnrollTotpResponse.
+type EnrollTotpResponse struct {
+	OtpauthUri string `json:"otpauth_uri"`
+
+	// Base32 encoded secret for manual entry
+	Secret string `json:"secret"`
+}
+
+// Problem details of a failed request as defined by RFC 7807, served as application/problem+json
 type Error struct {
-	Message string `json:"message"`
+	// Machine readable code identifying the error, set for errors clients need to tell apart
+	Code *string `json:"code,omitempty"`
+
+	// Explanation specific to this occurrence of the problem
+	Detail string `json:"detail"`
+
+	// Invalid fields of the request, set when it does not match the api specification
+	Errors *[]FieldError `json:"errors,omitempty"`
+
+	// Path of the request the problem occurred on
+	Instance *string `json:"instance,omitempty"`
+
+	// HTTP status code of the response
+	Status int `json:"status"`
+
+	// Short summary of the problem type, the reason phrase of the status code for about:blank
+	Title string `json:"title"`
+
+	// URI identifying the problem type, about:blank when the status code is enough to describe it
+	Type string `json:"type"`
+
+	// Rules of the password policy the password does not satisfy
+	Violations *[]PasswordViolation `json:"violations,omitempty"`
+}
+
+// FieldError defines model for FieldError.
+type FieldError struct {
+	// Name of the invalid path, query or header parameter
+	Parameter *string `json:"parameter,omitempty"`
+
+	// JSON pointer to the invalid field of the request body, empty for the whole body
+	Pointer *string `json:"pointer,omitempty"`
+	Reason  string  `json:"reason"`
 }
 
 // FirstName defines model for FirstName.
 type FirstName = string
 
+// GetRolesResponse defines model for GetRolesResponse.
+type GetRolesResponse struct {
+	Roles []RoleDefinition `json:"roles"`
+}
+
 // GetUsersResponse defines model for GetUsersResponse.
 type GetUsersResponse struct {
+	// Whether there are more users after this page
+	HasMore bool `json:"has_more"`
+
+	// Maximum number of users per page
+	Limit int64 `json:"limit"`
+
+	// Cursor of the page after this one, set when there are more users
+	Next *string `json:"next,omitempty"`
+
+	// Number of the page starting from 1, unknown when paging with cursors
+	Page *int64 `json:"page,omitempty"`
+
+	// Cursor of the page before this one, set when the page does not start at the newest user
+	Prev *string `json:"prev,omitempty"`
+
+	// Number of users matching the filters, unless counting them was skipped
+	Total *int64  `json:"total,omitempty"`
 	Users *[]User `json:"users,omitempty"`
 }
 
 // Id defines model for Id.
 type Id = string
 
+// ImportReport defines model for ImportReport.
+type ImportReport struct {
+	// Whether the rows were only validated
+	DryRun bool `json:"dry_run"`
+
+	// Number of rows that could not be imported
+	Failed int64             `json:"failed"`
+	Rows   []ImportRowResult `json:"rows"`
+
+	// Number of rows created, or found valid by a dry run
+	Succeeded int64 `json:"succeeded"`
+
+	// Number of rows in the file
+	Total int64 `json:"total"`
+}
+
+// ImportRowResult defines model for ImportRowResult.
+type ImportRowResult struct {
+	Id *Id `bson:"_id,omitempty" json:"_id,omitempty"`
+
+	// Why the row could not be imported
+	Detail *string `json:"detail,omitempty"`
+
+	// Fields of the row that do not match the user schema
+	Errors *[]FieldError `json:"errors,omitempty"`
+
+	// Number of the row in the file, starting from 1 and not counting the CSV header
+	Row int64 `json:"row"`
+
+	// Whether the user of the row was created, found valid by a dry run or could not be imported
+	Status ImportRowResultStatus `json:"status"`
+
+	// Rules of the password policy the password of the row violates
+	Violations *[]PasswordViolation `json:"violations,omitempty"`
+}
+
+// Whether the user of the row was created, found valid by a dry run or could not be imported
+type ImportRowResultStatus string
+
+// JSON patch of the editable fields of a user, whose operations are applied in order
+type JSONPatch = []JSONPatchOperation
+
+// JSONPatchOperation defines model for JSONPatchOperation.
+type JSONPatchOperation struct {
+	// JSON pointer to the source of move and copy operations
+	From *string              `json:"from,omitempty"`
+	Op   JSONPatchOperationOp `json:"op"`
+
+	// JSON pointer to the target of the operation
+	Path string `json:"path"`
+
+	// Value of add, replace and test operations
+	Value *interface{} `json:"value"`
+}
+
+// JSONPatchOperationOp defines model for JSONPatchOperation.Op.
+type JSONPatchOperationOp string
+
 // LastName defines model for LastName.
 type LastName = string
 
+// LoginRequest defines model for LoginRequest.
+type LoginRequest struct {
+	Email    Email    `bson:"email,omitempty" json:"email"`
+	Password Password `bson:"password,omitempty" json:"password"`
+}
+
+// MfaChallengeResponse defines model for MfaChallengeResponse.
+type MfaChallengeResponse struct {
+	// Number of seconds until the challenge token expires
+	ExpiresIn int64  `json:"expires_in"`
+	MfaToken  string `json:"mfa_token"`
+}
+
 // Nickname defines model for Nickname.
 type Nickname = string
 
 // Password defines model for Password.
 type Password = string
 
+// PasswordViolation defines model for PasswordViolation.
+type PasswordViolation struct {
+	Message string                `json:"message"`
+	Rule    PasswordViolationRule `json:"rule"`
+}
+
+// PasswordViolationRule defines model for PasswordViolation.Rule.
+type PasswordViolationRule string
+
+// Colon separated permission. A permission grants every narrower permission, so users:update grants users:update:self, and * matches any segment
+type Permission = string
+
+// RecoveryCodesResponse defines model for RecoveryCodesResponse.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// RefreshTokenRequest defines model for RefreshTokenRequest.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// ResetPasswordRequest defines model for ResetPasswordRequest.
+type ResetPasswordRequest struct {
+	Password Password `bson:"password,omitempty" json:"password"`
+	Token    string   `json:"token"`
+}
+
+// Name of a built-in role (admin, manager or user) or of a custom role
+type Role = string
+
+// RoleCreateData defines model for RoleCreateData.
+type RoleCreateData struct {
+	Description *string `json:"description,omitempty"`
+
+	// Name of a built-in role (admin, manager or user) or of a custom role
+	Name        Role         `json:"name"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// RoleDefinition defines model for RoleDefinition.
+type RoleDefinition struct {
+	// Built-in roles cannot be changed or deleted
+	BuiltIn     bool         `bson:"-" json:"built_in"`
+	CreatedAt   *CreatedAt   `bson:"created_at,omitempty" json:"created_at,omitempty"`
+	Description *string      `bson:"description,omitempty" json:"description,omitempty"`
+	Name        string       `bson:"_id" json:"name"`
+	Permissions []Permission `bson:"permissions" json:"permissions"`
+	UpdatedAt   *UpdatedAt   `bson:"updated_at,omitempty" json:"updated_at,omitempty"`
+}
+
+// RoleUpdateData defines model for RoleUpdateData.
+type RoleUpdateData struct {
+	Description *string      `json:"description,omitempty"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// Roles defines model for Roles.
+type Roles = []Role
+
+// SetUserRolesRequest defines model for SetUserRolesRequest.
+type SetUserRolesRequest struct {
+	Roles Roles `bson:"roles,omitempty" json:"roles"`
+}
+
+// TokenResponse defines model for TokenResponse.
+type TokenResponse struct {
+	AccessToken string `json:"access_token"`
+
+	// Number of seconds until the access token expires
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+}
+
 // UpdatedAt defines model for UpdatedAt.
 type UpdatedAt = time.Time
 
@@ -66,11 +314,22 @@ type User struct {
 	Id        Id        `bson:"_id,omitempty" json:"_id"`
 	Country   Country   `bson:"country,omitempty" json:"country"`
 	CreatedAt CreatedAt `bson:"created_at,omitempty" json:"created_at"`
-	Email     Email     `bson:"email,omitempty" json:"email"`
-	FirstName FirstName `bson:"first_name,omitempty" json:"first_name"`
-	LastName  LastName  `bson:"last_name,omitempty" json:"last_name"`
-	Nickname  Nickname  `bson:"nickname,omitempty" json:"nickname"`
-	UpdatedAt UpdatedAt `bson:"updated_at,omitempty" json:"updated_at"`
+
+	// When the user was soft deleted, only listed when deleted users are included
+	DeletedAt *time.Time `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"`
+	Email     Email      `bson:"email,omitempty" json:"email"`
+
+	// Whether the user proved they own their email address
+	EmailVerified   bool       `bson:"email_verified" json:"email_verified"`
+	EmailVerifiedAt *time.Time `bson:"email_verified_at,omitempty" json:"email_verified_at,omitempty"`
+	FirstName       FirstName  `bson:"first_name,omitempty" json:"first_name"`
+	LastName        LastName   `bson:"last_name,omitempty" json:"last_name"`
+	Nickname        Nickname   `bson:"nickname,omitempty" json:"nickname"`
+	Roles           *Roles     `bson:"roles,omitempty" json:"roles,omitempty"`
+	UpdatedAt       UpdatedAt  `bson:"updated_at,omitempty" json:"updated_at"`
+
+	// Version of the user, increased by every change and returned as the ETag of the user
+	Version int64 `bson:"version" json:"version"`
 }
 
 // UserCreateData defines model for UserCreateData.
@@ -85,28 +344,109 @@ type UserCreateData struct {
 	UpdatedAt *UpdatedAt `bson:"updated_at,omitempty" json:"updated_at,omitempty"`
 }
 
-// UserUpdateData defines model for UserUpdateData.
-type UserUpdateData struct {
+// JSON merge patch of the editable fields of a user
+type UserMergePatch struct {
 	Country   *Country   `bson:"country,omitempty" json:"country,omitempty"`
 	Email     *Email     `bson:"email,omitempty" json:"email,omitempty"`
 	FirstName *FirstName `bson:"first_name,omitempty" json:"first_name,omitempty"`
 	LastName  *LastName  `bson:"last_name,omitempty" json:"last_name,omitempty"`
 	Nickname  *Nickname  `bson:"nickname,omitempty" json:"nickname,omitempty"`
 	Password  *Password  `bson:"password,omitempty" json:"password,omitempty"`
-	UpdatedAt *UpdatedAt `bson:"updated_at,omitempty" json:"updated_at,omitempty"`
 }
 
+// UserReplaceData defines model for UserReplaceData.
+type UserReplaceData struct {
+	Country   Country   `bson:"country,omitempty" json:"country"`
+	Email     Email     `bson:"email,omitempty" json:"email"`
+	FirstName FirstName `bson:"first_name,omitempty" json:"first_name"`
+	LastName  LastName  `bson:"last_name,omitempty" json:"last_name"`
+	Nickname  Nickname  `bson:"nickname,omitempty" json:"nickname"`
+	Password  *Password `bson:"password,omitempty" json:"password,omitempty"`
+}
+
+// VerifyEmailRequest defines model for VerifyEmailRequest.
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// VerifyMfaRequest defines model for VerifyMfaRequest.
+type VerifyMfaRequest struct {
+	// A code generated by the authenticator app
+	Code     *string `json:"code,omitempty"`
+	MfaToken string  `json:"mfa_token"`
+
+	// A single-use recovery code, accepted instead of a code
+	RecoveryCode *string `json:"recovery_code,omitempty"`
+}
+
+// Cursor defines model for cursor.
+type Cursor = string
+
+// DryRun defines model for dryRun.
+type DryRun = bool
+
+// Fields defines model for fields.
+type Fields = []string
+
+// Filter defines model for filter.
+type Filter = []string
+
+// IfMatch defines model for ifMatch.
+type IfMatch = string
+
+// IfNoneMatch defines model for ifNoneMatch.
+type IfNoneMatch = string
+
+// IncludeDeleted defines model for includeDeleted.
+type IncludeDeleted = bool
+
 // Limit defines model for limit.
 type Limit = int64
 
 // Page defines model for page.
 type Page = int64
 
-// N400BadRequest defines model for 400BadRequest.
-type N400BadRequest = Error
+// Q defines model for q.
+type Q = string
+
+// Sort defines model for sort.
+type Sort = string
+
+// Total defines model for total.
+type Total = bool
+
+// ResendEmailVerificationJSONBody defines parameters for ResendEmailVerification.
+type ResendEmailVerificationJSONBody = EmailRequest
+
+// VerifyEmailJSONBody defines parameters for VerifyEmail.
+type VerifyEmailJSONBody = VerifyEmailRequest
 
-// N500InternalServerError defines model for 500InternalServerError.
-type N500InternalServerError = Error
+// LoginJSONBody defines parameters for Login.
+type LoginJSONBody = LoginRequest
+
+// LogoutJSONBody defines parameters for Logout.
+type LogoutJSONBody = RefreshTokenRequest
+
+// ConfirmTotpJSONBody defines parameters for ConfirmTotp.
+type ConfirmTotpJSONBody = ConfirmTotpRequest
+
+// VerifyMfaJSONBody defines parameters for VerifyMfa.
+type VerifyMfaJSONBody = VerifyMfaRequest
+
+// ForgotPasswordJSONBody defines parameters for ForgotPassword.
+type ForgotPasswordJSONBody = EmailRequest
+
+// ResetPasswordJSONBody defines parameters for ResetPassword.
+type ResetPasswordJSONBody = ResetPasswordRequest
+
+// RefreshTokenJSONBody defines parameters for RefreshToken.
+type RefreshTokenJSONBody = RefreshTokenRequest
+
+// CreateRoleJSONBody defines parameters for CreateRole.
+type CreateRoleJSONBody = RoleCreateData
+
+// UpdateRoleJSONBody defines parameters for UpdateRole.
+type UpdateRoleJSONBody = RoleUpdateData
 
 // GetUsersParams defines parameters for GetUsers.
 type GetUsersParams struct {
@@ -116,18 +456,153 @@ type GetUsersParams struct {
 	// User email
 	Email *Email `form:"email,omitempty" json:"email,omitempty"`
 
-	// Page number
-	Page Page `form:"page" json:"page"`
+	// Number of users to skip, ignored when a cursor is given. Prefer cursors, which stay fast on deep pages.
+	Page *Page `form:"page,omitempty" json:"page,omitempty"`
 
 	// Number of users per page
 	Limit Limit `form:"limit" json:"limit"`
+
+	// Opaque cursor returned as next or prev by a previous request, to fetch the page after or before it
+	Cursor *Cursor `form:"cursor,omitempty" json:"cursor,omitempty"`
+
+	// Whether to count the users matching the filters, set to false to skip counting on large collections
+	Total *Total `form:"total,omitempty" json:"total,omitempty"`
+
+	// Filters written as field:operator:value, which users have to match all of. Strings support eq, prefix and in, ids eq and in, booleans eq, and dates eq, gt, gte, lt and lte with RFC 3339 values. Values of in are comma separated.
+	Filter *Filter `form:"filter,omitempty" json:"filter,omitempty"`
+
+	// Comma separated fields to sort users by, each prefixed with - to sort in descending order. Ties are broken by id. Defaults to -created_at.
+	Sort *Sort `form:"sort,omitempty" json:"sort,omitempty"`
+
+	// Comma separated fields of users to return, such as _id,nickname,country. Other fields are left out of the response. Every field is returned when not given.
+	Fields *Fields `form:"fields,omitempty" json:"fields,omitempty"`
+
+	// Whether to list soft deleted users along with the others, which takes the permission to restore users
+	IncludeDeleted *IncludeDeleted `form:"include_deleted,omitempty" json:"include_deleted,omitempty"`
 }
 
+// GetUsersParamsFields defines parameters for GetUsers.
+type GetUsersParamsFields string
+
 // CreateUserJSONBody defines parameters for CreateUser.
 type CreateUserJSONBody = UserCreateData
 
+// ExportUsersParams defines parameters for ExportUsers.
+type ExportUsersParams struct {
+	// User country
+	Country *Country `form:"country,omitempty" json:"country,omitempty"`
+
+	// User email
+	Email *Email `form:"email,omitempty" json:"email,omitempty"`
+
+	// Filters written as field:operator:value, which users have to match all of. Strings support eq, prefix and in, ids eq and in, booleans eq, and dates eq, gt, gte, lt and lte with RFC 3339 values. Values of in are comma separated.
+	Filter *Filter `form:"filter,omitempty" json:"filter,omitempty"`
+
+	// Comma separated fields to sort users by, each prefixed with - to sort in descending order. Ties are broken by id. Defaults to -created_at.
+	Sort *Sort `form:"sort,omitempty" json:"sort,omitempty"`
+
+	// Comma separated fields of users to return, such as _id,nickname,country. Other fields are left out of the response. Every field is returned when not given.
+	Fields *Fields `form:"fields,omitempty" json:"fields,omitempty"`
+
+	// Whether to list soft deleted users along with the others, which takes the permission to restore users
+	IncludeDeleted *IncludeDeleted `form:"include_deleted,omitempty" json:"include_deleted,omitempty"`
+}
+
+// ExportUsersParamsFields defines parameters for ExportUsers.
+type ExportUsersParamsFields string
+
+// ImportUsersParams defines parameters for ImportUsers.
+type ImportUsersParams struct {
+	// Whether to only validate the rows, without creating any user
+	DryRun *DryRun `form:"dry_run,omitempty" json:"dry_run,omitempty"`
+}
+
+// SearchUsersParams defines parameters for SearchUsers.
+type SearchUsersParams struct {
+	// Words to search users by
+	Q Q `form:"q" json:"q"`
+
+	// User country
+	Country *Country `form:"country,omitempty" json:"country,omitempty"`
+
+	// Number of users to skip, ignored when a cursor is given. Prefer cursors, which stay fast on deep pages.
+	Page *Page `form:"page,omitempty" json:"page,omitempty"`
+
+	// Number of users per page
+	Limit Limit `form:"limit" json:"limit"`
+
+	// Whether to count the users matching the filters, set to false to skip counting on large collections
+	Total *Total `form:"total,omitempty" json:"total,omitempty"`
+}
+
+// DeleteUserParams defines parameters for DeleteUser.
+type DeleteUserParams struct {
+	// ETags of the versions of the user the request applies to, or * for any version. The request fails with 412 when the user is at another version. Weak ETags never match
+	IfMatch *IfMatch `json:"If-Match,omitempty"`
+}
+
+// GetUserParams defines parameters for GetUser.
+type GetUserParams struct {
+	// Comma separated fields of users to return, such as _id,nickname,country. Other fields are left out of the response. Every field is returned when not given.
+	Fields *Fields `form:"fields,omitempty" json:"fields,omitempty"`
+
+	// ETags of the versions of the user the client has, or * for any version, to get 304 when one matches
+	IfNoneMatch *IfNoneMatch `json:"If-None-Match,omitempty"`
+}
+
+// GetUserParamsFields defines parameters for GetUser.
+type GetUserParamsFields string
+
+// PatchUserParams defines parameters for PatchUser.
+type PatchUserParams struct {
+	// ETags of the versions of the user the request applies to, or * for any version. The request fails with 412 when the user is at another version. Weak ETags never match
+	IfMatch *IfMatch `json:"If-Match,omitempty"`
+}
+
 // UpdateUserJSONBody defines parameters for UpdateUser.
-type UpdateUserJSONBody = UserUpdateData
+type UpdateUserJSONBody = UserReplaceData
+
+// UpdateUserParams defines parameters for UpdateUser.
+type UpdateUserParams struct {
+	// ETags of the versions of the user the request applies to, or * for any version. The request fails with 412 when the user is at another version. Weak ETags never match
+	IfMatch *IfMatch `json:"If-Match,omitempty"`
+}
+
+// SetUserRolesJSONBody defines parameters for SetUserRoles.
+type SetUserRolesJSONBody = SetUserRolesRequest
+
+// ResendEmailVerificationJSONRequestBody defines body for ResendEmailVerification for application/json ContentType.
+type ResendEmailVerificationJSONRequestBody = ResendEmailVerificationJSONBody
+
+// VerifyEmailJSONRequestBody defines body for VerifyEmail for application/json ContentType.
+type VerifyEmailJSONRequestBody = VerifyEmailJSONBody
+
+// LoginJSONRequestBody defines body for Login for application/json ContentType.
+type LoginJSONRequestBody = LoginJSONBody
+
+// LogoutJSONRequestBody defines body for Logout for application/json ContentType.
+type LogoutJSONRequestBody = LogoutJSONBody
+
+// ConfirmTotpJSONRequestBody defines body for ConfirmTotp for application/json ContentType.
+type ConfirmTotpJSONRequestBody = ConfirmTotpJSONBody
+
+// VerifyMfaJSONRequestBody defines body for VerifyMfa for application/json ContentType.
+type VerifyMfaJSONRequestBody = VerifyMfaJSONBody
+
+// ForgotPasswordJSONRequestBody defines body for ForgotPassword for application/json ContentType.
+type ForgotPasswordJSONRequestBody = ForgotPasswordJSONBody
+
+// ResetPasswordJSONRequestBody defines body for ResetPassword for application/json ContentType.
+type ResetPasswordJSONRequestBody = ResetPasswordJSONBody
+
+// RefreshTokenJSONRequestBody defines body for RefreshToken for application/json ContentType.
+type RefreshTokenJSONRequestBody = RefreshTokenJSONBody
+
+// CreateRoleJSONRequestBody defines body for CreateRole for application/json ContentType.
+type CreateRoleJSONRequestBody = CreateRoleJSONBody
+
+// UpdateRoleJSONRequestBody defines body for UpdateRole for application/json ContentType.
+type UpdateRoleJSONRequestBody = UpdateRoleJSONBody
 
 // CreateUserJSONRequestBody defines body for CreateUser for application/json ContentType.
 type CreateUserJSONRequestBody = CreateUserJSONBody
@@ -135,23 +610,98 @@ type CreateUserJSONRequestBody = CreateUserJSONBody
 // UpdateUserJSONRequestBody defines body for UpdateUser for application/json ContentType.
 type UpdateUserJSONRequestBody = UpdateUserJSONBody
 
+// SetUserRolesJSONRequestBody defines body for SetUserRoles for application/json ContentType.
+type SetUserRolesJSONRequestBody = SetUserRolesJSONBody
+
 // ServerInterface represents all server handlers.
 type ServerInterface interface {
 	// Health check
 	// (GET /_healthz)
 	GetHealthz(ctx echo.Context) error
+	// Resend an email verification token
+	// (POST /auth/email/verification)
+	ResendEmailVerification(ctx echo.Context) error
+	// Verify an email address
+	// (POST /auth/email/verify)
+	VerifyEmail(ctx echo.Context) error
+	// Log in
+	// (POST /auth/login)
+	Login(ctx echo.Context) error
+	// Log out
+	// (POST /auth/logout)
+	Logout(ctx echo.Context) error
+	// Regenerate recovery codes
+	// (POST /auth/mfa/recovery-codes)
+	RegenerateRecoveryCodes(ctx echo.Context) error
+	// Enroll a TOTP secret
+	// (POST /auth/mfa/totp)
+	EnrollTotp(ctx echo.Context) error
+	// Confirm a TOTP enrollment
+	// (POST /auth/mfa/totp/confirm)
+	ConfirmTotp(ctx echo.Context) error
+	// Complete a multi-factor authentication challenge
+	// (POST /auth/mfa/verify)
+	VerifyMfa(ctx echo.Context) error
+	// Request a password reset
+	// (POST /auth/password/forgot)
+	ForgotPassword(ctx echo.Context) error
+	// Reset a password
+	// (POST /auth/password/reset)
+	ResetPassword(ctx echo.Context) error
+	// Refresh tokens
+	// (POST /auth/refresh)
+	RefreshToken(ctx echo.Context) error
+	// Get all roles
+	// (GET /roles)
+	GetRoles(ctx echo.Context) error
+	// Create a custom role
+	// (POST /roles)
+	CreateRole(ctx echo.Context) error
+	// Delete a custom role
+	// (DELETE /roles/{name})
+	DeleteRole(ctx echo.Context, name string) error
+	// Get a role
+	// (GET /roles/{name})
+	GetRole(ctx echo.Context, name string) error
+	// Update a custom role
+	// (PUT /roles/{name})
+	UpdateRole(ctx echo.Context, name string) error
 	// Get all users
 	// (GET /users)
 	GetUsers(ctx echo.Context, params GetUsersParams) error
 	// Create a new user
 	// (POST /users)
 	CreateUser(ctx echo.Context) error
+	// Export users
+	// (GET /users/export)
+	ExportUsers(ctx echo.Context, params ExportUsersParams) error
+	// Import users
+	// (POST /users/import)
+	ImportUsers(ctx echo.Context, params ImportUsersParams) error
+	// Search users
+	// (GET /users/search)
+	SearchUsers(ctx echo.Context, params SearchUsersParams) error
 	// Delete a user
 	// (DELETE /users/{id})
-	DeleteUser(ctx echo.Context, id string) error
-	// Update a user
+	DeleteUser(ctx echo.Context, id string, params DeleteUserParams) error
+	// Get a user
+	// (GET /users/{id})
+	GetUser(ctx echo.Context, id string, params GetUserParams) error
+	// Patch a user
+	// (PATCH /users/{id})
+	PatchUser(ctx echo.Context, id string, params PatchUserParams) error
+	// Replace a user
 	// (PUT /users/{id})
-	UpdateUser(ctx echo.Context, id string) error
+	UpdateUser(ctx echo.Context, id string, params UpdateUserParams) error
+	// Restore a user
+	// (POST /users/{id}/restore)
+	RestoreUser(ctx echo.Context, id string) error
+	// Assign roles to a user
+	// (PUT /users/{id}/roles)
+	SetUserRoles(ctx echo.Context, id string) error
+	// Unlock a user
+	// (POST /users/{id}/unlock)
+	UnlockUser(ctx echo.Context, id string) error
 }
 
 // ServerInterfaceWrapper converts echo contexts to parameters.
@@ -168,10 +718,193 @@ func (w *ServerInterfaceWrapper) GetHealthz(ctx echo.Context) error {
 	return err
 }
 
+// ResendEmailVerification converts echo context to params.
+func (w *ServerInterfaceWrapper) ResendEmailVerification(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.ResendEmailVerification(ctx)
+	return err
+}
+
+// VerifyEmail converts echo context to params.
+func (w *ServerInterfaceWrapper) VerifyEmail(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.VerifyEmail(ctx)
+	return err
+}
+
+// Login converts echo context to params.
+func (w *ServerInterfaceWrapper) Login(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.Login(ctx)
+	return err
+}
+
+// Logout converts echo context to params.
+func (w *ServerInterfaceWrapper) Logout(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.Logout(ctx)
+	return err
+}
+
+// RegenerateRecoveryCodes converts echo context to params.
+func (w *ServerInterfaceWrapper) RegenerateRecoveryCodes(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.RegenerateRecoveryCodes(ctx)
+	return err
+}
+
+// EnrollTotp converts echo context to params.
+func (w *ServerInterfaceWrapper) EnrollTotp(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.EnrollTotp(ctx)
+	return err
+}
+
+// ConfirmTotp converts echo context to params.
+func (w *ServerInterfaceWrapper) ConfirmTotp(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.ConfirmTotp(ctx)
+	return err
+}
+
+// VerifyMfa converts echo context to params.
+func (w *ServerInterfaceWrapper) VerifyMfa(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.VerifyMfa(ctx)
+	return err
+}
+
+// ForgotPassword converts echo context to params.
+func (w *ServerInterfaceWrapper) ForgotPassword(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.ForgotPassword(ctx)
+	return err
+}
+
+// ResetPassword converts echo context to params.
+func (w *ServerInterfaceWrapper) ResetPassword(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.ResetPassword(ctx)
+	return err
+}
+
+// RefreshToken converts echo context to params.
+func (w *ServerInterfaceWrapper) RefreshToken(ctx echo.Context) error {
+	var err error
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.RefreshToken(ctx)
+	return err
+}
+
+// GetRoles converts echo context to params.
+func (w *ServerInterfaceWrapper) GetRoles(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetRoles(ctx)
+	return err
+}
+
+// CreateRole converts echo context to params.
+func (w *ServerInterfaceWrapper) CreateRole(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.CreateRole(ctx)
+	return err
+}
+
+// DeleteRole converts echo context to params.
+func (w *ServerInterfaceWrapper) DeleteRole(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "name", runtime.ParamLocationPath, ctx.Param("name"), &name)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter name: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.DeleteRole(ctx, name)
+	return err
+}
+
+// GetRole converts echo context to params.
+func (w *ServerInterfaceWrapper) GetRole(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "name", runtime.ParamLocationPath, ctx.Param("name"), &name)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter name: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetRole(ctx, name)
+	return err
+}
+
+// UpdateRole converts echo context to params.
+func (w *ServerInterfaceWrapper) UpdateRole(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "name" -------------
+	var name string
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "name", runtime.ParamLocationPath, ctx.Param("name"), &name)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter name: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.UpdateRole(ctx, name)
+	return err
+}
+
 // GetUsers converts echo context to params.
 func (w *ServerInterfaceWrapper) GetUsers(ctx echo.Context) error {
 	var err error
 
+	ctx.Set(BearerAuthScopes, []string{""})
+
 	// Parameter object where we will unmarshal all parameters from the context
 	var params GetUsersParams
 	// ------------- Optional query parameter "country" -------------
@@ -188,9 +921,9 @@ func (w *ServerInterfaceWrapper) GetUsers(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter email: %s", err))
 	}
 
-	// ------------- Required query parameter "page" -------------
+	// ------------- Optional query parameter "page" -------------
 
-	err = runtime.BindQueryParameter("form", true, true, "page", ctx.QueryParams(), &params.Page)
+	err = runtime.BindQueryParameter("form", true, false, "page", ctx.QueryParams(), &params.Page)
 	if err != nil {
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter page: %s", err))
 	}
@@ -202,6 +935,48 @@ func (w *ServerInterfaceWrapper) GetUsers(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
 	}
 
+	// ------------- Optional query parameter "cursor" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "cursor", ctx.QueryParams(), &params.Cursor)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter cursor: %s", err))
+	}
+
+	// ------------- Optional query parameter "total" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "total", ctx.QueryParams(), &params.Total)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter total: %s", err))
+	}
+
+	// ------------- Optional query parameter "filter" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "filter", ctx.QueryParams(), &params.Filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter filter: %s", err))
+	}
+
+	// ------------- Optional query parameter "sort" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sort", ctx.QueryParams(), &params.Sort)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter sort: %s", err))
+	}
+
+	// ------------- Optional query parameter "fields" -------------
+
+	err = runtime.BindQueryParameter("form", false, false, "fields", ctx.QueryParams(), &params.Fields)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter fields: %s", err))
+	}
+
+	// ------------- Optional query parameter "include_deleted" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "include_deleted", ctx.QueryParams(), &params.IncludeDeleted)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter include_deleted: %s", err))
+	}
+
 	// Invoke the callback with all the unmarshalled arguments
 	err = w.Handler.GetUsers(ctx, params)
 	return err
@@ -216,6 +991,129 @@ func (w *ServerInterfaceWrapper) CreateUser(ctx echo.Context) error {
 	return err
 }
 
+// ExportUsers converts echo context to params.
+func (w *ServerInterfaceWrapper) ExportUsers(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{""})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ExportUsersParams
+	// ------------- Optional query parameter "country" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "country", ctx.QueryParams(), &params.Country)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter country: %s", err))
+	}
+
+	// ------------- Optional query parameter "email" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "email", ctx.QueryParams(), &params.Email)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter email: %s", err))
+	}
+
+	// ------------- Optional query parameter "filter" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "filter", ctx.QueryParams(), &params.Filter)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter filter: %s", err))
+	}
+
+	// ------------- Optional query parameter "sort" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "sort", ctx.QueryParams(), &params.Sort)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter sort: %s", err))
+	}
+
+	// ------------- Optional query parameter "fields" -------------
+
+	err = runtime.BindQueryParameter("form", false, false, "fields", ctx.QueryParams(), &params.Fields)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter fields: %s", err))
+	}
+
+	// ------------- Optional query parameter "include_deleted" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "include_deleted", ctx.QueryParams(), &params.IncludeDeleted)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter include_deleted: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.ExportUsers(ctx, params)
+	return err
+}
+
+// ImportUsers converts echo context to params.
+func (w *ServerInterfaceWrapper) ImportUsers(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{""})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params ImportUsersParams
+	// ------------- Optional query parameter "dry_run" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "dry_run", ctx.QueryParams(), &params.DryRun)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter dry_run: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.ImportUsers(ctx, params)
+	return err
+}
+
+// SearchUsers converts echo context to params.
+func (w *ServerInterfaceWrapper) SearchUsers(ctx echo.Context) error {
+	var err error
+
+	ctx.Set(BearerAuthScopes, []string{""})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params SearchUsersParams
+	// ------------- Required query parameter "q" -------------
+
+	err = runtime.BindQueryParameter("form", true, true, "q", ctx.QueryParams(), &params.Q)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter q: %s", err))
+	}
+
+	// ------------- Optional query parameter "country" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "country", ctx.QueryParams(), &params.Country)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter country: %s", err))
+	}
+
+	// ------------- Optional query parameter "page" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "page", ctx.QueryParams(), &params.Page)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter page: %s", err))
+	}
+
+	// ------------- Required query parameter "limit" -------------
+
+	err = runtime.BindQueryParameter("form", true, true, "limit", ctx.QueryParams(), &params.Limit)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter limit: %s", err))
+	}
+
+	// ------------- Optional query parameter "total" -------------
+
+	err = runtime.BindQueryParameter("form", true, false, "total", ctx.QueryParams(), &params.Total)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter total: %s", err))
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.SearchUsers(ctx, params)
+	return err
+}
+
 // DeleteUser converts echo context to params.
 func (w *ServerInterfaceWrapper) DeleteUser(ctx echo.Context) error {
 	var err error
@@ -227,8 +1125,112 @@ func (w *ServerInterfaceWrapper) DeleteUser(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
 	}
 
+	ctx.Set(BearerAuthScopes, []string{""})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params DeleteUserParams
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "If-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-Match")]; found {
+		var IfMatch IfMatch
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for If-Match, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "If-Match", runtime.ParamLocationHeader, valueList[0], &IfMatch)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter If-Match: %s", err))
+		}
+
+		params.IfMatch = &IfMatch
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.DeleteUser(ctx, id, params)
+	return err
+}
+
+// GetUser converts echo context to params.
+func (w *ServerInterfaceWrapper) GetUser(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{""})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params GetUserParams
+	// ------------- Optional query parameter "fields" -------------
+
+	err = runtime.BindQueryParameter("form", false, false, "fields", ctx.QueryParams(), &params.Fields)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter fields: %s", err))
+	}
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "If-None-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-None-Match")]; found {
+		var IfNoneMatch IfNoneMatch
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for If-None-Match, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "If-None-Match", runtime.ParamLocationHeader, valueList[0], &IfNoneMatch)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter If-None-Match: %s", err))
+		}
+
+		params.IfNoneMatch = &IfNoneMatch
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.GetUser(ctx, id, params)
+	return err
+}
+
+// PatchUser converts echo context to params.
+func (w *ServerInterfaceWrapper) PatchUser(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{""})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params PatchUserParams
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "If-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-Match")]; found {
+		var IfMatch IfMatch
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for If-Match, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "If-Match", runtime.ParamLocationHeader, valueList[0], &IfMatch)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter If-Match: %s", err))
+		}
+
+		params.IfMatch = &IfMatch
+	}
+
 	// Invoke the callback with all the unmarshalled arguments
-	err = w.Handler.DeleteUser(ctx, id)
+	err = w.Handler.PatchUser(ctx, id, params)
 	return err
 }
 
@@ -243,8 +1245,84 @@ func (w *ServerInterfaceWrapper) UpdateUser(ctx echo.Context) error {
 		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
 	}
 
+	ctx.Set(BearerAuthScopes, []string{""})
+
+	// Parameter object where we will unmarshal all parameters from the context
+	var params UpdateUserParams
+
+	headers := ctx.Request().Header
+	// ------------- Optional header parameter "If-Match" -------------
+	if valueList, found := headers[http.CanonicalHeaderKey("If-Match")]; found {
+		var IfMatch IfMatch
+		n := len(valueList)
+		if n != 1 {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Expected one value for If-Match, got %d", n))
+		}
+
+		err = runtime.BindStyledParameterWithLocation("simple", false, "If-Match", runtime.ParamLocationHeader, valueList[0], &IfMatch)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter If-Match: %s", err))
+		}
+
+		params.IfMatch = &IfMatch
+	}
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.UpdateUser(ctx, id, params)
+	return err
+}
+
+// RestoreUser converts echo context to params.
+func (w *ServerInterfaceWrapper) RestoreUser(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.RestoreUser(ctx, id)
+	return err
+}
+
+// SetUserRoles converts echo context to params.
+func (w *ServerInterfaceWrapper) SetUserRoles(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{""})
+
+	// Invoke the callback with all the unmarshalled arguments
+	err = w.Handler.SetUserRoles(ctx, id)
+	return err
+}
+
+// UnlockUser converts echo context to params.
+func (w *ServerInterfaceWrapper) UnlockUser(ctx echo.Context) error {
+	var err error
+	// ------------- Path parameter "id" -------------
+	var id string
+
+	err = runtime.BindStyledParameterWithLocation("simple", false, "id", runtime.ParamLocationPath, ctx.Param("id"), &id)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("Invalid format for parameter id: %s", err))
+	}
+
+	ctx.Set(BearerAuthScopes, []string{""})
+
 	// Invoke the callback with all the unmarshalled arguments
-	err = w.Handler.UpdateUser(ctx, id)
+	err = w.Handler.UnlockUser(ctx, id)
 	return err
 }
 
@@ -277,37 +1355,165 @@ func RegisterHandlersWithBaseURL(router EchoRouter, si ServerInterface, baseURL
 	}
 
 	router.GET(baseURL+"/_healthz", wrapper.GetHealthz)
+	router.POST(baseURL+"/auth/email/verification", wrapper.ResendEmailVerification)
+	router.POST(baseURL+"/auth/email/verify", wrapper.VerifyEmail)
+	router.POST(baseURL+"/auth/login", wrapper.Login)
+	router.POST(baseURL+"/auth/logout", wrapper.Logout)
+	router.POST(baseURL+"/auth/mfa/recovery-codes", wrapper.RegenerateRecoveryCodes)
+	router.POST(baseURL+"/auth/mfa/totp", wrapper.EnrollTotp)
+	router.POST(baseURL+"/auth/mfa/totp/confirm", wrapper.ConfirmTotp)
+	router.POST(baseURL+"/auth/mfa/verify", wrapper.VerifyMfa)
+	router.POST(baseURL+"/auth/password/forgot", wrapper.ForgotPassword)
+	router.POST(baseURL+"/auth/password/reset", wrapper.ResetPassword)
+	router.POST(baseURL+"/auth/refresh", wrapper.RefreshToken)
+	router.GET(baseURL+"/roles", wrapper.GetRoles)
+	router.POST(baseURL+"/roles", wrapper.CreateRole)
+	router.DELETE(baseURL+"/roles/:name", wrapper.DeleteRole)
+	router.GET(baseURL+"/roles/:name", wrapper.GetRole)
+	router.PUT(baseURL+"/roles/:name", wrapper.UpdateRole)
 	router.GET(baseURL+"/users", wrapper.GetUsers)
 	router.POST(baseURL+"/users", wrapper.CreateUser)
+	router.GET(baseURL+"/users/export", wrapper.ExportUsers)
+	router.POST(baseURL+"/users/import", wrapper.ImportUsers)
+	router.GET(baseURL+"/users/search", wrapper.SearchUsers)
 	router.DELETE(baseURL+"/users/:id", wrapper.DeleteUser)
+	router.GET(baseURL+"/users/:id", wrapper.GetUser)
+	router.PATCH(baseURL+"/users/:id", wrapper.PatchUser)
 	router.PUT(baseURL+"/users/:id", wrapper.UpdateUser)
+	router.POST(baseURL+"/users/:id/restore", wrapper.RestoreUser)
+	router.PUT(baseURL+"/users/:id/roles", wrapper.SetUserRoles)
+	router.POST(baseURL+"/users/:id/unlock", wrapper.UnlockUser)
 
 }
 
 // Base64 encoded, gzipped, json marshaled Swagger object
 var swaggerSpec = []string{
 
-	"H4sIAAAAAAAC/+xYX1PkNgz/Kh63j4GEO9rp7FM5uLtue6UMDE8Mw5hEu2susY2t8KdMvnvHdv5t4rB0",
-	"h2t5uLfN2pJ+ln6SJT/RVBZKChBo6OyJKqZZAQjafaWyFKgf7c8MTKq5Qi4FndFzA5o0qxGFB1aoHNZE",
-	"6PkZrSLK7fbbEtw+wQqgM9oJmnQFBbNy+KjskkHNxZJWVUShYDyfMO3X1gzX2+lN9mv9724qiykIjYLn",
-	"AOS84DgGcFwW16CJXJDSgDZEgSaKLYGGLXktEdVwW3INGZ2hLqFvOYMFK3Oks70kogupC4Z0RrnAn/dp",
-	"RAv2wIuysKtJ1KDkAmEJ2sF0tkcoT9gSiHBQJ4DVmF+AKwRrBKSyqoySwoDjzn6SfGDZKdyWYNAzQyAI",
-	"95MplfOUWajxjbF4n3p2f9SwoDP6Q9xRM/arJv6otaytrZ93Lu5YzjOia4NVRH9KkrlA0ILlZ6DvQHvh",
-	"/wCKN0qMs0qg3tj41rnnsEutlsT0/I/OszURI/qwI5niO6nMYAliBx5Qsx1kS6fm2kFuMiqSBUcoFD46",
-	"c4caGIJNmNM6Mi7FtVSgkfswXfFs00HnGfXRbYhy4aQuW6jy+gZS53JvMTtwrm05kzGEHeQFbHs6r/WK",
-	"4eCAH5sS0bnwxqyl/3YGXXUY2mrIs+6/AoypE3BcQfouazaG3PaJa4PHLi37Z/ldrsSWJ1hYjVc20wfH",
-	"+AxoCWGmGeGqmv1hxcwmclhl9gg1SKY184ZGh5xnYye97DBXPBuc4gsL+etIbsuwnIW9dczTr2Jk5ybb",
-	"0oyo1Q2snDBj7qXO1q3cS70tf1WtcGDnXGXfIDtLr3WcnY4a2xacqN98PLe5KaRWoq0TG4XaOtVvNJ6t",
-	"9m5TFfUya5NIl9W2mWAvlGqpXUUtXzbJtDStol44NqZuS4dQeV87aR9/D1bU9lFdS9cLwhqYUN2zDPGh",
-	"OGLIxlz5ToGtKKB69eQ5mbbuvBJt/g1hWowdd6YY4g2+FkO+h3rbUA+CU7nRaiEnRrQ/mWBLKEAgOTiZ",
-	"k4XU5BNLgSM5XLE8B+GmD+ToO9+xCI3oHWjjVSa7ye6eBS8VCKY4ndH3u8nue8ckXDkuxFcrYDmu/rYf",
-	"SwiMbqeApRaGvEsSwhcEV+B6dJ4C4YaUijCREV0K4W8/SzQ3GdjWxXZOv9X6B6POuyQZTBUIDxirnPHB",
-	"PNHd7n+NW/3AKHE2ic6NE2VRMDdre2AkXUH61ep1N/QF9f6gl3Zz3PZ1Qdd8BiQsz/1MGzr7eb3QfyG4",
-	"CNOn2xKnbeJt3Ap1zm3c6AbXF+zzk3d1uTFa28+Ao246EMMDknOD7XuBJfG+hxDS3EKN1wdoP89ulpoY",
-	"etfpMgx2wxf/fWnrijQBkvg7kzAi4N4Jj4jSjZz10wIY/CCzx1fz+KBlqMYPD++SvVezFpigAxGuWwnv",
-	"kTcQ3lCYhiFuK0L8xLPKhzoHDLwkHbn/CQsH3K/WAR/UhsCtMD9qXqNs2e4eo1zDOf0UNSyU45zenwL+",
-	"dsIydGQg68pA0vlLeMr/fvV/8f+3Se5etxdM7uRVrYXSue563gxvhgQYpbLd7aRDYf8i0/Yp0k5kOqcz",
-	"ukJUszjO7dpKGpz9kiRJzBSP7/ZodVn9EwAA//+CQySaFxgAAA==",
+	"H4sIAAAAAAAC/+x9C3PcNtLgX0HxvqtNspQ0krzZWFVbd44fiRM/dLKd7J3tU0FkzwwiDkADoOTZnP77",
+	"VTdAEuSAM5RsybLjqu/beESQaAD9fuHPJFOLUkmQ1iQHfyZz4Dlo+ufDl3yG/83BZFqUViiZHCS/gTZC",
+	"SaamzM6BVQZ0yrhhnBmrlZwxfI2ZKpvjX98k+2+SbXYEPDf4CmemOjFg69enAorcMA220pJxdg78tPuF",
+	"33foG29kkiYmm8OCI0x2WUJykBirhZwlFxcXaVJyzRdgPfCZqqTVy1X4XxnQrH6aJvCeL8oCOq8kr14k",
+	"F2kicPi7Cmic5AucsH1xGJY0ySptlF6d+3nJ31XA3GO/aMhxlRLeW6Y0KzWcsZMl4/QvoSrcm3cVGJsy",
+	"q9gUbDanjSv5DBifWtD42glMlQYmbDIAtgNoPdS5Xh5VchXq3+dg56BxfiWLJTvjhci5BYJDq3OTsnNh",
+	"56qyLNPArZAzxuWSUGMAnlwvj3XVPdIcprwqbHIw5YWBtAbwRKkCuCQIYcFFMXCk7lnnQP3w5I/8f/q/",
+	"bmdqMXS09QfWbZFD11UI7qvFgjMDiIMW8hqt1ZQ2weDWudNOG7w+FnkqRXaKs6cerbbZc9pp/zrXwAqY",
+	"WqaqhmA0mFJJA9vs4RnopRvKhGmx6XwOkkll2Uycgdwmwmk25XVyLHJcs585SRuUfovDykLl0BxBbJv8",
+	"FoT7JCwsaFNAVot2jqnQxh77WQre/juYvDk1/O/xGWgxFZCv/OGY2wDSNNGqAISB8K1+XJV5+yOHAvyP",
+	"t2n/JNNkIeRjB/Vu85RrzZf40NhlQUtVmrBlKgoLEXJ+RH837FwLa0HiqdLuHKgSNLdKH5zxooKUnc9F",
+	"NveoMOdngPiw4EjKvCiYmm6zFwSZYaYqS6Utg3cpcoCpeM+4zJmQKRO5YfCu+enpwtBQ/CMu3v2aWfx/",
+	"SFlh6UlhgSiUHT26z/b39+8ygstss9/ov4hbQhK6ZV1EXsGe+ugOHHAHf+TtuRwIefDq1/TVi87BHMws",
+	"HOxN9va2Jrtbk92Xk8kB/d//6SCc1dUgvtHmR/Ft9Vj5e3+se5PN5yqmT/EQVg8WBZCpKe7MCTwTSjxP",
+	"isSYGS/LQgASeYq8+Ds2VZoYoH9zm70MRk+5KIw7jju7e45am88KwziemSI20Lz/ey0VUVCcgXbIQ2dD",
+	"W+aEdrtnj6dbbmXruZmYPlMSPmgPskKAtGzOTXztJLVmYNn+5I5bq5LgwAezBnwEbNwaZFZUOTxw5L5W",
+	"eBXCWGbU1DLPHDxF8gLVFjoRXBLtvamJ1vJTME7igl4IQ7oPsXNjlXY7YQaknIft2E93WWlXiIWwqyt6",
+	"Vi1OQLeypQRN2sAAEO4raYL4JzTkNa1FQNmdpEQb3BLw9vs7CZGUWCBb3520JCWkhRnoxOldM9gMpVXM",
+	"nIoyZWImla7FFK91IWG8uGKHGqag/d+bYzCWL9mUG8uUZDlASUs22wOL9vsRWWNsiZFFvYtgktK5Wwdw",
+	"3bDzk44SmfyB4KkBqN6tPYYFf/8E5MzO/VYvhGx+pxHMN0rb0ZoIgo1ypQY6ZcCzuRcxkDvs32qGCdxk",
+	"k4HMUZlTOge9zV4ik0MhcaLVKUhUU0W+zR64raU5tlq+3xMdSfAoDRSA2D7RytaTvVWWF2upnYRSw6yM",
+	"4zm4Gmd2kOROmQGLg4kIaxx1r9LCJSu4nqFYLArIcJIhWncARVHOHfQKgV+kSa3MkTC7M5n8yPMjJyac",
+	"PSItSPoniZiMIwA7pVYnBSz+/odRpKy3M/6XhmlykPy3ndak23FPzc5DrZV2s3a37LEkfb6WTygX70x2",
+	"X0le2bnS4j+Q3xwsT5G/EsIx4cHKNOQgreCFcaDtP1L6ROQ5yJuDC+V3xovCSWjUrXlRqHPIEWVK0MhR",
+	"nOwgxQ9fIljvPFP2kapkfrOgajCq0hmwXIEDF96L+mi/f6bsvSyD0vKTAm4WMDcvc/Le7SHCJ6HWKxaQ",
+	"C86QVEzf4HHQ372v5LQQmf1EO5r56b0Gx6XbWscsHIy7e4caMiVzgR94xEUBN3z+tSpJiGoZr5UxJ2KR",
+	"b+PG1kqiPw4H+/4hXxaK5y+VeoKc72YBn4oCEHBiuuTYmHPDFko7PwOzcy5ZxiU7ASYWpdIo4TjqBJnf",
+	"+3+8kt6EgvwpItPLZXnDi6gV/ROVL+tTQOuKNYAFaE5Q7+0/UdnpTWMJz5yIFIZZwM3kWhRLVhAo3rVk",
+	"FRqqcklWC+SsUDNBIjBwEx6B1cute9OoidyqgYZowjAUrQWzXQAq6aaNSf1WLbugzfohJK+jRp26ya3r",
+	"kY5zvzhAUCI4RwSir9P8vZFA0N99qdRTLpdezpsbBLw+SzpExi2eujVsqpUTXoYvgD0+ZDzPNRiC9x+T",
+	"yWNpQUtevAB9Btp9/QaVEzc5MzQ7AzcQAdtHgEQGryQ/46K4eWGWc8tPuIE+AVUtQGnHT+AZlwarBeRO",
+	"h3cTIRwo2IRevFS2DHTAUqsStBXgESWHuD7cmhWv3ajW6aVO/oCMhP/91ife6uWvfk361kWavN9SvBRb",
+	"+KUZyC14bzXfsnxGUJzQHtZen1QtBGHSkgC5T1o+emSPasG9sgx0D27Y+cf5yrLwreiqnF1xj/arse2Q",
+	"AresIBvjSqtrrZXuAh/WPujA6DMd/3JgYdbuzVUIZmrrKmDRB2MQDeJM4zNfi+g0qL/j7tXYnj+UWhWF",
+	"w9WhU1a2RBviuNKiu13+wcHOjlW23EHmuLXgks9gAdIe/JH/9zuTBUjD57id/8NApsH+65cfX/z+v/cf",
+	"HD78+fDX/cN/H76pJpO974UxFeh/9b6RRDy+7jOrEupHbmB/j4HEfc+ZG0ZurAWXFS8YeJ/zeqLzn087",
+	"y45uXc1Bu2AcOgbFcrDkIKRYmZe6jaPRsBymAp38J0ty5v7zh8k/U8ccKYw0yPLSAU7Ss784msjANPAc",
+	"+RfDYUyQATZd1sYzMWFnOuM20U/jPYGGSXBC0EJRMF5yMuXbw/dC/7iR9ysH5XYg4pB8XxZc0tqYKSET",
+	"U5HRRHNhmMqySmuQWWNK+OV3Jvf7iUYb16arqsVAcUtbBaW2mdswT8Dn3c6Qe0vY1gRz/n4cx0vRLICW",
+	"k6StS3sdlT7C6R7WIrDv3RbSWC6zyLEecjvvARluUb15OVNdl80OL8XO2e5O7eNcpSnLbRXZn59fvjxk",
+	"7qFDob4tF8xyJ+ZWTBMrbBFZyou50paZarHgetk7alKoa5HLjZKsnGsU0H5YCBC5qU9UZQ9OCi5PO8v+",
+	"keesZqeRRdtlGQHs1dHjFULpwhVM13r9Q5iEYSBVNZsjgrqvn/iYbkA/HaBXgDsTquDOT7UC4lFVtGZ1",
+	"yY05VzpnpSpEtuz+rUFaw60w0+VYBD30H/ithmIVT3tskx7Wp90gVMMEYgw0oIIVmdPkAEQsEdRu/eJr",
+	"51LJ7Txl5MkjY9Np8+1Hwo2v/egrW14qIaMz/vLi+TPmnzpG1c7swrY9mkQ2lDIS7ISg+Ox8rgqoGVRA",
+	"mnHNgnaXex23He2e0rHKv4WsyCkq7I0T9W+SjWLOfz1+LtrYZ+QNDef+Rc3lFXWwNoDc03h+AnukCjDD",
+	"yocLEB/8OQ5v8WMPULKKUUjrPh7bg5/Aouq7BrA5N8cLpWGN73oOGsjPvmgCTLU5jqLOxzb6/uTBeNFT",
+	"F8BhcjhutDEikiaYoBKJNrjojZr2c1KcUJYQCMPYuqL0tCGe1MxkLNfkeSMLdjdllTyV6ly66Uo+E3VY",
+	"zweTxq0UE29GrdQn3cSX6oa0jBRhRVcVPpJwjuTu82NWBUw8ttGPqMUDGpUswJg2hGHnsGDn3FBko4R8",
+	"3B64wxlLPq+Me2st0dT8syGAGP08zlfN23G8AtNpukziMTkIj6D0cbIuHdYZSOvI0Dkdz0FDN/Epj5Lf",
+	"tPH0Dp1b7cO0eDxFTogReDJDBr876pjwg6NPye+HOj8Cg3GpiAppqiwDyEeswpvHFPSfYoTDbY5LXcv1",
+	"krnsrkuuZyPm09xC1ijfUST3xkV2Q5xs09Ca8F2zA82B+m2OomtvS6/q6Rg2en6fL2tM3Iw0RLaMF2i+",
+	"LV1gwtR5DcKwQZVhyMx51DVv1LlD3lz1DBqa1q3n45gyWp1vkgAITYAIaV8aUOITghlyQnb/xW+syTa5",
+	"FOYMGzshw6hMF8JzHpDKEJ0gDQ2erc+o8x9J0oTeb7Hz7bUZAcEy3AfBjD3dy9sBeOLNFsdIDZXpw3im",
+	"ktOz8VkNM+SCopuBgc59pvL5XJkgTusSGlwGF+bVuVyHsetsgHoexn372BwZtcIoEGnHWRA+EKmmbKHO",
+	"gNA8U+UyWFKMxFUZ5mfynNga4BfoH2XBM/yX/wN+EL8CJp49iXbTOGgtRvKa5NUGxq4tE6SDrOIyZihG",
+	"stDxz3SueZ4yvwDaDAS6uxmyKnx4wOoK+pinysQvKIZ2T3jMpnmgrupWbtJge8rKE4zJfBQfLi7GUd9Y",
+	"Kh3w+wYfiu3M0ym/P8ecCDmDYXMH3pdCgzkW8nKxwaz+NLOUa+S/E+LN/mQyim8vpvyYPrI5ctIOTUPI",
+	"Y8t/VuNsNxKQXxEvmkzwLlocBkfZzpIprSGzWz8rbWDrhFsLerm1e8Wp61MemLrl4SuHuwBjvLHWAreo",
+	"DAkxblkB3Fi2u4enqXlGudKYaxl1W1QFhCxqIeRx4dLfKAex/dF87DgruDGEFCVooyQvjoWcqiRNTjTw",
+	"bB4Vj33BU5EWWS8ldtSHTeJnLN2uUDJIt2uTRLfZveAXm2kurWFASfuSa63OQQcDUmaUM+wOfPjYvxH+",
+	"7cBAMXXZ3t/VSbSUbWtgtgBpeyl3nVdLrbzGXBLCIPT/95s3b777f6/51n/e/v3bbw7CX99+91+xYzqC",
+	"TOES7qt8rfvFDztGzDPrc7bX6wXdD8WO5wimGsz8JdLtIAfVbtBYRtAdHp/VgK1pZHDay7PiNBkJYs2n",
+	"1jJpdGwNu0I5O6lEYbeEZFoVwL7h+QJLC1wgjfJvEIW+Zc7xgcm6xqoFDe6hms9t2eIzF34Lklm/v9NB",
+	"OcSvydbdt/6/W28HEE0V4CK7D7jlEfs9XFEEq2revMnth2NbKhxvTQdMYX05Se/YvJ4TTjl0cIFHcmX5",
+	"dHBRqfpjeKQGkw68VZHNuZxB3qalRNwY4yTGFq0qqL3ZsFdthL6fSBEKjhe+9IVQiACnOCRxO6yDIdvK",
+	"czFzRVkXTN4Td6uyvI/SV/RK0dc/HMM6SDVSsAeTIhBBfdQmh16Z1we2GX3TFhmHMNl972qEfJPEOYYq",
+	"TQdLXpOQxcGjAw3rQRp3tETbPQx+4WIPPjAyJAfrFWyC0lwi6uFF75AywLMMjBkUvemVjQT34WEL4e5I",
+	"C2GTcuBl8nEd+Q1CxcB1zInf27rODvTn63x9o93RkubHzHZqWUMPqRCjPsCtGVRhrxUQftiHSJWm3DPm",
+	"oAtK7CgWEhSBpc63Xwhj66qkXnmYBuYruTrRkw/d8xbk3p5fztrvVc5udk+WWmG2kJ3DkmG0zM5B+PLp",
+	"JvXziopBD5aLi1gd78fE25Wv97YyKEHe6JCuo9cXYbHyhrca99BFUNW84Z3Gc3CRXoodX1GAp4nP/h/Z",
+	"ykFIJEHjcsycueo0R1LEwp4F+A61agje7zhoNjPfcadcL+AilhJ6uTrztoR8uHR8pRa9nj/Kjw3odXbK",
+	"jXHAy3GN204YVzGcP4Z+exlcamDs9FCIYshT0DNowhc8d8ULvDgMcMUXAkd86gt8e2SEI5LpeVkE/Ktj",
+	"0sXAIR65KENN52tP8esZfPAZXJUs19Hib8jUl+sT5i/lfxue5OmUb6zj6NL6PZcGOgMJzpnsC/UwnRyk",
+	"FRm3SmOsMuYvWxflSLtu1NjMWHZbwFZlgNVjCZqUjKzSUnDUWPTHOE8gficdHU5Z3SaXkV9pYZcv8PS9",
+	"W4vsqXuVnbe/HtU6xC+/v6zrw0g17dlec2tLpyJQECDeJehpUyPA7h0+bjJPh542qlMy2Z5s77pIKkhe",
+	"iuQg2d+ebO/74CFBv3M8B17Y+X/wxyxWbHBE6pNhe5MJEz4r2ZUxURVc6XSsSkqnAjdhTMyLwuTCn/33",
+	"e6Xje5NJr/bJwnu7UxZc9KqdWtP1+a+R81spdHoxCF14gsnB67dp4rOyMf+bwGTZHDLKUiZ17nXidid5",
+	"i6/uIFa7LNYdp2plbZRJmcjeEdUaxjF9joWveAeAjzg7I69u5uEqXmmaJjVP6NrOqcsza12PLcFus3vF",
+	"OV8an6ye+wyavckeM8pnb1EpeONbpUqHc29mcdnUNboEnDdy5SCPwIDMaT2/hUt31APG/ojpvsPFbJcs",
+	"Ygu53cVq34G9yV7UNGj310MFuavBngzN2Hx2p9vLwBUSbn5roNrQlfuNeT1WE7gOT91BMF5jyCpWBdjL",
+	"q0HcXQ5j7X0lTbUAs24SoqsF16fOpuqY4tSbyYcB0egKzJIuUgWi7ZoQKSI8o+h0Z4B2myW1DoIvC53c",
+	"BrUnHXhTBnCISnGHkcdRISIPnf/fTP1hmbd5WviDyuBwmBEzss4Dz+gKplCuyTXhSCePJYodk482V9ft",
+	"HKsexl3J3S6QA8Wzuo8yezT/ZV1DBmplUBVWbE15Rppcq9chKwCJCObOk5IocFIqH+8kxFydaO5Mdse8",
+	"1e39Qu/tj3kvaMzimhqMeKnpfOBq48e8sVJAf2vZwRM1Y0Kup35V2WHyP4IzdUpk7SMGgbhwnjnlux3h",
+	"X72gmPKFKJYxose5rofqYykYY0XDEzWjqHRlvzRxgOfv9nwIARZTvlObW1tNqswQMpD3wfiKscBGa9J5",
+	"A4YCeePO9VULbVGi7yurJJgVNDmC2vzsZPok18jG4ylFEU76U2MYd5d/8xzxE+NboL/Wp9XfkvU4Z5Ut",
+	"hzGt3ufa1nr5HKt527r4OKq5Lpd+GIXUTiBTpPlmVpwBNelhgtq9ZK7XRd13jvcdH5TCL2zEdGobD1wn",
+	"SkbaG6zFR7fqD5OxY4Rf0P7qFmGh2y3GQ0QZgYA7HgvWWPykEa3XmhpLHwiK5jCCaJUZ9G6t8r+gC8s1",
+	"ycpIn5dr1pNHM9inm7XTvy6v9edWo7lDt7rZyBpU3+QgePjehVfJQ/D00b2V1HdEZD+p0l38dewYRxns",
+	"sd/RCTB/zrNh5M858d8Br8HTKb9Wn0HgC791NuEN21JfmpFzvzZS+Vo23Zqvw9RSezR2pkrPlB3hCQ74",
+	"ev0y0+B6uo5xCd+Ar/cRreWwDRXfGhfvYXfHvmAnr++e1MORMZjoBo5w7kbxjzoC1Fp0x19XqJlpUdNf",
+	"7+BMegMucTUWNLhuPIoWNYw15Lv49CWGCkIcWoM93lkzSuT3PDvUlojQxXtwnRYbDEE5H/wckPP0XqnB",
+	"gKt95u5RdzLt3UsO78Y4kUI/z6d2Jf11dIbbShAhGsbJoUlvXBsMRz7YFCJRPXNbZtT0LwARVsuZWHD8",
+	"yF8Kc22IstJ2KIIr99w1E9SrogDzIed+Jb/7bTGXfgJqks7qm3pq7HC/316kA7zR5TSabrEZhUIUMbcp",
+	"k3zRKbSMGPH0DTyq62JS3eK0KH/a/aizhd2pVpHOwZLTXn0u8aHP2OHltruLohEUbzjgzp+IsxcO2Quw",
+	"kQwsd39ND+23GTUTY6cApe/FUQDhPzupLPpSpaKCatB1tTAWA7eksUIZbpqGMjapdA98IcRnhVd3xrzU",
+	"3sxwi/DKbfdGvErj4pQ4bitIle59JiovrzWws5FvHTV49RVDxkvVYbwIr8B8/Wdks1l46w41PWkusPFP",
+	"hu8n6ucKvk2TstoUqAweOZukFdsrJeUrOOrS9K9Zjge1qdfth99IDw6Wr/z2hqjJbfcoOd40RBxmvUXR",
+	"9LRcYbWv/IMegcaW0A7ZyZp6gI1DwZcCbBxI3TVHjHPtGkcM9FfLjhjpeuyNGOivfBwx0ihtx32RLi0d",
+	"MbJ3nyByueu0J7vdYtfakzVyBVevPBHyNMKAH91nP+z98AMrhDw1tROcSihSVnD8X7pu2PuIXDYI4oVx",
+	"zbMLbi1oZs9VkzLtuwluuJTt31sv8Yi3qI7lGhuYtjBsbvh48Xmw0dtmttfIVvNC93uT2e59l74OLWaU",
+	"v3KPrkOY9yoxr9koj9xzssYw9zsSuVw9NocftkNjPgiDPzsbeyjKGEGvPmo2YnoH3tc9f6PS+oXVwBe1",
+	"3xtf6bIiugfJ8yPGDTFgfEYfT+uuo+0dXqwU2WlbrtW55O6AcUY1nNTdI2XPHtAvn/ZEc5egkVPTfVHY",
+	"n9Q/c++jwt6ySN8znq7uzFRRLfC+VB+QcI0K3PXAbgPq65t7GVT07PYoJF+SsP+wbtnht99vyXz1+6si",
+	"l+q8MnO2flw0Kb3B+S5eUwPU5soMt+WfhyHy/ZiXetdf3qY0OiLMQcnbsjfXHnhNVNq70Gm0y6XkxFno",
+	"esVLsRfX39t9wbOu8CMd/rXNHuKL1JjZtL3SWSFOIbiH17c3oHvA6XJKKxYuXtnc6KgkBSHVuUybPIym",
+	"HTv9wubHdR9qY1UZXNzd54hzbuaQ12ol6bJ1h2x8fJLpZWndKIM9G+umzPUCTDs1rhpj9bQECqvKpc99",
+	"vef2BWs8uGULhX0vJxP3Gq5tlz0VP8bYsWshfjV2nOvlUSU92xqjUH10rtL1Fl2n86TTzT/C0Z5XNlPB",
+	"DS80vMFmrc4/Fza2O+al1Xta6dV/jHk1fkXqLWKE7qhHMEJ3EfuwnkePG0bo1DOhnSVMnlBnDvt/1q0E",
+	"iF7dPXtMc+k1Ow0FnHGZwTZzF8G79vd0PU+KWqK/St25Nevet/th61ul8UNzaqMPS2bQHbbN/lcFWrjX",
+	"zBwPRbPzYIKWcc75mUvlwMdtu3tixYTkDvUXdaF+StLbQV+vLcaB3DZdjQO9G+UhGq81fnTvlPc5fXov",
+	"Dq4MT6ivdZEpgeLCI5h16PnBfp5P7OHpL/OrV+dTclRH4iM46p8iXxumftE2zjPhlQqukUIdi26ahEm6",
+	"Za8AxM2CqiF990YNxioN+TZ7sNJrr6w0FgROlWYzpXw+myt6Q2L1Dcu6PfpQ7yq5MZC/GQp6e8/T2hAZ",
+	"jmGPH8QDZCK/VHhshPU5pUukY9xpTSy+8ubjFxoburO7N0aJid2x724HH5NeH79C/DZmAgz4utLNGXWx",
+	"pHeRDwWnbhdlXMKDM32mJAzS0eSjepejIdsP9Ozux2i96RkgDDNWYHGds4DPuh0bH0+3cPmdy+g/hZv5",
+	"a7z5EtkbgyRdxq83uoc4S+J2pRngN3QH9P7d76lFvx8QPPr+7mTv21oxXHspksjmJHztPHze63rXOEDC",
+	"e6Longv/7/qKe57nrT+c4HHVwfRPL8NYfVGIuxCrN1Xqy96CFQnDNGDzMH/Vtb8alRRYdMT41PXe/T/k",
+	"KooV5lA3xtuuFIyNkm3RFv39ciytvVKr730mHLvSN3vNLm/YSTTIpH1eTWW+8seYzjW5u+YIOvfIjy9J",
+	"c9wzZgp3KdRfck+eZn9HuLvhLPNRSV8CwKWzUNF6VBI+gq74ge6yL0XXJEpdK5c2ZvkNipZt9pCCrPR3",
+	"SlruXDFILN3tCHn61UJY6v/gPkMBTWXZKZTWe+XwamzHTd1jZ0aiPCANtxYz7fepNKq++oRkBQ2MCATH",
+	"JL4UiXA5phlI3q88+3Pi2ZfK7fhqWy9bzjXM8brusB3vq1rXmYkGUEF6cLEEfd6FK9HRiYzMu7aWYGPl",
+	"vfiRm2Y/n8Jk9kv9StofkbRvD3HR2Y4nrrpCdLOSQUND1eI5ina6ss5VQXOD3S+bisN+lKu9HenGCezj",
+	"C+3YZU/XXL7wVVB/1s6newF1MKtGU2glC5WdDku/J2JqHYHiON/Kgvt+xdJ3gzB16J20CUZtbxm3dFPP",
+	"Kq2+oik/vSi8M9BE3m3Jp2iG9dcr0aGtXoOs3aTh7g0Cr9/ioRoCLYY9T1TGC+aeJ2lS6cJfI3Cws1Pg",
+	"s7ky9uCHyWSyw0uxc7abXLy9+P8DAPGfYygxqwAA",
 }
 
 // GetSwagger returns the content of the embedded swagger specification file
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/deepmap/oapi-codegen/pkg/middleware"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// ClaimsContextKey is the echo context key the claims of the authenticated caller are stored under
+	ClaimsContextKey = "auth/claims"
+
+	bearerScheme     = "bearerAuth"
+	bearerPrefix     = "Bearer "
+	errNoEchoContext = "missing echo context"
+	errUnknownScheme = "unknown security scheme"
+	errMissingToken  = "missing bearer token"
+	errInvalidToken  = "invalid bearer token"
+)
+
+// NewAuthenticationFunc returns an openapi3filter authentication function that validates bearer tokens
+// and stores the claims of the caller in the echo context
+func NewAuthenticationFunc(tokens *TokenManager) openapi3filter.AuthenticationFunc {
+	return func(ctx context.Context, input *openapi3filter.AuthenticationInput) error {
+		ec := middleware.GetEchoContext(ctx)
+		if ec == nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, errNoEchoContext)
+		}
+
+		if input.SecuritySchemeName != bearerScheme {
+			return echo.NewHTTPError(http.StatusUnauthorized, errUnknownScheme)
+		}
+
+		header := input.RequestValidationInput.Request.Header.Get(echo.HeaderAuthorization)
+		if len(header) <= len(bearerPrefix) || !strings.EqualFold(header[:len(bearerPrefix)], bearerPrefix) {
+			return echo.NewHTTPError(http.StatusUnauthorized, errMissingToken)
+		}
+
+		claims, err := tokens.ParseAccessToken(header[len(bearerPrefix):])
+		if err != nil {
+			return echo.NewHTTPError(http.StatusUnauthorized, errInvalidToken).SetInternal(err)
+		}
+
+		ec.Set(ClaimsContextKey, claims)
+
+		return nil
+	}
+}
+
+// ClaimsFromContext returns the claims of the authenticated caller
+func ClaimsFromContext(ctx echo.Context) (*Claims, bool) {
+	claims, ok := ctx.Get(ClaimsContextKey).(*Claims)
+	return claims, ok && claims != nil
+}
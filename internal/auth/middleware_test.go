@@ -0,0 +1,100 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/danielMensah/user-management/internal/config"
+	"github.com/deepmap/oapi-codegen/pkg/middleware"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewAuthenticationFunc(t *testing.T) {
+	tokens, err := New(&config.Config{
+		JWTAlgorithm:   "HS256",
+		JWTSecret:      "secret",
+		JWTIssuer:      "issuer",
+		JWTAudience:    "audience",
+		AccessTokenTTL: 15 * time.Minute,
+	})
+	require.NoError(t, err)
+
+	token, err := tokens.IssueAccessToken(Identity{ID: "id", Email: "jd@example.com", Roles: []string{"user"}})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		scheme         string
+		header         string
+		expectedStatus int
+		expectedErr    string
+	}{
+		{
+			name:   "authenticates valid bearer token",
+			scheme: bearerScheme,
+			header: "Bearer " + token,
+		},
+		{
+			name:           "rejects missing header",
+			scheme:         bearerScheme,
+			expectedStatus: http.StatusUnauthorized,
+			expectedErr:    errMissingToken,
+		},
+		{
+			name:           "rejects non bearer header",
+			scheme:         bearerScheme,
+			header:         "Basic dXNlcjpwYXNz",
+			expectedStatus: http.StatusUnauthorized,
+			expectedErr:    errMissingToken,
+		},
+		{
+			name:           "rejects invalid token",
+			scheme:         bearerScheme,
+			header:         "Bearer invalid",
+			expectedStatus: http.StatusUnauthorized,
+			expectedErr:    errInvalidToken,
+		},
+		{
+			name:           "rejects unknown scheme",
+			scheme:         "apiKey",
+			header:         "Bearer " + token,
+			expectedStatus: http.StatusUnauthorized,
+			expectedErr:    errUnknownScheme,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/users", nil)
+			if tt.header != "" {
+				request.Header.Set(echo.HeaderAuthorization, tt.header)
+			}
+			ec := echo.New().NewContext(request, httptest.NewRecorder())
+			ctx := context.WithValue(context.Background(), middleware.EchoContextKey, ec)
+
+			err := NewAuthenticationFunc(tokens)(ctx, &openapi3filter.AuthenticationInput{
+				RequestValidationInput: &openapi3filter.RequestValidationInput{Request: request},
+				SecuritySchemeName:     tt.scheme,
+			})
+
+			claims, ok := ClaimsFromContext(ec)
+			if tt.expectedErr != "" {
+				var httpErr *echo.HTTPError
+				require.True(t, errors.As(err, &httpErr))
+				assert.Equal(t, tt.expectedStatus, httpErr.Code)
+				assert.Equal(t, tt.expectedErr, httpErr.Message)
+				assert.False(t, ok)
+			} else {
+				require.NoError(t, err)
+				require.True(t, ok)
+				assert.Equal(t, "id", claims.Subject)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/danielMensah/user-management/internal/config"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Supported password hashing algorithms
+const (
+	PasswordAlgorithmBcrypt   = "bcrypt"
+	PasswordAlgorithmArgon2id = "argon2id"
+)
+
+const (
+	argon2idSaltBytes = 16
+	argon2idKeyBytes  = 32
+	bcryptHashBytes   = 60
+
+	// bounds of the argon2id parameters, the memory in KiB
+	argon2idMaxMemory     = 1 << 20
+	argon2idMaxIterations = 16
+	argon2idMaxKeyBytes   = 64
+
+	errHashPassword        = "failed to hash password"
+	errUnknownPasswordHash = "unknown password hash format"
+	errMalformedHash       = "malformed password hash"
+	errUnsupportedHasher   = "unsupported password hashing algorithm"
+)
+
+// PasswordHasher hashes passwords into an encoded form that records the algorithm and parameters used, so that
+// hashes remain verifiable after the parameters change
+type PasswordHasher interface {
+	// Hash returns the encoded hash of a password
+	Hash(password string) (string, error)
+	// Verify reports whether a password matches an encoded hash
+	Verify(encoded, password string) (bool, error)
+	// NeedsRehash reports whether an encoded hash was produced with other parameters than the current ones
+	NeedsRehash(encoded string) bool
+}
+
+// BcryptHasher hashes passwords with bcrypt, encoded as "$2a$<cost>$<salt and hash>"
+type BcryptHasher struct {
+	cost int
+}
+
+// NewBcryptHasher creates a bcrypt hasher with the given cost
+func NewBcryptHasher(cost int) *BcryptHasher {
+	return &BcryptHasher{cost: cost}
+}
+
+// Hash returns the bcrypt hash of a password
+func (b *BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), b.cost)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", errHashPassword, err)
+	}
+
+	return string(hash), nil
+}
+
+// Verify reports whether a password matches a bcrypt hash
+func (b *BcryptHasher) Verify(encoded, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", errMalformedHash, err)
+	}
+
+	return true, nil
+}
+
+// NeedsRehash reports whether a bcrypt hash uses another cost
+func (b *BcryptHasher) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+
+	return err != nil || cost != b.cost
+}
+
+// IsBcryptHash reports whether a value is a bcrypt hash rather than a password, such as the hashes of users imported
+// from another system. Bcrypt hashes are verified like the ones the service produces, and upgraded when rehashed.
+func IsBcryptHash(value string) bool {
+	if len(value) != bcryptHashBytes || passwordAlgorithm(value) != PasswordAlgorithmBcrypt {
+		return false
+	}
+
+	_, err := bcrypt.Cost([]byte(value))
+
+	return err == nil
+}
+
+// Argon2idParams represents the parameters of argon2id
+type Argon2idParams struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+}
+
+// valid reports whether argon2id can hash with the parameters within the bounds the service accepts. Verifying a
+// stored hash derives a key with the parameters recorded in it, so unbounded ones could exhaust the memory or CPU of the
+// service, and argon2 panics on no iterations or parallelism.
+func (p Argon2idParams) valid() bool {
+	return p.Memory > 0 && p.Memory <= argon2idMaxMemory &&
+		p.Iterations > 0 && p.Iterations <= argon2idMaxIterations &&
+		p.Parallelism > 0
+}
+
+// Argon2idHasher hashes passwords with argon2id, encoded in the PHC string format as
+// "$argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<hash>"
+type Argon2idHasher struct {
+	params Argon2idParams
+}
+
+// NewArgon2idHasher creates an argon2id hasher with the given parameters
+func NewArgon2idHasher(params Argon2idParams) *Argon2idHasher {
+	return &Argon2idHasher{params: params}
+}
+
+// Hash returns the argon2id hash of a password
+func (a *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, argon2idSaltBytes)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("%s: %w", errHashPassword, err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, a.params.Iterations, a.params.Memory, a.params.Parallelism, argon2idKeyBytes)
+
+	return fmt.Sprintf(
+		"$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		PasswordAlgorithmArgon2id, argon2.Version, a.params.Memory, a.params.Iterations, a.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify reports whether a password matches an argon2id hash, using the parameters recorded in the hash
+func (a *Argon2idHasher) Verify(encoded, password string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+// NeedsRehash reports whether an argon2id hash uses other parameters
+func (a *Argon2idHasher) NeedsRehash(encoded string) bool {
+	params, _, key, err := decodeArgon2id(encoded)
+
+	return err != nil || params != a.params || len(key) != argon2idKeyBytes
+}
+
+func decodeArgon2id(encoded string) (Argon2idParams, []byte, []byte, error) {
+	var params Argon2idParams
+
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != PasswordAlgorithmArgon2id {
+		return params, nil, nil, errors.New(errMalformedHash)
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, errors.New(errMalformedHash)
+	}
+
+	_, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism)
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("%s: %w", errMalformedHash, err)
+	}
+	if !params.valid() {
+		return params, nil, nil, errors.New(errMalformedHash)
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("%s: %w", errMalformedHash, err)
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("%s: %w", errMalformedHash, err)
+	}
+
+	// an empty key would match every password
+	if len(salt) == 0 || len(key) == 0 || len(key) > argon2idMaxKeyBytes {
+		return params, nil, nil, errors.New(errMalformedHash)
+	}
+
+	return params, salt, key, nil
+}
+
+// upgradingHasher hashes passwords with the preferred algorithm while still verifying hashes of every supported
+// algorithm, so that hashes of another algorithm are upgraded when rehashed
+type upgradingHasher struct {
+	preferred string
+	hashers   map[string]PasswordHasher
+}
+
+// NewPasswordHasher creates the password hasher configured for the service
+func NewPasswordHasher(cfg *config.Config) (PasswordHasher, error) {
+	hashers := map[string]PasswordHasher{
+		PasswordAlgorithmBcrypt: NewBcryptHasher(cfg.BcryptCost),
+		PasswordAlgorithmArgon2id: NewArgon2idHasher(Argon2idParams{
+			Memory:      cfg.Argon2Memory,
+			Iterations:  cfg.Argon2Iterations,
+			Parallelism: cfg.Argon2Parallelism,
+		}),
+	}
+
+	if _, ok := hashers[cfg.PasswordHashAlgorithm]; !ok {
+		return nil, fmt.Errorf("%s: %s", errUnsupportedHasher, cfg.PasswordHashAlgorithm)
+	}
+
+	return &upgradingHasher{preferred: cfg.PasswordHashAlgorithm, hashers: hashers}, nil
+}
+
+// Hash returns the hash of a password using the preferred algorithm
+func (u *upgradingHasher) Hash(password string) (string, error) {
+	return u.hashers[u.preferred].Hash(password)
+}
+
+// Verify reports whether a password matches a hash of any supported algorithm
+func (u *upgradingHasher) Verify(encoded, password string) (bool, error) {
+	hasher, ok := u.hashers[passwordAlgorithm(encoded)]
+	if !ok {
+		return false, errors.New(errUnknownPasswordHash)
+	}
+
+	return hasher.Verify(encoded, password)
+}
+
+// NeedsRehash reports whether a hash uses another algorithm than the preferred one or outdated parameters
+func (u *upgradingHasher) NeedsRehash(encoded string) bool {
+	algorithm := passwordAlgorithm(encoded)
+
+	return algorithm != u.preferred || u.hashers[algorithm].NeedsRehash(encoded)
+}
+
+// passwordAlgorithm returns the algorithm an encoded hash was produced with
+func passwordAlgorithm(encoded string) string {
+	switch {
+	case strings.HasPrefix(encoded, "$"+PasswordAlgorithmArgon2id+"$"):
+		return PasswordAlgorithmArgon2id
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		return PasswordAlgorithmBcrypt
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,196 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha1" //nolint:gosec // breached password corpora are indexed by SHA-1
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/danielMensah/user-management/internal/config"
+)
+
+// Rules of the password policy
+const (
+	PasswordRuleMinLength        = "min_length"
+	PasswordRuleMaxLength        = "max_length"
+	PasswordRuleCharacterClasses = "character_classes"
+	PasswordRulePersonalInfo     = "personal_info"
+	PasswordRuleBreached         = "breached"
+)
+
+const (
+	breachedPrefixLength = 5
+	minPersonalInfoRunes = 3
+
+	errBreachedPasswordsDir = "breached passwords directory is not readable"
+	errCheckBreached        = "failed to check breached passwords"
+)
+
+// PasswordViolation represents a rule of the password policy that a password does not satisfy
+type PasswordViolation struct {
+	Rule    string
+	Message string
+}
+
+// PasswordPolicy checks that passwords are strong enough before they are hashed
+type PasswordPolicy struct {
+	minLength   int
+	maxLength   int
+	minClasses  int
+	breachedDir string
+}
+
+// NewPasswordPolicy creates the password policy configured for the service. Checking breached passwords is
+// skipped unless a directory of SHA-1 range files is configured.
+func NewPasswordPolicy(cfg *config.Config) (*PasswordPolicy, error) {
+	if cfg.BreachedPasswordsDir != "" {
+		info, err := os.Stat(cfg.BreachedPasswordsDir)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", errBreachedPasswordsDir, err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("%s: %s is not a directory", errBreachedPasswordsDir, cfg.BreachedPasswordsDir)
+		}
+	}
+
+	return &PasswordPolicy{
+		minLength:   cfg.PasswordMinLength,
+		maxLength:   cfg.PasswordMaxLength,
+		minClasses:  cfg.PasswordMinCharacterClasses,
+		breachedDir: cfg.BreachedPasswordsDir,
+	}, nil
+}
+
+// Check returns every rule the password violates. Personal information such as the email or nickname of the user
+// must not appear in the password.
+func (p *PasswordPolicy) Check(password string, personalInfo ...string) ([]PasswordViolation, error) {
+	violations := make([]PasswordViolation, 0)
+
+	if length := utf8.RuneCountInString(password); length < p.minLength {
+		violations = append(violations, PasswordViolation{
+			Rule:    PasswordRuleMinLength,
+			Message: fmt.Sprintf("must be at least %d characters long", p.minLength),
+		})
+	}
+
+	// bcrypt ignores everything past 72 bytes, so the maximum length is counted in bytes
+	if len(password) > p.maxLength {
+		violations = append(violations, PasswordViolation{
+			Rule:    PasswordRuleMaxLength,
+			Message: fmt.Sprintf("must be at most %d bytes long", p.maxLength),
+		})
+	}
+
+	if characterClasses(password) < p.minClasses {
+		violations = append(violations, PasswordViolation{
+			Rule: PasswordRuleCharacterClasses,
+			Message: fmt.Sprintf(
+				"must contain at least %d of lowercase letters, uppercase letters, digits and symbols", p.minClasses,
+			),
+		})
+	}
+
+	if containsPersonalInfo(password, personalInfo) {
+		violations = append(violations, PasswordViolation{
+			Rule:    PasswordRulePersonalInfo,
+			Message: "must not contain the email or nickname",
+		})
+	}
+
+	if p.breachedDir != "" {
+		breached, err := p.breached(password)
+		if err != nil {
+			return nil, err
+		}
+
+		if breached {
+			violations = append(violations, PasswordViolation{
+				Rule:    PasswordRuleBreached,
+				Message: "appears in a known data breach",
+			})
+		}
+	}
+
+	return violations, nil
+}
+
+// breached looks the password up in the range file of its SHA-1 prefix. Range files are named after the first
+// five hex characters of the hash and list the remaining characters of each breached hash, as in "<suffix>:<count>".
+func (p *PasswordPolicy) breached(password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+	prefix, suffix := hash[:breachedPrefixLength], hash[breachedPrefixLength:]
+
+	file, err := os.Open(filepath.Join(p.breachedDir, prefix+".txt"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("%s: %w", errCheckBreached, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), ":")
+		if strings.EqualFold(strings.TrimSpace(line), suffix) {
+			return true, nil
+		}
+	}
+
+	if err = scanner.Err(); err != nil {
+		return false, fmt.Errorf("%s: %w", errCheckBreached, err)
+	}
+
+	return false, nil
+}
+
+// characterClasses counts the classes of characters among lowercase letters, uppercase letters, digits and symbols
+// that the password contains
+func characterClasses(password string) int {
+	var lower, upper, digit, symbol int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+
+	return lower + upper + digit + symbol
+}
+
+// containsPersonalInfo reports whether the password contains any of the given values, or the local part of an email,
+// ignoring case. Values too short to be meaningful are ignored.
+func containsPersonalInfo(password string, personalInfo []string) bool {
+	password = strings.ToLower(password)
+
+	for _, info := range personalInfo {
+		info = strings.ToLower(strings.TrimSpace(info))
+
+		candidates := []string{info}
+		if local, _, ok := strings.Cut(info, "@"); ok {
+			candidates = append(candidates, local)
+		}
+
+		for _, candidate := range candidates {
+			if utf8.RuneCountInString(candidate) >= minPersonalInfoRunes && strings.Contains(password, candidate) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/danielMensah/user-management/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordPolicy_Check(t *testing.T) {
+	// the range file of the SHA-1 of "password", 5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8
+	dir := t.TempDir()
+	rangeFile := "0018A45C4D1DEF81644B54AB7F969B88D65:1\r\n1E4C9B93F3F0682250B6CF8331B7EE68FD8:9545824\r\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "5BAA6.txt"), []byte(rangeFile), 0o600))
+
+	policy, err := NewPasswordPolicy(&config.Config{
+		PasswordMinLength:           8,
+		PasswordMaxLength:           16,
+		PasswordMinCharacterClasses: 3,
+		BreachedPasswordsDir:        dir,
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		password      string
+		personalInfo  []string
+		expectedRules []string
+	}{
+		{
+			name:          "accepts strong password",
+			password:      "Tr0ub4dor&3",
+			personalInfo:  []string{"jd@example.com", "jd"},
+			expectedRules: []string{},
+		},
+		{
+			name:          "rejects short password",
+			password:      "Ab1!",
+			expectedRules: []string{PasswordRuleMinLength},
+		},
+		{
+			name:          "rejects long password",
+			password:      "Correct-Horse-Battery-Staple-1",
+			expectedRules: []string{PasswordRuleMaxLength},
+		},
+		{
+			name:          "rejects password with too few character classes",
+			password:      "abcdefghij",
+			expectedRules: []string{PasswordRuleCharacterClasses},
+		},
+		{
+			name:          "rejects password containing the email",
+			password:      "John.Doe-2020",
+			personalInfo:  []string{"john.doe@example.com", "jd"},
+			expectedRules: []string{PasswordRulePersonalInfo},
+		},
+		{
+			name:          "rejects password containing the nickname",
+			password:      "xX-Johnny-99",
+			personalInfo:  []string{"jd@example.com", "johnny"},
+			expectedRules: []string{PasswordRulePersonalInfo},
+		},
+		{
+			name:          "rejects breached password",
+			password:      "password",
+			expectedRules: []string{PasswordRuleCharacterClasses, PasswordRuleBreached},
+		},
+		{
+			name:          "lists every violated rule",
+			password:      "jdjd",
+			personalInfo:  []string{"jdjd@example.com"},
+			expectedRules: []string{PasswordRuleMinLength, PasswordRuleCharacterClasses, PasswordRulePersonalInfo},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations, err := policy.Check(tt.password, tt.personalInfo...)
+			require.NoError(t, err)
+
+			rules := make([]string, 0, len(violations))
+			for _, v := range violations {
+				assert.NotEmpty(t, v.Message)
+				rules = append(rules, v.Rule)
+			}
+
+			assert.Equal(t, tt.expectedRules, rules)
+		})
+	}
+}
+
+func TestNewPasswordPolicy(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+
+	tests := []struct {
+		name        string
+		dir         string
+		expectedErr string
+	}{
+		{
+			name: "skips breached passwords without a directory",
+		},
+		{
+			name:        "errors when the directory does not exist",
+			dir:         filepath.Join(t.TempDir(), "missing"),
+			expectedErr: errBreachedPasswordsDir,
+		},
+		{
+			name:        "errors when the directory is a file",
+			dir:         file,
+			expectedErr: errBreachedPasswordsDir,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := NewPasswordPolicy(&config.Config{PasswordMinLength: 1, PasswordMaxLength: 72, BreachedPasswordsDir: tt.dir})
+
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+
+			violations, err := policy.Check("password")
+			require.NoError(t, err)
+			assert.Empty(t, violations)
+		})
+	}
+}
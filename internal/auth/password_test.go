@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/danielMensah/user-management/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var testArgon2idParams = Argon2idParams{Memory: 1024, Iterations: 1, Parallelism: 1}
+
+func TestPasswordHashers(t *testing.T) {
+	tests := []struct {
+		name           string
+		hasher         PasswordHasher
+		expectedPrefix string
+	}{
+		{
+			name:           "bcrypt",
+			hasher:         NewBcryptHasher(bcrypt.MinCost),
+			expectedPrefix: "$2a$04$",
+		},
+		{
+			name:           "argon2id",
+			hasher:         NewArgon2idHasher(testArgon2idParams),
+			expectedPrefix: "$argon2id$v=19$m=1024,t=1,p=1$",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash, err := tt.hasher.Hash("password")
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(hash, tt.expectedPrefix), hash)
+
+			valid, err := tt.hasher.Verify(hash, "password")
+			require.NoError(t, err)
+			assert.True(t, valid)
+
+			valid, err = tt.hasher.Verify(hash, "wrong")
+			require.NoError(t, err)
+			assert.False(t, valid)
+
+			other, err := tt.hasher.Hash("password")
+			require.NoError(t, err)
+			assert.NotEqual(t, hash, other)
+
+			assert.False(t, tt.hasher.NeedsRehash(hash))
+		})
+	}
+}
+
+func TestArgon2idHasher_Verify(t *testing.T) {
+	hasher := NewArgon2idHasher(testArgon2idParams)
+
+	tests := []struct {
+		name          string
+		encoded       string
+		expectedValid bool
+		expectedErr   string
+	}{
+		{
+			name:          "verifies hash with other parameters",
+			encoded:       mustHash(t, NewArgon2idHasher(Argon2idParams{Memory: 2048, Iterations: 2, Parallelism: 2})),
+			expectedValid: true,
+		},
+		{
+			name:        "rejects hash with an empty key",
+			encoded:     "$argon2id$v=19$m=1024,t=1,p=1$c2FsdHNhbHQ$",
+			expectedErr: errMalformedHash,
+		},
+		{
+			name:        "rejects hash with another version",
+			encoded:     "$argon2id$v=16$m=1024,t=1,p=1$c2FsdHNhbHQ$a2V5",
+			expectedErr: errMalformedHash,
+		},
+		{
+			name:        "rejects malformed hash",
+			encoded:     "$argon2id$v=19$m=1024",
+			expectedErr: errMalformedHash,
+		},
+		{
+			name:        "rejects hash without iterations",
+			encoded:     "$argon2id$v=19$m=1024,t=0,p=1$c2FsdHNhbHQ$a2V5",
+			expectedErr: errMalformedHash,
+		},
+		{
+			name:        "rejects hash without parallelism",
+			encoded:     "$argon2id$v=19$m=1024,t=1,p=0$c2FsdHNhbHQ$a2V5",
+			expectedErr: errMalformedHash,
+		},
+		{
+			name:        "rejects hash without memory",
+			encoded:     "$argon2id$v=19$m=0,t=1,p=1$c2FsdHNhbHQ$a2V5",
+			expectedErr: errMalformedHash,
+		},
+		{
+			name:        "rejects hash with too much memory",
+			encoded:     "$argon2id$v=19$m=4294967295,t=1,p=1$c2FsdHNhbHQ$a2V5",
+			expectedErr: errMalformedHash,
+		},
+		{
+			name:        "rejects hash with too many iterations",
+			encoded:     "$argon2id$v=19$m=1024,t=4294967295,p=1$c2FsdHNhbHQ$a2V5",
+			expectedErr: errMalformedHash,
+		},
+		{
+			name: "rejects hash with too long a key",
+			encoded: "$argon2id$v=19$m=1024,t=1,p=1$c2FsdHNhbHQ$" +
+				base64.RawStdEncoding.EncodeToString(make([]byte, argon2idMaxKeyBytes+1)),
+			expectedErr: errMalformedHash,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid, err := hasher.Verify(tt.encoded, "password")
+
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedValid, valid)
+		})
+	}
+}
+
+func TestNewPasswordHasher(t *testing.T) {
+	cfg := &config.Config{
+		PasswordHashAlgorithm: PasswordAlgorithmArgon2id,
+		BcryptCost:            bcrypt.MinCost + 1,
+		Argon2Memory:          testArgon2idParams.Memory,
+		Argon2Iterations:      testArgon2idParams.Iterations,
+		Argon2Parallelism:     testArgon2idParams.Parallelism,
+	}
+
+	hasher, err := NewPasswordHasher(cfg)
+	require.NoError(t, err)
+
+	current := mustHash(t, hasher)
+	legacyBcrypt := mustHash(t, NewBcryptHasher(bcrypt.MinCost))
+	weakerArgon2id := mustHash(t, NewArgon2idHasher(Argon2idParams{Memory: 512, Iterations: 1, Parallelism: 1}))
+
+	tests := []struct {
+		name                string
+		encoded             string
+		expectedNeedsRehash bool
+	}{
+		{
+			name:    "current hash",
+			encoded: current,
+		},
+		{
+			name:                "hash of another algorithm",
+			encoded:             legacyBcrypt,
+			expectedNeedsRehash: true,
+		},
+		{
+			name:                "hash with outdated parameters",
+			encoded:             weakerArgon2id,
+			expectedNeedsRehash: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid, err := hasher.Verify(tt.encoded, "password")
+			require.NoError(t, err)
+			assert.True(t, valid)
+
+			assert.Equal(t, tt.expectedNeedsRehash, hasher.NeedsRehash(tt.encoded))
+		})
+	}
+
+	_, err = hasher.Verify("plaintext", "password")
+	assert.ErrorContains(t, err, errUnknownPasswordHash)
+
+	cfg.PasswordHashAlgorithm = "md5"
+	_, err = NewPasswordHasher(cfg)
+	assert.ErrorContains(t, err, errUnsupportedHasher)
+}
+
+func TestIsBcryptHash(t *testing.T) {
+	assert.True(t, IsBcryptHash(mustHash(t, NewBcryptHasher(4))))
+	assert.True(t, IsBcryptHash("$2y$10$.vGA1O9wmRjrwAVXD98HNOgsNpDczlqm3Jq7KnEd1rVAGv3Fykk1a"))
+	assert.False(t, IsBcryptHash(mustHash(t, NewArgon2idHasher(Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 1}))))
+	assert.False(t, IsBcryptHash("$2a$10$tooshort"))
+	assert.False(t, IsBcryptHash("correct-Horse-battery-1"))
+}
+
+func mustHash(t *testing.T, hasher PasswordHasher) string {
+	t.Helper()
+
+	hash, err := hasher.Hash("password")
+	require.NoError(t, err)
+
+	return hash
+}
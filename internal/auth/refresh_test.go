@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOpaqueToken(t *testing.T) {
+	first, err := NewOpaqueToken()
+	require.NoError(t, err)
+
+	second, err := NewOpaqueToken()
+	require.NoError(t, err)
+
+	assert.Len(t, first, 43)
+	assert.NotEqual(t, first, second)
+}
+
+func TestHashOpaqueToken(t *testing.T) {
+	hash := HashOpaqueToken("token")
+
+	assert.Equal(t, "3c469e9d6c5875d37a43f353d4f88e61fcf812c66eee3457465a40b0da4153e0", hash)
+	assert.Equal(t, hash, HashOpaqueToken("token"))
+	assert.NotEqual(t, hash, HashOpaqueToken("other"))
+}
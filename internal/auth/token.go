@@ -0,0 +1,230 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/danielMensah/user-management/internal/config"
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	algorithmHS256 = "HS256"
+	algorithmRS256 = "RS256"
+	tokenType      = "Bearer"
+	mfaAudience    = "mfa"
+
+	errReadKeyFile    = "failed to read key file"
+	errParseKey       = "failed to parse key"
+	errUnsupportedAlg = "unsupported signing algorithm"
+	errSignToken      = "failed to sign token"
+	errParseToken     = "failed to parse token"
+	errUnexpectedAlg  = "unexpected signing algorithm"
+	errTokenExpired   = "token is expired or not yet valid"
+	errTokenIssuer    = "token has an invalid issuer"
+	errTokenAudience  = "token has an invalid audience"
+)
+
+// Identity represents the user an access token is issued for
+type Identity struct {
+	ID            string
+	Email         string
+	EmailVerified bool
+	Country       string
+	Roles         []string
+}
+
+// Claims represents the claims carried by the access tokens issued by the service
+type Claims struct {
+	Email         string   `json:"email"`
+	EmailVerified bool     `json:"email_verified"`
+	Country       string   `json:"country,omitempty"`
+	Roles         []string `json:"roles,omitempty"`
+	jwt.StandardClaims
+}
+
+// HasRole reports whether the claims carry the given role
+func (c *Claims) HasRole(role string) bool {
+	for _, r := range c.Roles {
+		if r == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TokenManager issues signed access tokens
+type TokenManager struct {
+	method     jwt.SigningMethod
+	signKey    interface{}
+	verifyKey  interface{}
+	issuer     string
+	audience   string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	mfaTTL     time.Duration
+	now        func() time.Time
+}
+
+// New creates a new token manager from the service configuration
+func New(cfg *config.Config) (*TokenManager, error) {
+	m := &TokenManager{
+		issuer:     cfg.JWTIssuer,
+		audience:   cfg.JWTAudience,
+		accessTTL:  cfg.AccessTokenTTL,
+		refreshTTL: cfg.RefreshTokenTTL,
+		mfaTTL:     cfg.MFAChallengeTTL,
+		now:        time.Now,
+	}
+
+	switch cfg.JWTAlgorithm {
+	case algorithmHS256:
+		m.method = jwt.SigningMethodHS256
+		m.signKey = []byte(cfg.JWTSecret)
+		m.verifyKey = []byte(cfg.JWTSecret)
+	case algorithmRS256:
+		signKey, verifyKey, err := loadRSAKeys(cfg.JWTPrivateKeyFile, cfg.JWTPublicKeyFile)
+		if err != nil {
+			return nil, err
+		}
+
+		m.method = jwt.SigningMethodRS256
+		m.signKey = signKey
+		m.verifyKey = verifyKey
+	default:
+		return nil, fmt.Errorf("%s: %s", errUnsupportedAlg, cfg.JWTAlgorithm)
+	}
+
+	return m, nil
+}
+
+// TokenType returns the type of the issued access tokens
+func (m *TokenManager) TokenType() string {
+	return tokenType
+}
+
+// AccessTTL returns how long issued access tokens are valid for
+func (m *TokenManager) AccessTTL() time.Duration {
+	return m.accessTTL
+}
+
+// RefreshTTL returns how long issued refresh tokens are valid for
+func (m *TokenManager) RefreshTTL() time.Duration {
+	return m.refreshTTL
+}
+
+// MFATTL returns how long issued mfa challenge tokens are valid for
+func (m *TokenManager) MFATTL() time.Duration {
+	return m.mfaTTL
+}
+
+// IssueAccessToken issues a signed access token for the given user
+func (m *TokenManager) IssueAccessToken(identity Identity) (string, error) {
+	return m.sign(&Claims{
+		Email:         identity.Email,
+		EmailVerified: identity.EmailVerified,
+		Country:       identity.Country,
+		Roles:         identity.Roles,
+	}, identity.ID, m.audience, m.accessTTL)
+}
+
+// ParseAccessToken verifies the signature, expiry, issuer and audience of an access token and returns its claims
+func (m *TokenManager) ParseAccessToken(token string) (*Claims, error) {
+	return m.parse(token, m.audience)
+}
+
+// IssueMFAToken issues a short-lived token proving that the given user presented their password and still
+// has to complete an mfa challenge. Its audience differs from access tokens so it cannot be used as one.
+func (m *TokenManager) IssueMFAToken(userID string) (string, error) {
+	return m.sign(&Claims{}, userID, m.mfaAudience(), m.mfaTTL)
+}
+
+// ParseMFAToken verifies an mfa challenge token and returns the id of its user
+func (m *TokenManager) ParseMFAToken(token string) (string, error) {
+	claims, err := m.parse(token, m.mfaAudience())
+	if err != nil {
+		return "", err
+	}
+
+	return claims.Subject, nil
+}
+
+func (m *TokenManager) mfaAudience() string {
+	return m.audience + ":" + mfaAudience
+}
+
+func (m *TokenManager) sign(claims *Claims, subject, audience string, ttl time.Duration) (string, error) {
+	now := m.now().UTC()
+
+	claims.StandardClaims = jwt.StandardClaims{
+		Subject:   subject,
+		Issuer:    m.issuer,
+		Audience:  audience,
+		IssuedAt:  now.Unix(),
+		NotBefore: now.Unix(),
+		ExpiresAt: now.Add(ttl).Unix(),
+	}
+
+	token, err := jwt.NewWithClaims(m.method, claims).SignedString(m.signKey)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", errSignToken, err)
+	}
+
+	return token, nil
+}
+
+func (m *TokenManager) parse(token, audience string) (*Claims, error) {
+	claims := &Claims{}
+	parser := &jwt.Parser{SkipClaimsValidation: true}
+
+	_, err := parser.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Method.Alg() != m.method.Alg() {
+			return nil, fmt.Errorf("%s: %s", errUnexpectedAlg, t.Method.Alg())
+		}
+
+		return m.verifyKey, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errParseToken, err)
+	}
+
+	now := m.now().UTC().Unix()
+	if !claims.VerifyExpiresAt(now, true) || !claims.VerifyNotBefore(now, false) {
+		return nil, errors.New(errTokenExpired)
+	}
+	if !claims.VerifyIssuer(m.issuer, true) {
+		return nil, errors.New(errTokenIssuer)
+	}
+	if !claims.VerifyAudience(audience, true) {
+		return nil, errors.New(errTokenAudience)
+	}
+
+	return claims, nil
+}
+
+func loadRSAKeys(privateKeyFile, publicKeyFile string) (interface{}, interface{}, error) {
+	privatePEM, err := os.ReadFile(privateKeyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s '%s': %w", errReadKeyFile, privateKeyFile, err)
+	}
+
+	publicPEM, err := os.ReadFile(publicKeyFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s '%s': %w", errReadKeyFile, publicKeyFile, err)
+	}
+
+	signKey, err := jwt.ParseRSAPrivateKeyFromPEM(privatePEM)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", errParseKey, err)
+	}
+
+	verifyKey, err := jwt.ParseRSAPublicKeyFromPEM(publicPEM)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", errParseKey, err)
+	}
+
+	return signKey, verifyKey, nil
+}
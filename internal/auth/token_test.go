@@ -0,0 +1,323 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/danielMensah/user-management/internal/config"
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	privateKeyFile, publicKeyFile := writeRSAKeys(t)
+
+	tests := []struct {
+		name        string
+		cfg         *config.Config
+		expectedAlg string
+		expectedErr string
+	}{
+		{
+			name: "creates hs256 token manager",
+			cfg: &config.Config{
+				JWTAlgorithm:   "HS256",
+				JWTSecret:      "secret",
+				AccessTokenTTL: time.Minute,
+			},
+			expectedAlg: "HS256",
+		},
+		{
+			name: "creates rs256 token manager",
+			cfg: &config.Config{
+				JWTAlgorithm:      "RS256",
+				JWTPrivateKeyFile: privateKeyFile,
+				JWTPublicKeyFile:  publicKeyFile,
+				AccessTokenTTL:    time.Minute,
+			},
+			expectedAlg: "RS256",
+		},
+		{
+			name: "errors when key files are missing",
+			cfg: &config.Config{
+				JWTAlgorithm:      "RS256",
+				JWTPrivateKeyFile: filepath.Join(t.TempDir(), "missing.pem"),
+				JWTPublicKeyFile:  publicKeyFile,
+			},
+			expectedErr: errReadKeyFile,
+		},
+		{
+			name: "errors when keys are invalid",
+			cfg: &config.Config{
+				JWTAlgorithm:      "RS256",
+				JWTPrivateKeyFile: publicKeyFile,
+				JWTPublicKeyFile:  publicKeyFile,
+			},
+			expectedErr: errParseKey,
+		},
+		{
+			name: "errors on unsupported algorithm",
+			cfg: &config.Config{
+				JWTAlgorithm: "none",
+			},
+			expectedErr: errUnsupportedAlg,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.cfg)
+
+			if tt.expectedErr != "" {
+				assert.Nil(t, got)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedAlg, got.method.Alg())
+			}
+		})
+	}
+}
+
+func TestTokenManager_IssueAccessToken(t *testing.T) {
+	privateKeyFile, publicKeyFile := writeRSAKeys(t)
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		cfg  *config.Config
+	}{
+		{
+			name: "issues hs256 token",
+			cfg: &config.Config{
+				JWTAlgorithm:   "HS256",
+				JWTSecret:      "secret",
+				JWTIssuer:      "issuer",
+				JWTAudience:    "audience",
+				AccessTokenTTL: 15 * time.Minute,
+			},
+		},
+		{
+			name: "issues rs256 token",
+			cfg: &config.Config{
+				JWTAlgorithm:      "RS256",
+				JWTPrivateKeyFile: privateKeyFile,
+				JWTPublicKeyFile:  publicKeyFile,
+				JWTIssuer:         "issuer",
+				JWTAudience:       "audience",
+				AccessTokenTTL:    15 * time.Minute,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := New(tt.cfg)
+			require.NoError(t, err)
+			m.now = func() time.Time { return now }
+
+			token, err := m.IssueAccessToken(Identity{ID: "id", Email: "jd@example.com", EmailVerified: true, Roles: []string{"user"}})
+			require.NoError(t, err)
+
+			claims := &Claims{}
+			parser := &jwt.Parser{SkipClaimsValidation: true}
+			_, err = parser.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) {
+				return m.verifyKey, nil
+			})
+			require.NoError(t, err)
+
+			assert.Equal(t, "id", claims.Subject)
+			assert.Equal(t, "jd@example.com", claims.Email)
+			assert.True(t, claims.EmailVerified)
+			assert.Equal(t, []string{"user"}, claims.Roles)
+			assert.Equal(t, "issuer", claims.Issuer)
+			assert.Equal(t, "audience", claims.Audience)
+			assert.Equal(t, now.Add(15*time.Minute).Unix(), claims.ExpiresAt)
+		})
+	}
+}
+
+func writeRSAKeys(t *testing.T) (string, string) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	publicDER, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	privateKeyFile := filepath.Join(dir, "private.pem")
+	publicKeyFile := filepath.Join(dir, "public.pem")
+
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	require.NoError(t, os.WriteFile(privateKeyFile, privatePEM, 0o600))
+
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	require.NoError(t, os.WriteFile(publicKeyFile, publicPEM, 0o600))
+
+	return privateKeyFile, publicKeyFile
+}
+
+func TestTokenManager_ParseAccessToken(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	cfg := &config.Config{
+		JWTAlgorithm:   "HS256",
+		JWTSecret:      "secret",
+		JWTIssuer:      "issuer",
+		JWTAudience:    "audience",
+		AccessTokenTTL: 15 * time.Minute,
+	}
+
+	issuer, err := New(cfg)
+	require.NoError(t, err)
+	issuer.now = func() time.Time { return now }
+
+	token, err := issuer.IssueAccessToken(Identity{ID: "id", Email: "jd@example.com", Roles: []string{"user"}})
+	require.NoError(t, err)
+
+	otherIssuer, err := New(&config.Config{
+		JWTAlgorithm:   "HS256",
+		JWTSecret:      "secret",
+		JWTIssuer:      "other",
+		JWTAudience:    "audience",
+		AccessTokenTTL: 15 * time.Minute,
+	})
+	require.NoError(t, err)
+	otherIssuer.now = func() time.Time { return now }
+
+	otherIssuerToken, err := otherIssuer.IssueAccessToken(Identity{ID: "id", Email: "jd@example.com", Roles: []string{"user"}})
+	require.NoError(t, err)
+
+	otherAudience, err := New(&config.Config{
+		JWTAlgorithm:   "HS256",
+		JWTSecret:      "secret",
+		JWTIssuer:      "issuer",
+		JWTAudience:    "other",
+		AccessTokenTTL: 15 * time.Minute,
+	})
+	require.NoError(t, err)
+	otherAudience.now = func() time.Time { return now }
+
+	otherAudienceToken, err := otherAudience.IssueAccessToken(Identity{ID: "id", Email: "jd@example.com", Roles: []string{"user"}})
+	require.NoError(t, err)
+
+	otherSecret, err := New(&config.Config{
+		JWTAlgorithm:   "HS256",
+		JWTSecret:      "other",
+		JWTIssuer:      "issuer",
+		JWTAudience:    "audience",
+		AccessTokenTTL: 15 * time.Minute,
+	})
+	require.NoError(t, err)
+
+	otherSecretToken, err := otherSecret.IssueAccessToken(Identity{ID: "id", Email: "jd@example.com", Roles: []string{"user"}})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name        string
+		token       string
+		now         time.Time
+		expectedErr string
+	}{
+		{
+			name:  "parses valid token",
+			token: token,
+			now:   now.Add(time.Minute),
+		},
+		{
+			name:        "rejects expired token",
+			token:       token,
+			now:         now.Add(time.Hour),
+			expectedErr: errTokenExpired,
+		},
+		{
+			name:        "rejects token with another issuer",
+			token:       otherIssuerToken,
+			now:         now,
+			expectedErr: errTokenIssuer,
+		},
+		{
+			name:        "rejects token with another audience",
+			token:       otherAudienceToken,
+			now:         now,
+			expectedErr: errTokenAudience,
+		},
+		{
+			name:        "rejects token with invalid signature",
+			token:       otherSecretToken,
+			now:         now,
+			expectedErr: errParseToken,
+		},
+		{
+			name:        "rejects malformed token",
+			token:       "not-a-token",
+			now:         now,
+			expectedErr: errParseToken,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := New(cfg)
+			require.NoError(t, err)
+			m.now = func() time.Time { return tt.now }
+
+			got, err := m.ParseAccessToken(tt.token)
+
+			if tt.expectedErr != "" {
+				assert.Nil(t, got)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, "id", got.Subject)
+				assert.Equal(t, "jd@example.com", got.Email)
+			}
+		})
+	}
+}
+
+func TestTokenManager_MFAToken(t *testing.T) {
+	m, err := New(&config.Config{
+		JWTAlgorithm:    "HS256",
+		JWTSecret:       "secret",
+		JWTIssuer:       "issuer",
+		JWTAudience:     "audience",
+		AccessTokenTTL:  15 * time.Minute,
+		MFAChallengeTTL: 5 * time.Minute,
+	})
+	require.NoError(t, err)
+
+	token, err := m.IssueMFAToken("id")
+	require.NoError(t, err)
+
+	userID, err := m.ParseMFAToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "id", userID)
+
+	_, err = m.ParseAccessToken(token)
+	assert.ErrorContains(t, err, errTokenAudience)
+
+	accessToken, err := m.IssueAccessToken(Identity{ID: "id"})
+	require.NoError(t, err)
+
+	_, err = m.ParseMFAToken(accessToken)
+	assert.ErrorContains(t, err, errTokenAudience)
+
+	m.now = func() time.Time { return time.Now().Add(10 * time.Minute) }
+	_, err = m.ParseMFAToken(token)
+	assert.ErrorContains(t, err, errTokenExpired)
+}
+
+func TestClaims_HasRole(t *testing.T) {
+	claims := &Claims{Roles: []string{"manager", "user"}}
+
+	assert.True(t, claims.HasRole("manager"))
+	assert.True(t, claims.HasRole("user"))
+	assert.False(t, claims.HasRole("admin"))
+	assert.False(t, (&Claims{}).HasRole("user"))
+}
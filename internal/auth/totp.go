@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // RFC 6238 authenticator apps default to HMAC-SHA1
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/danielMensah/user-management/internal/config"
+)
+
+const (
+	totpSecretBytes   = 20
+	totpDigits        = 6
+	totpPeriod        = 30 * time.Second
+	totpSkew          = 1
+	recoveryCodeBytes = 10
+	recoveryCodeCount = 10
+
+	errEncryptionKey   = "mfa encryption key must be 32 base64 encoded bytes"
+	errEncryptSecret   = "failed to encrypt secret"
+	errDecryptSecret   = "failed to decrypt secret"
+	errGenerateSecret  = "failed to generate secret"
+	errCiphertextShort = "ciphertext too short"
+)
+
+// TOTP generates and validates RFC 6238 time-based one-time passwords. Secrets are only handed out
+// encrypted with AES-GCM so that they can be stored as they are.
+type TOTP struct {
+	issuer string
+	aead   cipher.AEAD
+	now    func() time.Time
+}
+
+// Enrollment represents a new TOTP secret
+type Enrollment struct {
+	Secret          string
+	EncryptedSecret string
+	URI             string
+}
+
+// NewTOTP creates a new TOTP manager from the service configuration
+func NewTOTP(cfg *config.Config) (*TOTP, error) {
+	key, err := base64.StdEncoding.DecodeString(cfg.MFAEncryptionKey)
+	if err != nil || len(key) != 32 {
+		return nil, errors.New(errEncryptionKey)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errEncryptionKey, err)
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errEncryptionKey, err)
+	}
+
+	return &TOTP{issuer: cfg.MFAIssuer, aead: aead, now: time.Now}, nil
+}
+
+// Enroll generates a new secret for the given account along with the otpauth:// URI to share it with
+// authenticator apps
+func (t *TOTP) Enroll(account string) (*Enrollment, error) {
+	b := make([]byte, totpSecretBytes)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("%s: %w", errGenerateSecret, err)
+	}
+
+	secret := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b)
+
+	encrypted, err := t.encrypt(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Enrollment{Secret: secret, EncryptedSecret: encrypted, URI: t.uri(account, secret)}, nil
+}
+
+// Validate checks a code against an encrypted secret, allowing for one period of clock skew. It returns
+// the time step the code belongs to so that callers can refuse codes that were already used.
+func (t *TOTP) Validate(encryptedSecret, code string) (int64, bool, error) {
+	secret, err := t.decrypt(encryptedSecret)
+	if err != nil {
+		return 0, false, err
+	}
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		return 0, false, fmt.Errorf("%s: %w", errDecryptSecret, err)
+	}
+
+	current := t.now().Unix() / int64(totpPeriod.Seconds())
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if subtle.ConstantTimeCompare([]byte(generateTOTP(key, step)), []byte(code)) == 1 {
+			return step, true, nil
+		}
+	}
+
+	return 0, false, nil
+}
+
+// NewRecoveryCodes generates single-use recovery codes allowing users to log in without their authenticator
+func NewRecoveryCodes() ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(b); err != nil {
+			return nil, fmt.Errorf("%s: %w", errGenerateToken, err)
+		}
+
+		code := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b))
+		codes = append(codes, code[:8]+"-"+code[8:])
+	}
+
+	return codes, nil
+}
+
+func (t *TOTP) uri(account, secret string) string {
+	label := url.PathEscape(t.issuer + ":" + account)
+	params := url.Values{
+		"secret":    {secret},
+		"issuer":    {t.issuer},
+		"algorithm": {"SHA1"},
+		"digits":    {fmt.Sprint(totpDigits)},
+		"period":    {fmt.Sprint(int(totpPeriod.Seconds()))},
+	}
+
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, params.Encode())
+}
+
+func (t *TOTP) encrypt(plaintext string) (string, error) {
+	nonce := make([]byte, t.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("%s: %w", errEncryptSecret, err)
+	}
+
+	sealed := t.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (t *TOTP) decrypt(ciphertext string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", errDecryptSecret, err)
+	}
+
+	size := t.aead.NonceSize()
+	if len(sealed) < size {
+		return "", fmt.Errorf("%s: %s", errDecryptSecret, errCiphertextShort)
+	}
+
+	plaintext, err := t.aead.Open(nil, sealed[:size], sealed[size:], nil)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", errDecryptSecret, err)
+	}
+
+	return string(plaintext), nil
+}
+
+// generateTOTP computes the code of the given time step as described by RFC 4226 and RFC 6238
+func generateTOTP(key []byte, step int64) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
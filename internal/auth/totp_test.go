@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"encoding/base32"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielMensah/user-management/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMFAKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+
+func TestNewTOTP(t *testing.T) {
+	tests := []struct {
+		name        string
+		key         string
+		expectedErr string
+	}{
+		{
+			name: "creates totp manager",
+			key:  testMFAKey,
+		},
+		{
+			name:        "rejects key that is not base64",
+			key:         "not base64",
+			expectedErr: errEncryptionKey,
+		},
+		{
+			name:        "rejects key of the wrong size",
+			key:         "c2hvcnQ=",
+			expectedErr: errEncryptionKey,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewTOTP(&config.Config{MFAEncryptionKey: tt.key})
+
+			if tt.expectedErr != "" {
+				assert.Nil(t, got)
+				assert.ErrorContains(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, got)
+			}
+		})
+	}
+}
+
+func TestTOTP_Enroll(t *testing.T) {
+	totp, err := NewTOTP(&config.Config{MFAEncryptionKey: testMFAKey, MFAIssuer: "user-management"})
+	require.NoError(t, err)
+
+	got, err := totp.Enroll("jd@mensah.com")
+	require.NoError(t, err)
+
+	assert.NotContains(t, got.EncryptedSecret, got.Secret)
+
+	secret, err := totp.decrypt(got.EncryptedSecret)
+	require.NoError(t, err)
+	assert.Equal(t, got.Secret, secret)
+
+	uri, err := url.Parse(got.URI)
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/user-management:jd@mensah.com", uri.Path)
+	assert.Equal(t, got.Secret, uri.Query().Get("secret"))
+	assert.Equal(t, "user-management", uri.Query().Get("issuer"))
+}
+
+func TestTOTP_Validate(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+
+	totp, err := NewTOTP(&config.Config{MFAEncryptionKey: testMFAKey})
+	require.NoError(t, err)
+	totp.now = func() time.Time { return now }
+
+	key := []byte("12345678901234567890")
+	encrypted, err := totp.encrypt(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(key))
+	require.NoError(t, err)
+
+	step := now.Unix() / 30
+
+	tests := []struct {
+		name          string
+		secret        string
+		code          string
+		expectedStep  int64
+		expectedValid bool
+		expectedErr   string
+	}{
+		{
+			name:          "accepts code of the current step",
+			secret:        encrypted,
+			code:          "081804",
+			expectedStep:  step,
+			expectedValid: true,
+		},
+		{
+			name:          "accepts code of the previous step",
+			secret:        encrypted,
+			code:          generateTOTP(key, step-1),
+			expectedStep:  step - 1,
+			expectedValid: true,
+		},
+		{
+			name:   "rejects code outside of the allowed skew",
+			secret: encrypted,
+			code:   generateTOTP(key, step+2),
+		},
+		{
+			name:   "rejects wrong code",
+			secret: encrypted,
+			code:   "000000",
+		},
+		{
+			name:        "fails to decrypt tampered secret",
+			secret:      "dGFtcGVyZWQgc2VjcmV0IHRoYXQgaXMgbG9uZyBlbm91Z2g=",
+			code:        "081804",
+			expectedErr: errDecryptSecret,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotStep, gotValid, err := totp.Validate(tt.secret, tt.code)
+
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectedStep, gotStep)
+			assert.Equal(t, tt.expectedValid, gotValid)
+		})
+	}
+}
+
+func TestNewRecoveryCodes(t *testing.T) {
+	codes, err := NewRecoveryCodes()
+	require.NoError(t, err)
+
+	assert.Len(t, codes, recoveryCodeCount)
+	for _, code := range codes {
+		assert.Len(t, code, 17)
+		assert.Equal(t, strings.ToLower(code), code)
+	}
+	assert.NotEqual(t, codes[0], codes[1])
+}
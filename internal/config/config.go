@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/mitchellh/mapstructure"
@@ -13,6 +14,60 @@ type Config struct {
 	APIPort  string `mapstructure:"API_PORT"`
 	MongoURI string `mapstructure:"API_MONGO_URI" validate:"required"`
 	MongoDB  string `mapstructure:"API_MONGO_DB_NAME" validate:"required"`
+
+	UniqueNicknames bool   `mapstructure:"API_UNIQUE_NICKNAMES"`
+	CursorSecret    string `mapstructure:"API_CURSOR_SECRET" validate:"required,min=32"`
+	RequireIfMatch  bool   `mapstructure:"API_REQUIRE_IF_MATCH"`
+
+	DeletedUserRetention time.Duration `mapstructure:"API_DELETED_USER_RETENTION" validate:"gt=0"`
+	PurgeInterval        time.Duration `mapstructure:"API_PURGE_INTERVAL" validate:"gt=0"`
+
+	JWTAlgorithm      string        `mapstructure:"API_JWT_ALGORITHM" validate:"oneof=HS256 RS256"`
+	JWTSecret         string        `mapstructure:"API_JWT_SECRET" validate:"required_if=JWTAlgorithm HS256,omitempty,min=32"`
+	JWTPrivateKeyFile string        `mapstructure:"API_JWT_PRIVATE_KEY_FILE" validate:"required_if=JWTAlgorithm RS256"`
+	JWTPublicKeyFile  string        `mapstructure:"API_JWT_PUBLIC_KEY_FILE" validate:"required_if=JWTAlgorithm RS256"`
+	JWTIssuer         string        `mapstructure:"API_JWT_ISSUER"`
+	JWTAudience       string        `mapstructure:"API_JWT_AUDIENCE"`
+	AccessTokenTTL    time.Duration `mapstructure:"API_ACCESS_TOKEN_TTL" validate:"gt=0"`
+	RefreshTokenTTL   time.Duration `mapstructure:"API_REFRESH_TOKEN_TTL" validate:"gt=0"`
+
+	MailDriver   string `mapstructure:"API_MAIL_DRIVER" validate:"oneof=smtp file"`
+	MailFrom     string `mapstructure:"API_MAIL_FROM" validate:"required"`
+	MailDir      string `mapstructure:"API_MAIL_DIR" validate:"required_if=MailDriver file"`
+	SMTPHost     string `mapstructure:"API_SMTP_HOST" validate:"required_if=MailDriver smtp"`
+	SMTPPort     string `mapstructure:"API_SMTP_PORT"`
+	SMTPUsername string `mapstructure:"API_SMTP_USERNAME"`
+	SMTPPassword string `mapstructure:"API_SMTP_PASSWORD"`
+
+	PasswordResetTTL time.Duration `mapstructure:"API_PASSWORD_RESET_TTL" validate:"gt=0"`
+	PasswordResetURL string        `mapstructure:"API_PASSWORD_RESET_URL"`
+
+	PasswordHashAlgorithm string `mapstructure:"API_PASSWORD_HASH_ALGORITHM" validate:"oneof=bcrypt argon2id"`
+	BcryptCost            int    `mapstructure:"API_BCRYPT_COST" validate:"min=4,max=31"`
+	Argon2Memory          uint32 `mapstructure:"API_ARGON2_MEMORY" validate:"gt=0,max=1048576"`
+	Argon2Iterations      uint32 `mapstructure:"API_ARGON2_ITERATIONS" validate:"gt=0,max=16"`
+	Argon2Parallelism     uint8  `mapstructure:"API_ARGON2_PARALLELISM" validate:"gt=0"`
+
+	PasswordMinLength           int    `mapstructure:"API_PASSWORD_MIN_LENGTH" validate:"gt=0"`
+	PasswordMaxLength           int    `mapstructure:"API_PASSWORD_MAX_LENGTH" validate:"gtefield=PasswordMinLength"`
+	PasswordMinCharacterClasses int    `mapstructure:"API_PASSWORD_MIN_CHARACTER_CLASSES" validate:"min=0,max=4"`
+	BreachedPasswordsDir        string `mapstructure:"API_BREACHED_PASSWORDS_DIR"`
+
+	RequireVerifiedEmail bool          `mapstructure:"API_REQUIRE_VERIFIED_EMAIL"`
+	EmailVerificationTTL time.Duration `mapstructure:"API_EMAIL_VERIFICATION_TTL" validate:"gt=0"`
+	EmailVerificationURL string        `mapstructure:"API_EMAIL_VERIFICATION_URL"`
+
+	MFAEncryptionKey string        `mapstructure:"API_MFA_ENCRYPTION_KEY" validate:"required,base64"`
+	MFAIssuer        string        `mapstructure:"API_MFA_ISSUER"`
+	MFAChallengeTTL  time.Duration `mapstructure:"API_MFA_CHALLENGE_TTL" validate:"gt=0"`
+
+	LoginMaxAttempts        int           `mapstructure:"API_LOGIN_MAX_ATTEMPTS" validate:"gt=0"`
+	LoginAttemptWindow      time.Duration `mapstructure:"API_LOGIN_ATTEMPT_WINDOW" validate:"gt=0"`
+	LoginLockoutDuration    time.Duration `mapstructure:"API_LOGIN_LOCKOUT_DURATION" validate:"gt=0"`
+	LoginMaxLockoutDuration time.Duration `mapstructure:"API_LOGIN_MAX_LOCKOUT_DURATION" validate:"gtefield=LoginLockoutDuration"`
+	LoginRateLimit          int           `mapstructure:"API_LOGIN_RATE_LIMIT" validate:"gt=0"`
+	LoginRateBurst          int           `mapstructure:"API_LOGIN_RATE_BURST" validate:"gt=0"`
+	TrustedProxies          []string      `mapstructure:"API_TRUSTED_PROXIES" validate:"dive,cidr"`
 }
 
 func New() (*Config, error) {
@@ -24,6 +79,38 @@ func New() (*Config, error) {
 
 	v.SetDefault("API_HOST", "0.0.0.0")
 	v.SetDefault("API_PORT", "8000")
+	v.SetDefault("API_UNIQUE_NICKNAMES", false)
+	v.SetDefault("API_REQUIRE_IF_MATCH", false)
+	v.SetDefault("API_DELETED_USER_RETENTION", "720h")
+	v.SetDefault("API_PURGE_INTERVAL", "1h")
+	v.SetDefault("API_JWT_ALGORITHM", "HS256")
+	v.SetDefault("API_JWT_ISSUER", "user-management")
+	v.SetDefault("API_JWT_AUDIENCE", "user-management")
+	v.SetDefault("API_ACCESS_TOKEN_TTL", "15m")
+	v.SetDefault("API_REFRESH_TOKEN_TTL", "720h")
+	v.SetDefault("API_MAIL_DRIVER", "smtp")
+	v.SetDefault("API_MAIL_FROM", "no-reply@user-management.local")
+	v.SetDefault("API_MAIL_DIR", "mail")
+	v.SetDefault("API_SMTP_PORT", "587")
+	v.SetDefault("API_PASSWORD_RESET_TTL", "1h")
+	v.SetDefault("API_PASSWORD_HASH_ALGORITHM", "argon2id")
+	v.SetDefault("API_BCRYPT_COST", 12)
+	v.SetDefault("API_ARGON2_MEMORY", 64*1024)
+	v.SetDefault("API_ARGON2_ITERATIONS", 3)
+	v.SetDefault("API_ARGON2_PARALLELISM", 2)
+	v.SetDefault("API_PASSWORD_MIN_LENGTH", 12)
+	v.SetDefault("API_PASSWORD_MAX_LENGTH", 72)
+	v.SetDefault("API_PASSWORD_MIN_CHARACTER_CLASSES", 3)
+	v.SetDefault("API_REQUIRE_VERIFIED_EMAIL", false)
+	v.SetDefault("API_EMAIL_VERIFICATION_TTL", "24h")
+	v.SetDefault("API_MFA_ISSUER", "user-management")
+	v.SetDefault("API_MFA_CHALLENGE_TTL", "5m")
+	v.SetDefault("API_LOGIN_MAX_ATTEMPTS", 5)
+	v.SetDefault("API_LOGIN_ATTEMPT_WINDOW", "15m")
+	v.SetDefault("API_LOGIN_LOCKOUT_DURATION", "5m")
+	v.SetDefault("API_LOGIN_MAX_LOCKOUT_DURATION", "24h")
+	v.SetDefault("API_LOGIN_RATE_LIMIT", 20)
+	v.SetDefault("API_LOGIN_RATE_BURST", 5)
 
 	var config Config
 	if err := v.Unmarshal(&config); err != nil {
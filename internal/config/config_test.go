@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -15,6 +16,11 @@ func TestLoadConfig(t *testing.T) {
 		"API_MONGO_DB_NAME": "test",
 		"API_HOST":          "0.0.0.0",
 		"API_PORT":          "8000",
+		"API_JWT_SECRET":    "0123456789abcdef0123456789abcdef",
+
+		"API_CURSOR_SECRET":      "fedcba9876543210fedcba9876543210",
+		"API_MFA_ENCRYPTION_KEY": "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=",
+		"API_SMTP_HOST":          "smtp.example.com",
 	}
 
 	tests := []struct {
@@ -31,7 +37,136 @@ func TestLoadConfig(t *testing.T) {
 				MongoDB:  "test",
 				APIHost:  "0.0.0.0",
 				APIPort:  "8000",
+
+				CursorSecret: "fedcba9876543210fedcba9876543210",
+
+				DeletedUserRetention: 720 * time.Hour,
+				PurgeInterval:        time.Hour,
+
+				JWTAlgorithm:    "HS256",
+				JWTSecret:       "0123456789abcdef0123456789abcdef",
+				JWTIssuer:       "user-management",
+				JWTAudience:     "user-management",
+				AccessTokenTTL:  15 * time.Minute,
+				RefreshTokenTTL: 720 * time.Hour,
+
+				MailDriver: "smtp",
+				MailFrom:   "no-reply@user-management.local",
+				MailDir:    "mail",
+				SMTPHost:   "smtp.example.com",
+				SMTPPort:   "587",
+
+				PasswordResetTTL: time.Hour,
+
+				PasswordHashAlgorithm: "argon2id",
+				BcryptCost:            12,
+				Argon2Memory:          64 * 1024,
+				Argon2Iterations:      3,
+				Argon2Parallelism:     2,
+
+				PasswordMinLength:           12,
+				PasswordMaxLength:           72,
+				PasswordMinCharacterClasses: 3,
+
+				EmailVerificationTTL: 24 * time.Hour,
+
+				MFAEncryptionKey: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=",
+				MFAIssuer:        "user-management",
+				MFAChallengeTTL:  5 * time.Minute,
+
+				LoginMaxAttempts:        5,
+				LoginAttemptWindow:      15 * time.Minute,
+				LoginLockoutDuration:    5 * time.Minute,
+				LoginMaxLockoutDuration: 24 * time.Hour,
+				LoginRateLimit:          20,
+				LoginRateBurst:          5,
+			},
+		},
+		{
+			name: "Errors when the jwt algorithm is not supported",
+			envVars: map[string]string{
+				"API_MONGO_URI":     "mongodb://localhost:27017",
+				"API_MONGO_DB_NAME": "test",
+				"API_JWT_ALGORITHM": "none",
+			},
+			expectedErr: "JWTAlgorithm",
+		},
+		{
+			name: "Errors when rs256 keys are missing",
+			envVars: map[string]string{
+				"API_MONGO_URI":     "mongodb://localhost:27017",
+				"API_MONGO_DB_NAME": "test",
+				"API_JWT_ALGORITHM": "RS256",
+			},
+			expectedErr: "JWTPrivateKeyFile",
+		},
+		{
+			name: "Errors when the smtp host of the default mail driver is missing",
+			envVars: map[string]string{
+				"API_MONGO_URI":     "mongodb://localhost:27017",
+				"API_MONGO_DB_NAME": "test",
+				"API_JWT_SECRET":    "0123456789abcdef0123456789abcdef",
+			},
+			expectedErr: "SMTPHost",
+		},
+		{
+			name: "Errors when the bcrypt cost is too low",
+			envVars: map[string]string{
+				"API_MONGO_URI":     "mongodb://localhost:27017",
+				"API_MONGO_DB_NAME": "test",
+				"API_JWT_SECRET":    "0123456789abcdef0123456789abcdef",
+				"API_BCRYPT_COST":   "3",
+			},
+			expectedErr: "BcryptCost",
+		},
+		{
+			name: "Errors when the argon2 memory is too high",
+			envVars: map[string]string{
+				"API_MONGO_URI":     "mongodb://localhost:27017",
+				"API_MONGO_DB_NAME": "test",
+				"API_JWT_SECRET":    "0123456789abcdef0123456789abcdef",
+				"API_ARGON2_MEMORY": "2097152",
+			},
+			expectedErr: "Argon2Memory",
+		},
+		{
+			name: "Errors when the max lockout is shorter than the lockout",
+			envVars: map[string]string{
+				"API_MONGO_URI":                  "mongodb://localhost:27017",
+				"API_MONGO_DB_NAME":              "test",
+				"API_JWT_SECRET":                 "0123456789abcdef0123456789abcdef",
+				"API_LOGIN_MAX_LOCKOUT_DURATION": "1m",
+			},
+			expectedErr: "LoginMaxLockoutDuration",
+		},
+		{
+			name: "Errors when the jwt secret is too short",
+			envVars: map[string]string{
+				"API_MONGO_URI":     "mongodb://localhost:27017",
+				"API_MONGO_DB_NAME": "test",
+				"API_JWT_SECRET":    "secret",
+			},
+			expectedErr: "'JWTSecret' failed on the 'min' tag",
+		},
+		{
+			name: "Errors when the cursor secret is too short",
+			envVars: map[string]string{
+				"API_MONGO_URI":     "mongodb://localhost:27017",
+				"API_MONGO_DB_NAME": "test",
+				"API_JWT_SECRET":    "0123456789abcdef0123456789abcdef",
+				"API_CURSOR_SECRET": "cursor-secret",
+			},
+			expectedErr: "'CursorSecret' failed on the 'min' tag",
+		},
+		{
+			name: "Errors when a trusted proxy is not a cidr",
+			envVars: map[string]string{
+				"API_MONGO_URI":       "mongodb://localhost:27017",
+				"API_MONGO_DB_NAME":   "test",
+				"API_JWT_SECRET":      "0123456789abcdef0123456789abcdef",
+				"API_TRUSTED_PROXIES": "10.0.0.0/8,10.0.0.1",
 			},
+			expectedErr: "TrustedProxies",
 		},
 		{
 			name:        "Errors when an environment var is missing",
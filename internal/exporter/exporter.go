@@ -0,0 +1,248 @@
+package exporter
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+)
+
+// Media types users are exported in
+const (
+	MIMEJSON   = "application/json"
+	MIMENDJSON = "application/x-ndjson"
+	MIMECSV    = "text/csv"
+)
+
+// listSeparator separates the values of a list in a CSV cell, such as the roles of a user
+const listSeparator = ";"
+
+// mediaTypes are the supported media types, the first one being picked when an Accept header allows several of them
+// equally
+var mediaTypes = []string{MIMEJSON, MIMENDJSON, MIMECSV}
+
+// ErrNotAcceptable is returned when an Accept header allows none of the supported media types
+var ErrNotAcceptable = errors.New("no acceptable export media type")
+
+// Negotiate returns the supported media type an Accept header prefers, JSON when the header is empty
+func Negotiate(accept string) (string, error) {
+	if strings.TrimSpace(accept) == "" {
+		return MIMEJSON, nil
+	}
+
+	best, bestQuality := "", 0.0
+	for _, accepted := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+
+		quality := 1.0
+		if value, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(value, 64); err != nil {
+				continue
+			}
+		}
+
+		for _, supported := range mediaTypes {
+			if quality > bestQuality && matches(mediaType, supported) {
+				best, bestQuality = supported, quality
+			}
+		}
+	}
+
+	if best == "" {
+		return "", fmt.Errorf("%w: %s", ErrNotAcceptable, accept)
+	}
+
+	return best, nil
+}
+
+// matches reports whether an accepted media type, which can be a range such as text/*, matches a supported one
+func matches(accepted, supported string) bool {
+	if accepted == "*/*" || accepted == supported {
+		return true
+	}
+
+	return strings.HasSuffix(accepted, "/*") && strings.HasPrefix(supported, strings.TrimSuffix(accepted, "*"))
+}
+
+// Writer writes exported users one at a time, each given as a value encoded to a JSON object
+type Writer interface {
+	Write(user interface{}) error
+	// Flush writes the users buffered by the writer
+	Flush() error
+	// Close ends the export once every user is written
+	Close() error
+}
+
+// NewWriter returns the writer of users in the given media type. JSON exports are an array of users, NDJSON exports a
+// user per line and CSV exports start with a header naming the given columns, then a user per row.
+func NewWriter(mediaType string, w io.Writer, columns []string) (Writer, error) {
+	switch mediaType {
+	case MIMEJSON:
+		return &jsonWriter{w: w}, nil
+	case MIMENDJSON:
+		return &ndjsonWriter{w: w}, nil
+	case MIMECSV:
+		return &csvWriter{w: csv.NewWriter(w), columns: columns}, nil
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrNotAcceptable, mediaType)
+	}
+}
+
+// jsonWriter writes users as the items of a JSON array
+type jsonWriter struct {
+	w       io.Writer
+	written bool
+}
+
+func (j *jsonWriter) Write(user interface{}) error {
+	data, err := json.Marshal(user)
+	if err != nil {
+		return err
+	}
+
+	separator := ","
+	if !j.written {
+		separator = "["
+		j.written = true
+	}
+
+	_, err = io.WriteString(j.w, separator+string(data))
+
+	return err
+}
+
+func (j *jsonWriter) Flush() error {
+	return nil
+}
+
+func (j *jsonWriter) Close() error {
+	closing := "]\n"
+	if !j.written {
+		closing = "[]\n"
+	}
+
+	_, err := io.WriteString(j.w, closing)
+
+	return err
+}
+
+// ndjsonWriter writes users as JSON objects, one per line
+type ndjsonWriter struct {
+	w io.Writer
+}
+
+func (n *ndjsonWriter) Write(user interface{}) error {
+	return json.NewEncoder(n.w).Encode(user)
+}
+
+func (n *ndjsonWriter) Flush() error {
+	return nil
+}
+
+func (n *ndjsonWriter) Close() error {
+	return nil
+}
+
+// csvWriter writes users as CSV rows with a cell per column, writing the header before the first row
+type csvWriter struct {
+	w             *csv.Writer
+	columns       []string
+	headerWritten bool
+}
+
+func (c *csvWriter) Write(user interface{}) error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	fields, err := jsonFields(user)
+	if err != nil {
+		return err
+	}
+
+	record := make([]string, len(c.columns))
+	for i, column := range c.columns {
+		if record[i], err = csvCell(fields[column]); err != nil {
+			return err
+		}
+	}
+
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) writeHeader() error {
+	if c.headerWritten {
+		return nil
+	}
+	c.headerWritten = true
+
+	return c.w.Write(c.columns)
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	if err := c.writeHeader(); err != nil {
+		return err
+	}
+
+	return c.Flush()
+}
+
+// jsonFields returns the fields of a value encoded to a JSON object, keeping numbers as they are written
+func jsonFields(value interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	fields := map[string]interface{}{}
+	if err = decoder.Decode(&fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
+
+// csvCell returns the CSV cell of a JSON value. Missing and null values are empty, lists have their values separated
+// by semicolons and objects stay JSON.
+func csvCell(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case []interface{}:
+		cells := make([]string, 0, len(v))
+		for _, item := range v {
+			cell, err := csvCell(item)
+			if err != nil {
+				return "", err
+			}
+			cells = append(cells, cell)
+		}
+		return strings.Join(cells, listSeparator), nil
+	default:
+		data, err := json.Marshal(v)
+		return string(data), err
+	}
+}
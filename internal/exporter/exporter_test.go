@@ -0,0 +1,133 @@
+package exporter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name        string
+		accept      string
+		expected    string
+		expectedErr error
+	}{
+		{
+			name:     "no accept header",
+			expected: MIMEJSON,
+		},
+		{
+			name:     "any media type",
+			accept:   "*/*",
+			expected: MIMEJSON,
+		},
+		{
+			name:     "csv with parameters",
+			accept:   "text/csv; charset=utf-8",
+			expected: MIMECSV,
+		},
+		{
+			name:     "preferred media type",
+			accept:   "application/json;q=0.5, application/x-ndjson, */*;q=0.1",
+			expected: MIMENDJSON,
+		},
+		{
+			name:     "media type range",
+			accept:   "text/html, text/*;q=0.8",
+			expected: MIMECSV,
+		},
+		{
+			name:        "unsupported media types",
+			accept:      "application/xml, text/csv;q=0",
+			expectedErr: ErrNotAcceptable,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mediaType, err := Negotiate(tt.accept)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, mediaType)
+		})
+	}
+}
+
+func TestNewWriter(t *testing.T) {
+	users := []interface{}{
+		map[string]interface{}{
+			"_id":            "630625a6c8dc8c2e3ec5f0a1",
+			"nickname":       "jd, the first",
+			"roles":          []string{"admin", "user"},
+			"email_verified": true,
+			"version":        int64(12345678),
+		},
+		map[string]interface{}{"_id": "630625a6c8dc8c2e3ec5f0a2", "deleted_at": nil},
+	}
+
+	tests := []struct {
+		name      string
+		mediaType string
+		users     []interface{}
+		expected  string
+	}{
+		{
+			name:      "json array",
+			mediaType: MIMEJSON,
+			users:     users,
+			expected: `[{"_id":"630625a6c8dc8c2e3ec5f0a1","email_verified":true,"nickname":"jd, the first",` +
+				`"roles":["admin","user"],"version":12345678},{"_id":"630625a6c8dc8c2e3ec5f0a2","deleted_at":null}]` + "\n",
+		},
+		{
+			name:      "empty json array",
+			mediaType: MIMEJSON,
+			expected:  "[]\n",
+		},
+		{
+			name:      "ndjson lines",
+			mediaType: MIMENDJSON,
+			users:     users,
+			expected: `{"_id":"630625a6c8dc8c2e3ec5f0a1","email_verified":true,"nickname":"jd, the first",` +
+				`"roles":["admin","user"],"version":12345678}` + "\n" +
+				`{"_id":"630625a6c8dc8c2e3ec5f0a2","deleted_at":null}` + "\n",
+		},
+		{
+			name:      "csv rows",
+			mediaType: MIMECSV,
+			users:     users,
+			expected: "_id,nickname,roles,email_verified,version,deleted_at\n" +
+				"630625a6c8dc8c2e3ec5f0a1,\"jd, the first\",admin;user,true,12345678,\n" +
+				"630625a6c8dc8c2e3ec5f0a2,,,,,\n",
+		},
+		{
+			name:      "csv header only",
+			mediaType: MIMECSV,
+			expected:  "_id,nickname,roles,email_verified,version,deleted_at\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			columns := []string{"_id", "nickname", "roles", "email_verified", "version", "deleted_at"}
+
+			writer, err := NewWriter(tt.mediaType, &out, columns)
+			require.NoError(t, err)
+
+			for _, user := range tt.users {
+				require.NoError(t, writer.Write(user))
+			}
+			require.NoError(t, writer.Close())
+
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	_, err := NewWriter("application/xml", &strings.Builder{}, nil)
+	assert.ErrorIs(t, err, ErrNotAcceptable)
+}
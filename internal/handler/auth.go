@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/auth"
+	"github.com/danielMensah/user-management/internal/repository"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	errLogin               = "failed to log in"
+	errInvalidCredentials  = "invalid email or password"
+	errIssueToken          = "failed to issue token"
+	errRefreshToken        = "failed to refresh token"
+	errInvalidRefreshToken = "invalid refresh token"
+	errRefreshTokenReused  = "refresh token reuse detected"
+	errLogout              = "failed to log out"
+	errRehashPassword      = "failed to rehash password"
+)
+
+// Login authenticates a user with their email and password and issues an access and refresh token. Users with
+// mfa enabled are given a challenge token instead and users failing too many times are temporarily locked out.
+func (h *Handler) Login(ctx echo.Context) error {
+	body := new(api.LoginRequest)
+	if err := ctx.Bind(body); err != nil {
+		logrus.WithError(err).Error(errParseBody)
+		return renderProblem(ctx, http.StatusBadRequest, errParseBody)
+	}
+
+	creds, err := h.repo.GetCredentialsByEmail(ctx.Request().Context(), string(body.Email))
+	if err != nil {
+		if !errors.Is(err, repository.ErrNotFound) {
+			return renderServerError(ctx, err, errLogin)
+		}
+
+		// hash the password anyway so that failed logins take the same time whether or not the email exists
+		_, _ = h.passwords.Hash(body.Password)
+		return renderProblem(ctx, http.StatusUnauthorized, errInvalidCredentials)
+	}
+
+	if until, locked := lockedUntil(creds); locked {
+		return accountLocked(ctx, until)
+	}
+
+	valid, err := h.passwords.Verify(creds.Password, body.Password)
+	if err != nil {
+		return renderServerError(ctx, err, errLogin)
+	}
+	if !valid {
+		return h.failedLogin(ctx, creds, errInvalidCredentials)
+	}
+
+	h.rehashPassword(ctx.Request().Context(), creds, body.Password)
+
+	if h.cfg.RequireVerifiedEmail && !creds.EmailVerified {
+		return renderProblem(ctx, http.StatusForbidden, errEmailNotVerified)
+	}
+
+	if creds.MFA.Enabled {
+		return h.mfaChallenge(ctx, creds)
+	}
+
+	tokens, err := h.issueTokens(ctx.Request().Context(), creds, "")
+	if err != nil {
+		return renderServerError(ctx, err, errIssueToken)
+	}
+
+	h.resetFailedLogins(ctx.Request().Context(), creds)
+
+	return ctx.JSON(http.StatusOK, tokens)
+}
+
+// RefreshToken exchanges a refresh token for a new access and refresh token. Presenting a refresh token
+// that was already used revokes every token of its family.
+func (h *Handler) RefreshToken(ctx echo.Context) error {
+	body := new(api.RefreshTokenRequest)
+	if err := ctx.Bind(body); err != nil {
+		logrus.WithError(err).Error(errParseBody)
+		return renderProblem(ctx, http.StatusBadRequest, errParseBody)
+	}
+
+	reqCtx := ctx.Request().Context()
+
+	previous, err := h.repo.UseRefreshToken(reqCtx, auth.HashOpaqueToken(body.RefreshToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return renderProblem(ctx, http.StatusUnauthorized, errInvalidRefreshToken)
+		}
+
+		return renderServerError(ctx, err, errRefreshToken)
+	}
+
+	if previous.UsedAt != nil || previous.RevokedAt != nil {
+		logrus.WithField("family_id", previous.FamilyID).WithField("user_id", previous.UserID).Warn(errRefreshTokenReused)
+
+		if err = h.repo.RevokeRefreshTokenFamily(reqCtx, previous.FamilyID); err != nil {
+			return renderServerError(ctx, err, errRefreshToken)
+		}
+
+		return renderProblem(ctx, http.StatusUnauthorized, errInvalidRefreshToken)
+	}
+
+	if time.Now().After(previous.ExpiresAt) {
+		return renderProblem(ctx, http.StatusUnauthorized, errInvalidRefreshToken)
+	}
+
+	creds, err := h.repo.GetCredentialsByID(reqCtx, previous.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return renderProblem(ctx, http.StatusUnauthorized, errInvalidRefreshToken)
+		}
+
+		return renderServerError(ctx, err, errRefreshToken)
+	}
+
+	tokens, err := h.issueTokens(reqCtx, creds, previous.FamilyID)
+	if err != nil {
+		return renderServerError(ctx, err, errIssueToken)
+	}
+
+	return ctx.JSON(http.StatusOK, tokens)
+}
+
+// Logout revokes a refresh token and every other token of its family
+func (h *Handler) Logout(ctx echo.Context) error {
+	body := new(api.RefreshTokenRequest)
+	if err := ctx.Bind(body); err != nil {
+		logrus.WithError(err).Error(errParseBody)
+		return renderProblem(ctx, http.StatusBadRequest, errParseBody)
+	}
+
+	reqCtx := ctx.Request().Context()
+
+	token, err := h.repo.GetRefreshToken(reqCtx, auth.HashOpaqueToken(body.RefreshToken))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ctx.NoContent(http.StatusNoContent)
+		}
+
+		return renderServerError(ctx, err, errLogout)
+	}
+
+	if err = h.repo.RevokeRefreshTokenFamily(reqCtx, token.FamilyID); err != nil {
+		return renderServerError(ctx, err, errLogout)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// rehashPassword replaces the password hash of a user when it was produced with outdated parameters. Failures are
+// logged rather than returned as they must not prevent the login.
+func (h *Handler) rehashPassword(ctx context.Context, creds *repository.Credentials, password string) {
+	if !h.passwords.NeedsRehash(creds.Password) {
+		return
+	}
+
+	hash, err := h.passwords.Hash(password)
+	if err == nil {
+		err = h.repo.RehashPassword(ctx, creds.ID, creds.Password, hash)
+	}
+
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		logrus.WithError(err).WithField("user_id", creds.ID).Error(errRehashPassword)
+	}
+}
+
+// issueTokens issues an access token and a refresh token belonging to the given family, or to a new family
+// when none is given
+func (h *Handler) issueTokens(ctx context.Context, creds *repository.Credentials, familyID string) (*api.TokenResponse, error) {
+	accessToken, err := h.tokens.IssueAccessToken(auth.Identity{
+		ID:            creds.ID,
+		Email:         creds.Email,
+		EmailVerified: creds.EmailVerified,
+		Country:       creds.Country,
+		Roles:         rolesToStrings(creds.Roles),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	err = h.repo.CreateRefreshToken(ctx, &repository.RefreshToken{
+		Hash:      auth.HashOpaqueToken(refreshToken),
+		FamilyID:  familyID,
+		UserID:    creds.ID,
+		ExpiresAt: time.Now().UTC().Add(h.tokens.RefreshTTL()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    h.tokens.TokenType(),
+		ExpiresIn:    int64(h.tokens.AccessTTL().Seconds()),
+	}, nil
+}
@@ -0,0 +1,447 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/auth"
+	"github.com/danielMensah/user-management/internal/config"
+	mongoRepo "github.com/danielMensah/user-management/internal/repository/mongo"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestHandler_Login(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	hash, err := testPasswords.Hash("password")
+	require.NoError(t, err)
+
+	credentials := bson.D{
+		{"_id", hexID},
+		{"email", "jd@jd@mensah.com.com"},
+		{"password", hash},
+	}
+
+	outdatedHash, err := auth.NewBcryptHasher(bcrypt.MinCost + 1).Hash("password")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name             string
+		body             string
+		mockResponses    []bson.D
+		requireVerified  bool
+		expectedStatus   int
+		expectedErr      api.Error
+		expectedCommands []string
+	}{
+		{
+			name: "can log in",
+			body: `{"email":"jd@jd@mensah.com.com","password":"password"}`,
+			mockResponses: []bson.D{
+				mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, credentials),
+				{{"ok", 1}},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "rehashes outdated password hash",
+			body: `{"email":"jd@jd@mensah.com.com","password":"password"}`,
+			mockResponses: []bson.D{
+				mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{
+					{"_id", hexID},
+					{"email", "jd@jd@mensah.com.com"},
+					{"password", outdatedHash},
+				}),
+				{{"ok", 1}, {"n", 1}, {"nModified", 1}},
+				{{"ok", 1}},
+			},
+			expectedStatus:   http.StatusOK,
+			expectedCommands: []string{"find", "update", "insert"},
+		},
+		{
+			name: "can log in with verified email",
+			body: `{"email":"jd@jd@mensah.com.com","password":"password"}`,
+			mockResponses: []bson.D{
+				mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, append(credentials, bson.E{Key: "email_verified", Value: true})),
+				{{"ok", 1}},
+			},
+			requireVerified: true,
+			expectedStatus:  http.StatusOK,
+		},
+		{
+			name: "unverified email",
+			body: `{"email":"jd@jd@mensah.com.com","password":"password"}`,
+			mockResponses: []bson.D{
+				mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, credentials),
+			},
+			requireVerified: true,
+			expectedStatus:  http.StatusForbidden,
+			expectedErr: api.Error{
+				Detail: errEmailNotVerified,
+			},
+		},
+		{
+			name:           "invalid request",
+			body:           `{"`,
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errParseBody,
+			},
+		},
+		{
+			name: "wrong password",
+			body: `{"email":"jd@jd@mensah.com.com","password":"wrong"}`,
+			mockResponses: []bson.D{
+				mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, credentials),
+				failedAttempts(1),
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedErr: api.Error{
+				Detail: errInvalidCredentials,
+			},
+		},
+		{
+			name: "too many wrong passwords lock the account",
+			body: `{"email":"jd@jd@mensah.com.com","password":"wrong"}`,
+			mockResponses: []bson.D{
+				mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, credentials),
+				failedAttempts(3),
+				{{"ok", 1}, {"n", 1}, {"nModified", 1}},
+			},
+			expectedStatus: http.StatusLocked,
+			expectedErr:    problemWithCode(http.StatusLocked, errAccountLocked, codeAccountLocked),
+		},
+		{
+			name: "locked account",
+			body: `{"email":"jd@jd@mensah.com.com","password":"password"}`,
+			mockResponses: []bson.D{
+				mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, append(credentials, bson.E{
+					Key: "lockout", Value: bson.D{{"locked_until", time.Now().Add(time.Minute)}},
+				})),
+			},
+			expectedStatus: http.StatusLocked,
+			expectedErr:    problemWithCode(http.StatusLocked, errAccountLocked, codeAccountLocked),
+		},
+		{
+			name: "can log in once the lockout expired",
+			body: `{"email":"jd@jd@mensah.com.com","password":"password"}`,
+			mockResponses: []bson.D{
+				mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, append(credentials, bson.E{
+					Key: "lockout", Value: bson.D{{"locked_until", time.Now().Add(-time.Minute)}, {"lockouts", 1}},
+				})),
+				{{"ok", 1}},
+				{{"ok", 1}, {"n", 1}, {"nModified", 1}},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "error recording failed login",
+			body: `{"email":"jd@jd@mensah.com.com","password":"wrong"}`,
+			mockResponses: []bson.D{
+				mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, credentials),
+				{{"ok", 0}},
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr: api.Error{
+				Detail: errRecordFailedLogin,
+			},
+		},
+		{
+			name: "unknown email",
+			body: `{"email":"unknown@mensah.com","password":"password"}`,
+			mockResponses: []bson.D{
+				mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch),
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedErr: api.Error{
+				Detail: errInvalidCredentials,
+			},
+		},
+		{
+			name:           "error finding user",
+			body:           `{"email":"jd@jd@mensah.com.com","password":"password"}`,
+			mockResponses:  []bson.D{{{"ok", 0}}},
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr: api.Error{
+				Detail: errLogin,
+			},
+		},
+		{
+			name: "error storing refresh token",
+			body: `{"email":"jd@jd@mensah.com.com","password":"password"}`,
+			mockResponses: []bson.D{
+				mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, credentials),
+				{{"ok", 0}},
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr: api.Error{
+				Detail: errIssueToken,
+			},
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponses...)
+
+			repo := mongoRepo.New(mt.DB)
+			cfg := newLockoutConfig()
+			cfg.RequireVerifiedEmail = tt.requireVerified
+			h := New(repo, newTokenManager(t), nil, testPasswords, newPasswordPolicy(t), newCursorCodec(t), nil, cfg)
+
+			ctx, response := setUpRequest(echo.POST, "/auth/login", tt.body)
+
+			err := h.Login(ctx)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+
+			if tt.expectedStatus == http.StatusLocked {
+				assert.NotEmpty(t, response.Header().Get("Retry-After"))
+			}
+
+			for _, command := range tt.expectedCommands {
+				assert.Equal(t, command, mt.GetStartedEvent().CommandName)
+			}
+
+			if tt.expectedErr.Detail != "" {
+				assertProblem(t, response, tt.expectedErr)
+			} else {
+				var responseBody api.TokenResponse
+				err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+				require.NoError(t, err)
+
+				assert.NotEmpty(t, responseBody.AccessToken)
+				assert.NotEmpty(t, responseBody.RefreshToken)
+				assert.Equal(t, "Bearer", responseBody.TokenType)
+				assert.Equal(t, int64(900), responseBody.ExpiresIn)
+			}
+		})
+	}
+}
+
+func failedAttempts(attempts int) bson.D {
+	return bson.D{{"ok", 1}, {"value", bson.D{{"_id", hexID}, {"lockout", bson.D{{"failed_attempts", attempts}}}}}}
+}
+
+func TestHandler_RefreshToken(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	body := `{"refresh_token":"token"}`
+	expiresAt := time.Now().UTC().Add(time.Hour)
+	usedAt := time.Now().UTC().Add(-time.Minute)
+
+	refreshToken := func(fields ...bson.E) bson.D {
+		return bson.D{
+			{"ok", 1},
+			{"value", append(bson.D{
+				{"_id", hexID},
+				{"hash", auth.HashOpaqueToken("token")},
+				{"family_id", "family"},
+				{"user_id", hexID},
+				{"created_at", createdAt},
+			}, fields...)},
+		}
+	}
+	credentials := mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{
+		{"_id", hexID},
+		{"email", "jd@jd@mensah.com.com"},
+		{"password", "hash"},
+	})
+
+	tests := []struct {
+		name           string
+		body           string
+		mockResponses  []bson.D
+		expectedStatus int
+		expectedErr    api.Error
+	}{
+		{
+			name: "can refresh token",
+			body: body,
+			mockResponses: []bson.D{
+				refreshToken(bson.E{Key: "expires_at", Value: expiresAt}),
+				credentials,
+				{{"ok", 1}},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "invalid request",
+			body:           `{"`,
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errParseBody,
+			},
+		},
+		{
+			name:           "unknown refresh token",
+			body:           body,
+			mockResponses:  []bson.D{{{"ok", 1}, {"value", nil}}},
+			expectedStatus: http.StatusUnauthorized,
+			expectedErr: api.Error{
+				Detail: errInvalidRefreshToken,
+			},
+		},
+		{
+			name: "reused refresh token revokes family",
+			body: body,
+			mockResponses: []bson.D{
+				refreshToken(bson.E{Key: "expires_at", Value: expiresAt}, bson.E{Key: "used_at", Value: usedAt}),
+				{{"ok", 1}, {"n", 2}, {"nModified", 2}},
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedErr: api.Error{
+				Detail: errInvalidRefreshToken,
+			},
+		},
+		{
+			name: "expired refresh token",
+			body: body,
+			mockResponses: []bson.D{
+				refreshToken(bson.E{Key: "expires_at", Value: usedAt}),
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedErr: api.Error{
+				Detail: errInvalidRefreshToken,
+			},
+		},
+		{
+			name: "user no longer exists",
+			body: body,
+			mockResponses: []bson.D{
+				refreshToken(bson.E{Key: "expires_at", Value: expiresAt}),
+				mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch),
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedErr: api.Error{
+				Detail: errInvalidRefreshToken,
+			},
+		},
+		{
+			name:           "error using refresh token",
+			body:           body,
+			mockResponses:  []bson.D{{{"ok", 0}}},
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr: api.Error{
+				Detail: errRefreshToken,
+			},
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponses...)
+
+			repo := mongoRepo.New(mt.DB)
+			h := New(repo, newTokenManager(t), nil, testPasswords, newPasswordPolicy(t), newCursorCodec(t), nil, &config.Config{})
+
+			ctx, response := setUpRequest(echo.POST, "/auth/refresh", tt.body)
+
+			err := h.RefreshToken(ctx)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+
+			if tt.expectedErr.Detail != "" {
+				assertProblem(t, response, tt.expectedErr)
+			} else {
+				var responseBody api.TokenResponse
+				err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+				require.NoError(t, err)
+
+				assert.NotEmpty(t, responseBody.AccessToken)
+				assert.NotEmpty(t, responseBody.RefreshToken)
+				assert.NotEqual(t, "token", responseBody.RefreshToken)
+			}
+		})
+	}
+}
+
+func TestHandler_Logout(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	body := `{"refresh_token":"token"}`
+
+	tests := []struct {
+		name           string
+		body           string
+		mockResponses  []bson.D
+		expectedStatus int
+		expectedErr    api.Error
+	}{
+		{
+			name: "can log out",
+			body: body,
+			mockResponses: []bson.D{
+				mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{
+					{"_id", hexID},
+					{"hash", auth.HashOpaqueToken("token")},
+					{"family_id", "family"},
+					{"user_id", hexID},
+				}),
+				{{"ok", 1}, {"n", 1}, {"nModified", 1}},
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name: "unknown refresh token",
+			body: body,
+			mockResponses: []bson.D{
+				mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch),
+			},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "invalid request",
+			body:           `{"`,
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errParseBody,
+			},
+		},
+		{
+			name:           "error finding refresh token",
+			body:           body,
+			mockResponses:  []bson.D{{{"ok", 0}}},
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr: api.Error{
+				Detail: errLogout,
+			},
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponses...)
+
+			repo := mongoRepo.New(mt.DB)
+			h := New(repo, newTokenManager(t), nil, testPasswords, newPasswordPolicy(t), newCursorCodec(t), nil, &config.Config{})
+
+			ctx, response := setUpRequest(echo.POST, "/auth/logout", tt.body)
+
+			err := h.Logout(ctx)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+
+			if tt.expectedErr.Detail != "" {
+				assertProblem(t, response, tt.expectedErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/auth"
+	"github.com/danielMensah/user-management/internal/mail"
+	"github.com/danielMensah/user-management/internal/repository"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	errVerifyEmail              = "failed to verify email"
+	errInvalidVerificationToken = "invalid or expired email verification token"
+	errSendVerification         = "failed to send email verification"
+	errEmailNotVerified         = "email address not verified"
+
+	emailVerificationSubject = "Verify your email address"
+)
+
+// VerifyEmail consumes an email verification token and marks the email of its user as verified
+func (h *Handler) VerifyEmail(ctx echo.Context) error {
+	body := new(api.VerifyEmailRequest)
+	if err := ctx.Bind(body); err != nil {
+		logrus.WithError(err).Error(errParseBody)
+		return renderProblem(ctx, http.StatusBadRequest, errParseBody)
+	}
+
+	reqCtx := ctx.Request().Context()
+
+	token, err := h.repo.UseOneTimeToken(reqCtx, auth.HashOpaqueToken(body.Token), repository.TokenPurposeEmailVerification)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return renderProblem(ctx, http.StatusBadRequest, errInvalidVerificationToken)
+		}
+
+		return renderServerError(ctx, err, errVerifyEmail)
+	}
+
+	// the token no longer applies when the user changed their email since it was sent
+	if err = h.repo.VerifyEmail(reqCtx, token.UserID, token.Email); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return renderProblem(ctx, http.StatusBadRequest, errInvalidVerificationToken)
+		}
+
+		return renderServerError(ctx, err, errVerifyEmail)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// ResendEmailVerification emails a new verification token to the user with the given email. It responds with 202
+// whether or not the user exists so that callers cannot enumerate accounts.
+func (h *Handler) ResendEmailVerification(ctx echo.Context) error {
+	body := new(api.EmailRequest)
+	if err := ctx.Bind(body); err != nil {
+		logrus.WithError(err).Error(errParseBody)
+		return renderProblem(ctx, http.StatusBadRequest, errParseBody)
+	}
+
+	reqCtx := ctx.Request().Context()
+
+	creds, err := h.repo.GetCredentialsByEmail(reqCtx, string(body.Email))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ctx.NoContent(http.StatusAccepted)
+		}
+
+		return renderServerError(ctx, err, errSendVerification)
+	}
+
+	if !creds.EmailVerified {
+		h.sendEmailVerification(reqCtx, creds.ID, creds.Email)
+	}
+
+	return ctx.NoContent(http.StatusAccepted)
+}
+
+// sendEmailVerification emails a new verification token to the user. Failures are logged rather than returned
+// as they must not fail the operation that triggered the verification.
+func (h *Handler) sendEmailVerification(ctx context.Context, userID, email string) {
+	ttl := h.cfg.EmailVerificationTTL
+
+	token, err := h.issueOneTimeToken(ctx, repository.TokenPurposeEmailVerification, userID, email, ttl)
+	if err == nil {
+		err = h.mailer.Send(ctx, mail.Message{
+			To:      email,
+			Subject: emailVerificationSubject,
+			Body:    emailVerificationBody(h.cfg.EmailVerificationURL, token, ttl),
+		})
+	}
+
+	if err != nil {
+		logrus.WithError(err).WithField("user_id", userID).Error(errSendVerification)
+	}
+}
+
+func emailVerificationBody(verifyURL, token string, ttl time.Duration) string {
+	return fmt.Sprintf(
+		"Please confirm that this is your email address, %s\n\nThe token expires in %s.",
+		tokenInstructions(verifyURL, token, "verify it"), ttl,
+	)
+}
@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/config"
+	"github.com/danielMensah/user-management/internal/mail"
+	mongoRepo "github.com/danielMensah/user-management/internal/repository/mongo"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestHandler_VerifyEmail(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	token := bson.D{
+		{"ok", 1},
+		{"value", bson.D{
+			{"hash", "hash"},
+			{"purpose", "email_verification"},
+			{"user_id", hexID},
+			{"email", "jd@jd@mensah.com.com"},
+		}},
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		mockResponses  []bson.D
+		expectedStatus int
+		expectedErr    api.Error
+	}{
+		{
+			name:           "verifies email",
+			body:           `{"token":"token"}`,
+			mockResponses:  []bson.D{token, {{"ok", 1}, {"n", 1}, {"nModified", 1}}},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "invalid request",
+			body:           `{"`,
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errParseBody,
+			},
+		},
+		{
+			name:           "invalid, used or expired token",
+			body:           `{"token":"token"}`,
+			mockResponses:  []bson.D{{{"ok", 1}, {"value", nil}}},
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errInvalidVerificationToken,
+			},
+		},
+		{
+			name:           "email changed since the token was sent",
+			body:           `{"token":"token"}`,
+			mockResponses:  []bson.D{token, {{"ok", 1}, {"n", 0}, {"nModified", 0}}},
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errInvalidVerificationToken,
+			},
+		},
+		{
+			name:           "error using token",
+			body:           `{"token":"token"}`,
+			mockResponses:  []bson.D{{{"ok", 0}}},
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr: api.Error{
+				Detail: errVerifyEmail,
+			},
+		},
+		{
+			name:           "error verifying email",
+			body:           `{"token":"token"}`,
+			mockResponses:  []bson.D{token, {{"ok", 0}}},
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr: api.Error{
+				Detail: errVerifyEmail,
+			},
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponses...)
+
+			h := New(mongoRepo.New(mt.DB), nil, nil, testPasswords, newPasswordPolicy(t), newCursorCodec(t), nil, &config.Config{})
+
+			ctx, response := setUpRequest(echo.POST, "/auth/email/verify", tt.body)
+
+			err := h.VerifyEmail(ctx)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+
+			if tt.expectedErr.Detail != "" {
+				assertProblem(t, response, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestHandler_ResendEmailVerification(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tests := []struct {
+		name           string
+		body           string
+		mockResponses  []bson.D
+		expectedStatus int
+		expectedMails  int
+		expectedErr    api.Error
+	}{
+		{
+			name: "emails verification token",
+			body: `{"email":"jd@jd@mensah.com.com"}`,
+			mockResponses: []bson.D{
+				mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{
+					{"_id", hexID},
+					{"email", "jd@jd@mensah.com.com"},
+				}),
+				{{"ok", 1}},
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedMails:  1,
+		},
+		{
+			name: "email already verified",
+			body: `{"email":"jd@jd@mensah.com.com"}`,
+			mockResponses: []bson.D{
+				mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{
+					{"_id", hexID},
+					{"email", "jd@jd@mensah.com.com"},
+					{"email_verified", true},
+				}),
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name: "unknown email",
+			body: `{"email":"unknown@mensah.com"}`,
+			mockResponses: []bson.D{
+				mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch),
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "invalid request",
+			body:           `{"`,
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errParseBody,
+			},
+		},
+		{
+			name:           "error finding user",
+			body:           `{"email":"jd@jd@mensah.com.com"}`,
+			mockResponses:  []bson.D{{{"ok", 0}}},
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr: api.Error{
+				Detail: errSendVerification,
+			},
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponses...)
+
+			dir := t.TempDir()
+			cfg := &config.Config{EmailVerificationTTL: 24 * time.Hour}
+			h := New(mongoRepo.New(mt.DB), nil, nil, testPasswords, newPasswordPolicy(t), newCursorCodec(t), mail.NewFileSender(dir, "from@example.com"), cfg)
+
+			ctx, response := setUpRequest(echo.POST, "/auth/email/verification", tt.body)
+
+			err := h.ResendEmailVerification(ctx)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+
+			mails, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			assert.Len(t, mails, tt.expectedMails)
+
+			if tt.expectedErr.Detail != "" {
+				assertProblem(t, response, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestEmailVerificationBody(t *testing.T) {
+	assert.Contains(t, emailVerificationBody("", "abc", time.Hour), "token to verify it: abc")
+	assert.Contains(t, emailVerificationBody("https://example.com/verify", "abc", time.Hour), "https://example.com/verify?token=abc")
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	errPreconditionFailed   = "user was changed since the version given by If-Match"
+	errPreconditionRequired = "If-Match header is required to change users"
+
+	headerETag = "ETag"
+
+	// anyETag matches any version of a resource in If-Match and If-None-Match headers
+	anyETag = "*"
+	// weakPrefix marks weak entity tags, which never match when compared strongly
+	weakPrefix = "W/"
+)
+
+// userETag returns the entity tag of a version of a user. The tag is weak when the representation of the user only
+// has some of its fields.
+func userETag(version int64, sparse bool) string {
+	tag := strconv.Quote(strconv.FormatInt(version, 10))
+	if sparse {
+		return weakPrefix + tag
+	}
+
+	return tag
+}
+
+// setUserETag sets the entity tag of a user on the response
+func setUserETag(ctx echo.Context, user *api.User, sparse bool) {
+	ctx.Response().Header().Set(headerETag, userETag(user.Version, sparse))
+}
+
+// ifMatch returns the versions of a user a write is conditioned on by its If-Match header, which are none when the
+// header is missing or is "*". The header is required when configured to, and a header none of whose entity tags can
+// ever match fails the precondition.
+func (h *Handler) ifMatch(header *string) ([]int64, *echo.HTTPError) {
+	if header == nil {
+		if h.cfg.RequireIfMatch {
+			return nil, echo.NewHTTPError(http.StatusPreconditionRequired, errPreconditionRequired)
+		}
+		return nil, nil
+	}
+
+	tags := entityTags(*header)
+	versions := make([]int64, 0, len(tags))
+	for _, tag := range tags {
+		if tag == anyETag {
+			return nil, nil
+		}
+		if version, ok := tagVersion(tag); ok && !strings.HasPrefix(tag, weakPrefix) {
+			versions = append(versions, version)
+		}
+	}
+
+	if len(versions) == 0 {
+		return nil, echo.NewHTTPError(http.StatusPreconditionFailed, errPreconditionFailed)
+	}
+
+	return versions, nil
+}
+
+// matchesVersion reports whether the given versions of a write conditioned by If-Match allow a version of a user
+func matchesVersion(versions []int64, version int64) bool {
+	if len(versions) == 0 {
+		return true
+	}
+
+	for _, v := range versions {
+		if v == version {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ifNoneMatch reports whether an If-None-Match header has an entity tag of the version of a user, compared weakly
+func ifNoneMatch(header *string, version int64) bool {
+	if header == nil {
+		return false
+	}
+
+	for _, tag := range entityTags(*header) {
+		if tag == anyETag {
+			return true
+		}
+		if v, ok := tagVersion(tag); ok && v == version {
+			return true
+		}
+	}
+
+	return false
+}
+
+// entityTags splits the comma separated entity tags of a header
+func entityTags(header string) []string {
+	tags := strings.Split(header, ",")
+	for i := range tags {
+		tags[i] = strings.TrimSpace(tags[i])
+	}
+
+	return tags
+}
+
+// tagVersion returns the version of a user an entity tag stands for, ignoring whether the tag is weak
+func tagVersion(tag string) (int64, bool) {
+	opaque := strings.TrimPrefix(tag, weakPrefix)
+	if len(opaque) < 2 || opaque[0] != '"' || opaque[len(opaque)-1] != '"' {
+		return 0, false
+	}
+
+	digits := opaque[1 : len(opaque)-1]
+	version, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil || strconv.FormatInt(version, 10) != digits {
+		return 0, false
+	}
+
+	return version, true
+}
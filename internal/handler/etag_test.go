@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/danielMensah/user-management/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_ifMatch(t *testing.T) {
+	tests := []struct {
+		name             string
+		header           *string
+		requireIfMatch   bool
+		expectedVersions []int64
+		expectedStatus   int
+	}{
+		{
+			name: "no header",
+		},
+		{
+			name:           "required header is missing",
+			requireIfMatch: true,
+			expectedStatus: http.StatusPreconditionRequired,
+		},
+		{
+			name:             "strong entity tags",
+			header:           pstring(`"3", W/"4" ,"5"`),
+			expectedVersions: []int64{3, 5},
+		},
+		{
+			name:           "any version",
+			header:         pstring(`"3", *`),
+			requireIfMatch: true,
+		},
+		{
+			name:           "no entity tag can match",
+			header:         pstring(`W/"3", "v3", "03", 3`),
+			expectedStatus: http.StatusPreconditionFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := &Handler{cfg: &config.Config{RequireIfMatch: tt.requireIfMatch}}
+
+			versions, httpErr := h.ifMatch(tt.header)
+			assert.Equal(t, tt.expectedVersions, versions)
+
+			if tt.expectedStatus != 0 {
+				assert.Equal(t, tt.expectedStatus, httpErr.Code)
+			} else {
+				assert.Nil(t, httpErr)
+			}
+		})
+	}
+}
+
+func TestIfNoneMatch(t *testing.T) {
+	assert.False(t, ifNoneMatch(nil, 3))
+	assert.True(t, ifNoneMatch(pstring(`"2", W/"3"`), 3))
+	assert.True(t, ifNoneMatch(pstring(`*`), 3))
+	assert.False(t, ifNoneMatch(pstring(`"2", "03"`), 3))
+}
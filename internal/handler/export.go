@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/exporter"
+	"github.com/danielMensah/user-management/internal/query"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	errExportUsers   = "failed to export users"
+	errNotAcceptable = "none of the accepted media types can be exported"
+
+	// exportFlushInterval is the number of users written between flushes of the response
+	exportFlushInterval = 100
+)
+
+// exportFileNames name the file of the exports of each media type
+var exportFileNames = map[string]string{
+	exporter.MIMEJSON:   "users.json",
+	exporter.MIMENDJSON: "users.ndjson",
+	exporter.MIMECSV:    "users.csv",
+}
+
+// ExportUsers streams every user matching the same filters as GetUsers, in the media type the Accept header prefers.
+// Users are written as they are read and the response is flushed every exportFlushInterval users, so that exports
+// of any size take the same memory. An export failing once users were written is logged and cut short.
+func (h *Handler) ExportUsers(ctx echo.Context, params api.ExportUsersParams) error {
+	claims, permissions, httpErr := h.authorize(ctx)
+	if httpErr != nil {
+		return renderError(ctx, httpErr)
+	}
+
+	var allowed bool
+	params.Country, allowed = canListUsers(claims, permissions, params.Country)
+	if !allowed || !canListDeletedUsers(permissions, params.IncludeDeleted) {
+		return renderProblem(ctx, http.StatusForbidden, errForbidden)
+	}
+
+	mediaType, err := exporter.Negotiate(ctx.Request().Header.Get(echo.HeaderAccept))
+	if err != nil {
+		return renderProblem(ctx, http.StatusNotAcceptable, errNotAcceptable)
+	}
+
+	q, err := usersQuery(api.GetUsersParams{
+		Country:        params.Country,
+		Email:          params.Email,
+		Filter:         params.Filter,
+		Sort:           params.Sort,
+		Fields:         params.Fields,
+		IncludeDeleted: params.IncludeDeleted,
+	})
+	if err != nil {
+		return renderQueryError(ctx, err)
+	}
+
+	export := &userExport{ctx: ctx, mediaType: mediaType, fields: q.Fields}
+	err = h.repo.ExportUsers(ctx.Request().Context(), q, export.write)
+	if err == nil {
+		err = export.close()
+	}
+
+	if err != nil && export.writer == nil {
+		return renderServerError(ctx, err, errExportUsers)
+	}
+	if err != nil {
+		logrus.WithError(err).WithField("exported", export.written).Error(errExportUsers)
+	}
+
+	return nil
+}
+
+// userExport writes exported users to the response, which starts with the first user
+type userExport struct {
+	ctx       echo.Context
+	mediaType string
+	fields    []query.Field
+	writer    exporter.Writer
+	written   int
+}
+
+// write writes a user, with only the fields of the export unless it has none
+func (e *userExport) write(user *api.User) error {
+	if err := e.start(); err != nil {
+		return err
+	}
+
+	var value interface{} = user
+	if len(e.fields) > 0 {
+		sparse, err := sparseUser(user, e.fields)
+		if err != nil {
+			return err
+		}
+		value = sparse
+	}
+
+	if err := e.writer.Write(value); err != nil {
+		return err
+	}
+
+	e.written++
+	if e.written%exportFlushInterval == 0 {
+		return e.flush()
+	}
+
+	return nil
+}
+
+// start writes the headers of the response once, before the first user
+func (e *userExport) start() error {
+	if e.writer != nil {
+		return nil
+	}
+
+	writer, err := exporter.NewWriter(e.mediaType, e.ctx.Response(), exportColumns(e.fields))
+	if err != nil {
+		return err
+	}
+	e.writer = writer
+
+	header := e.ctx.Response().Header()
+	header.Set(echo.HeaderContentType, e.mediaType)
+	header.Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", exportFileNames[e.mediaType]))
+	e.ctx.Response().WriteHeader(http.StatusOK)
+
+	return nil
+}
+
+// close ends the export once every user was written, starting it when there were no users
+func (e *userExport) close() error {
+	if err := e.start(); err != nil {
+		return err
+	}
+
+	if err := e.writer.Close(); err != nil {
+		return err
+	}
+
+	return e.flush()
+}
+
+func (e *userExport) flush() error {
+	if err := e.writer.Flush(); err != nil {
+		return err
+	}
+
+	e.ctx.Response().Flush()
+
+	return nil
+}
+
+// exportColumns returns the names of the given fields, or of every field along with the version when none are given
+func exportColumns(fields []query.Field) []string {
+	if len(fields) > 0 {
+		return fieldNames(fields)
+	}
+
+	return append(fieldNames(query.AllFields()), "version")
+}
+
+func fieldNames(fields []query.Field) []string {
+	names := make([]string, 0, len(fields))
+	for _, field := range fields {
+		names = append(names, field.Name)
+	}
+
+	return names
+}
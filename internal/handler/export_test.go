@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/auth"
+	"github.com/danielMensah/user-management/internal/config"
+	"github.com/danielMensah/user-management/internal/exporter"
+	"github.com/danielMensah/user-management/internal/permission"
+	"github.com/danielMensah/user-management/internal/query"
+	mongoRepo "github.com/danielMensah/user-management/internal/repository/mongo"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestHandler_ExportUsers(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	john := bson.D{{"_id", hexID}, {"nickname", "jd"}, {"country", "UK"}, {"roles", bson.A{"admin", "user"}}, {"version", 2}}
+	jane := bson.D{{"_id", hexID}, {"nickname", "jane"}, {"country", "UK"}, {"version", 1}}
+	batches := []bson.D{
+		mtest.CreateCursorResponse(1, "foo.bar", mtest.FirstBatch, john),
+		mtest.CreateCursorResponse(0, "foo.bar", mtest.NextBatch, jane),
+	}
+
+	tests := []struct {
+		name                string
+		accept              string
+		params              api.ExportUsersParams
+		caller              *auth.Claims
+		mockResponses       []bson.D
+		expectedStatus      int
+		expectedErr         api.Error
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:                "exports users as a json array by default",
+			mockResponses:       batches,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: exporter.MIMEJSON,
+		},
+		{
+			name:                "exports the given fields as csv",
+			accept:              "text/csv",
+			params:              api.ExportUsersParams{Fields: &[]string{"nickname", "roles"}},
+			mockResponses:       batches,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: exporter.MIMECSV,
+			expectedBody:        "nickname,roles\njd,admin;user\njane,\n",
+		},
+		{
+			name:                "exports users as ndjson",
+			accept:              "application/x-ndjson",
+			params:              api.ExportUsersParams{Fields: &[]string{"nickname"}},
+			mockResponses:       batches,
+			expectedStatus:      http.StatusOK,
+			expectedContentType: exporter.MIMENDJSON,
+			expectedBody:        "{\"nickname\":\"jd\"}\n{\"nickname\":\"jane\"}\n",
+		},
+		{
+			name:                "no users to export",
+			mockResponses:       []bson.D{mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch)},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: exporter.MIMEJSON,
+			expectedBody:        "[]\n",
+		},
+		{
+			name:           "no acceptable media type",
+			accept:         "application/xml",
+			expectedStatus: http.StatusNotAcceptable,
+			expectedErr:    api.Error{Detail: errNotAcceptable},
+		},
+		{
+			name:           "user cannot export users",
+			caller:         newClaims(hexID, "UK", permission.RoleUser),
+			expectedStatus: http.StatusForbidden,
+			expectedErr:    api.Error{Detail: errForbidden},
+		},
+		{
+			name:           "invalid filter",
+			params:         api.ExportUsersParams{Filter: &[]string{"password:eq:secret"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errInvalidQuery,
+				Errors: &[]api.FieldError{{Parameter: pstring(query.ParamFilter), Reason: `unknown field "password"`}},
+			},
+		},
+		{
+			name:           "error before any user is exported",
+			mockResponses:  []bson.D{{{"ok", 0}}},
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr:    api.Error{Detail: errExportUsers},
+		},
+		{
+			name:   "error once users were exported",
+			accept: "application/x-ndjson",
+			params: api.ExportUsersParams{Fields: &[]string{"nickname"}},
+			mockResponses: []bson.D{
+				mtest.CreateCursorResponse(1, "foo.bar", mtest.FirstBatch, john),
+				{{"ok", 0}},
+			},
+			expectedStatus:      http.StatusOK,
+			expectedContentType: exporter.MIMENDJSON,
+			expectedBody:        "{\"nickname\":\"jd\"}\n",
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponses...)
+
+			h := New(mongoRepo.New(mt.DB), nil, nil, testPasswords, newPasswordPolicy(t), newCursorCodec(t), nil, &config.Config{})
+
+			ctx, response := setUpRequest(echo.GET, "/users/export", "")
+			ctx.Request().Header.Set(echo.HeaderAccept, tt.accept)
+			authenticate(ctx, tt.caller)
+
+			err := h.ExportUsers(ctx, tt.params)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+
+			if tt.expectedErr.Detail != "" {
+				assertProblem(t, response, tt.expectedErr)
+				return
+			}
+
+			assert.Equal(t, tt.expectedContentType, response.Header().Get(echo.HeaderContentType))
+			assert.True(t, strings.HasPrefix(response.Header().Get(echo.HeaderContentDisposition), "attachment"))
+
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, response.Body.String())
+				return
+			}
+
+			var users []api.User
+			require.NoError(t, json.Unmarshal(response.Body.Bytes(), &users))
+			require.Len(t, users, 2)
+			assert.True(t, response.Flushed)
+			assert.Equal(t, "jd", users[0].Nickname)
+			assert.Equal(t, int64(2), users[0].Version)
+			assert.Equal(t, "jane", users[1].Nickname)
+		})
+	}
+}
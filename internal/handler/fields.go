@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/query"
+	"github.com/labstack/echo/v4"
+)
+
+const errRenderFields = "failed to render user fields"
+
+// sparseUsersResponse represents a page of users rendered with only the requested fields
+type sparseUsersResponse struct {
+	api.GetUsersResponse
+	Users []map[string]interface{} `json:"users"`
+}
+
+// renderUsers renders a page of users, with only the given fields of the users unless none are given
+func renderUsers(ctx echo.Context, response api.GetUsersResponse, fields []query.Field) error {
+	if len(fields) == 0 {
+		return ctx.JSON(http.StatusOK, response)
+	}
+
+	users := make([]map[string]interface{}, 0, len(*response.Users))
+	for i := range *response.Users {
+		user, err := sparseUser(&(*response.Users)[i], fields)
+		if err != nil {
+			return renderServerError(ctx, err, errRenderFields)
+		}
+		users = append(users, user)
+	}
+
+	return ctx.JSON(http.StatusOK, sparseUsersResponse{GetUsersResponse: response, Users: users})
+}
+
+// renderUser renders a user, with only the given fields unless none are given
+func renderUser(ctx echo.Context, user *api.User, fields []query.Field) error {
+	if len(fields) == 0 {
+		return ctx.JSON(http.StatusOK, user)
+	}
+
+	sparse, err := sparseUser(user, fields)
+	if err != nil {
+		return renderServerError(ctx, err, errRenderFields)
+	}
+
+	return ctx.JSON(http.StatusOK, sparse)
+}
+
+// sparseUser returns the given fields of a user keyed by their json name, leaving out the fields the user does not have
+func sparseUser(user *api.User, fields []query.Field) (map[string]interface{}, error) {
+	data, err := json.Marshal(user)
+	if err != nil {
+		return nil, err
+	}
+
+	all := map[string]interface{}{}
+	if err = json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+
+	sparse := make(map[string]interface{}, len(fields))
+	for _, field := range fields {
+		if value, ok := all[field.Name]; ok {
+			sparse[field.Name] = value
+		}
+	}
+
+	return sparse, nil
+}
+
+// readFields returns the fields to read a user with for the caller to be authorized to read them, along with the
+// fields requested. No fields means every field.
+func readFields(requested []query.Field) []query.Field {
+	if len(requested) == 0 {
+		return nil
+	}
+
+	return append([]query.Field{query.FieldID, query.FieldCountry}, requested...)
+}
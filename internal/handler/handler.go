@@ -1,26 +1,54 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/auth"
+	"github.com/danielMensah/user-management/internal/config"
+	"github.com/danielMensah/user-management/internal/mail"
+	"github.com/danielMensah/user-management/internal/pagination"
+	"github.com/danielMensah/user-management/internal/permission"
+	"github.com/danielMensah/user-management/internal/query"
 	"github.com/danielMensah/user-management/internal/repository"
 	"github.com/labstack/echo/v4"
 	"github.com/sirupsen/logrus"
 )
 
 const (
-	errGetUsers   = "failed to get users"
-	errParseBody  = "failed to parse request body"
-	errCreateUser = "failed to create user"
-	errUpdateUser = "failed to update user"
-	errDeleteUser = "failed to delete user"
-	errEncryptPwd = "failed to encrypt password"
+	errGetUsers      = "failed to get users"
+	errGetUser       = "failed to get user"
+	errInvalidID     = "invalid user id"
+	errUserExists    = "user already exists"
+	errUnavailable   = "service temporarily unavailable, try again later"
+	errParseBody     = "failed to parse request body"
+	errCreateUser    = "failed to create user"
+	errUpdateUser    = "failed to update user"
+	errDeleteUser    = "failed to delete user"
+	errRestoreUser   = "failed to restore user"
+	errEncryptPwd    = "failed to encrypt password"
+	errSetRoles      = "failed to set user roles"
+	errUnknownRole   = "unknown role"
+	errInvalidCursor = "invalid cursor"
+	errInvalidQuery  = "invalid query parameters"
 )
 
 // Handler represents handlers for user management
 type Handler struct {
-	repo repository.UserRepository
+	repo        repository.Repository
+	tokens      *auth.TokenManager
+	totp        *auth.TOTP
+	passwords   auth.PasswordHasher
+	policy      *auth.PasswordPolicy
+	cursors     *pagination.CursorCodec
+	permissions *permission.Evaluator
+	mailer      mail.Sender
+	cfg         *config.Config
 }
 
 func (h *Handler) GetHealthz(ctx echo.Context) error {
@@ -28,77 +56,366 @@ func (h *Handler) GetHealthz(ctx echo.Context) error {
 }
 
 // New creates a new user handler
-func New(repo repository.UserRepository) *Handler {
-	return &Handler{repo}
+func New(
+	repo repository.Repository,
+	tokens *auth.TokenManager,
+	totp *auth.TOTP,
+	passwords auth.PasswordHasher,
+	policy *auth.PasswordPolicy,
+	cursors *pagination.CursorCodec,
+	mailer mail.Sender,
+	cfg *config.Config,
+) *Handler {
+	return &Handler{repo, tokens, totp, passwords, policy, cursors, permission.NewEvaluator(repo), mailer, cfg}
 }
 
 // GetUsers returns a list of users
 func (h *Handler) GetUsers(ctx echo.Context, params api.GetUsersParams) error {
-	users, err := h.repo.GetUsers(ctx.Request().Context(), params)
+	claims, permissions, httpErr := h.authorize(ctx)
+	if httpErr != nil {
+		return renderError(ctx, httpErr)
+	}
+
+	var allowed bool
+	params.Country, allowed = canListUsers(claims, permissions, params.Country)
+	if !allowed || !canListDeletedUsers(permissions, params.IncludeDeleted) {
+		return renderProblem(ctx, http.StatusForbidden, errForbidden)
+	}
+
+	q, err := usersQuery(params)
+	if err != nil {
+		return renderQueryError(ctx, err)
+	}
+
+	if q.Cursor, err = h.decodeCursor(params.Cursor, q.Sort); err != nil {
+		return renderProblem(ctx, http.StatusBadRequest, errInvalidCursor)
+	}
+
+	reqCtx := ctx.Request().Context()
+
+	page, err := h.repo.GetUsers(reqCtx, q)
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		return renderProblem(ctx, http.StatusBadRequest, errInvalidCursor)
+	}
+	if err != nil {
+		return renderServerError(ctx, err, errGetUsers)
+	}
+
+	response := api.GetUsersResponse{
+		Users:   &page.Users,
+		Page:    pageNumber(q),
+		Limit:   params.Limit,
+		HasMore: page.More,
+	}
+
+	if params.Total == nil || *params.Total {
+		total, err := h.repo.CountUsers(reqCtx, q)
+		if err != nil {
+			return renderServerError(ctx, err, errGetUsers)
+		}
+
+		response.Total = &total
+		ctx.Response().Header().Set(headerTotalCount, strconv.FormatInt(total, 10))
+	}
+
+	if err = h.linkPages(ctx, &response, page, q.Sort); err != nil {
+		return renderServerError(ctx, err, errGetUsers)
+	}
+
+	return renderUsers(ctx, response, q.Fields)
+}
+
+// GetUser returns a user, with only the requested fields when fields are requested. Users of other countries are not
+// found by callers only allowed to read the users of their own country, as they are not listed to them.
+func (h *Handler) GetUser(ctx echo.Context, id string, params api.GetUserParams) error {
+	claims, permissions, httpErr := h.authorize(ctx)
+	if httpErr != nil {
+		return renderError(ctx, httpErr)
+	}
+	if !mayReadUser(claims, permissions, id) {
+		return renderProblem(ctx, http.StatusForbidden, errForbidden)
+	}
+
+	var names []string
+	if params.Fields != nil {
+		names = *params.Fields
+	}
+	fields, err := query.ParseFields(names)
+	if err != nil {
+		return renderQueryError(ctx, err)
+	}
+
+	user, err := h.repo.GetUser(ctx.Request().Context(), id, readFields(fields))
 	if err != nil {
-		logrus.WithError(err).Error(errGetUsers)
-		return ctx.JSON(http.StatusInternalServerError, api.Error{Message: errGetUsers})
+		return renderRepositoryError(ctx, err, errGetUser)
+	}
+
+	if !canReadUser(claims, permissions, user) {
+		return renderProblem(ctx, http.StatusNotFound, errUserNotFound)
 	}
 
-	return ctx.JSON(http.StatusOK, api.GetUsersResponse{Users: users})
+	setUserETag(ctx, user, len(fields) > 0)
+	if ifNoneMatch(params.IfNoneMatch, user.Version) {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+
+	return renderUser(ctx, user, fields)
 }
 
-// CreateUser creates a new user
+// CreateUser creates a new user, returning the entity tag of its initial version
 func (h *Handler) CreateUser(ctx echo.Context) error {
 	var err error
 	body := new(api.UserCreateData)
 	if err = ctx.Bind(body); err != nil {
 		logrus.WithError(err).Error(errParseBody)
-		return ctx.JSON(http.StatusBadRequest, api.Error{Message: errParseBody})
+		return renderProblem(ctx, http.StatusBadRequest, errParseBody)
+	}
+
+	if rejected, err := h.enforcePasswordPolicy(ctx, body.Password, string(body.Email), body.Nickname); rejected {
+		return err
 	}
 
-	if body.Password, err = encryptPassword(body.Password); err != nil {
+	if body.Password, err = h.passwords.Hash(body.Password); err != nil {
 		logrus.WithError(err).Error(errCreateUser)
-		return ctx.JSON(http.StatusInternalServerError, api.Error{Message: errEncryptPwd})
+		return renderProblem(ctx, http.StatusInternalServerError, errEncryptPwd)
 	}
 
 	id, err := h.repo.CreateUser(ctx.Request().Context(), body)
 	if err != nil {
-		logrus.WithError(err).Error(errCreateUser)
-		return ctx.JSON(http.StatusInternalServerError, api.Error{Message: errCreateUser})
+		return renderRepositoryError(ctx, err, errCreateUser)
 	}
 
+	h.sendEmailVerification(ctx.Request().Context(), id, string(body.Email))
+
+	ctx.Response().Header().Set(headerETag, userETag(repository.InitialVersion, false))
+
 	return ctx.JSON(http.StatusCreated, api.CreateUserResponse{Id: id})
 }
 
-// UpdateUser updates a user
-func (h *Handler) UpdateUser(ctx echo.Context, id string) error {
-	body := new(api.UserUpdateData)
+// UpdateUser replaces the editable fields of a user
+func (h *Handler) UpdateUser(ctx echo.Context, id string, params api.UpdateUserParams) error {
+	claims, permissions, httpErr := h.authorize(ctx)
+	if httpErr != nil {
+		return renderError(ctx, httpErr)
+	}
+
+	// the user is only looked up once the caller is allowed to update them, so that their existence is not revealed
+	if !canUpdateUser(claims, permissions, id, false) {
+		return renderProblem(ctx, http.StatusForbidden, errForbidden)
+	}
+
+	versions, httpErr := h.ifMatch(params.IfMatch)
+	if httpErr != nil {
+		return renderError(ctx, httpErr)
+	}
+
+	body := new(api.UserReplaceData)
 	if err := ctx.Bind(body); err != nil {
 		logrus.WithError(err).Error(errParseBody)
-		return ctx.JSON(http.StatusBadRequest, api.Error{Message: errParseBody})
+		return renderProblem(ctx, http.StatusBadRequest, errParseBody)
 	}
 
-	if body.Password != nil && *body.Password != "" {
-		p, err := encryptPassword(*body.Password)
-		if err != nil {
-			logrus.WithError(err).Error(errEncryptPwd)
-			return ctx.JSON(http.StatusInternalServerError, api.Error{Message: errEncryptPwd})
+	return h.replaceUser(ctx, claims, permissions, id, body, versions)
+}
+
+// replaceUser replaces the editable fields of a user at one of the given versions, or at any version when none are
+// given, for callers already allowed to update the user. Changing the country takes the permission to update the
+// profile of users. Changing the email or the password takes the permission to change the credentials of the user,
+// and a verified email when those are required. A new password must satisfy the
+// password policy and revokes the refresh tokens of the user, and a new email is verified again.
+func (h *Handler) replaceUser(
+	ctx echo.Context,
+	claims *auth.Claims,
+	permissions permission.Set,
+	id string,
+	data *api.UserReplaceData,
+	versions []int64,
+) error {
+	reqCtx := ctx.Request().Context()
+
+	creds, err := h.repo.GetCredentialsByID(reqCtx, id)
+	if err != nil {
+		return renderRepositoryError(ctx, err, errUpdateUser)
+	}
+
+	replacement := &repository.UserReplacement{
+		FirstName:    data.FirstName,
+		LastName:     data.LastName,
+		Nickname:     data.Nickname,
+		Email:        string(data.Email),
+		Country:      data.Country,
+		EmailChanged: string(data.Email) != creds.Email,
+	}
+	changesPassword := data.Password != nil && *data.Password != ""
+	changesCredentials := replacement.EmailChanged || changesPassword
+	changesCountry := data.Country != creds.Country
+
+	if httpErr := h.authorizeReplacement(claims, permissions, id, changesCredentials, changesCountry); httpErr != nil {
+		return renderError(ctx, httpErr)
+	}
+
+	if changesPassword {
+		var rejected bool
+		personalInfo := []string{creds.Email, creds.Nickname, string(data.Email), data.Nickname}
+		if replacement.Password, rejected, err = h.hashNewPassword(ctx, *data.Password, personalInfo...); rejected {
+			return err
 		}
+	}
+
+	user, err := h.repo.ReplaceUser(reqCtx, id, replacement, versions)
+	if err != nil {
+		return renderRepositoryError(ctx, err, errUpdateUser)
+	}
+
+	if err = h.credentialsReplaced(reqCtx, id, replacement); err != nil {
+		return renderServerError(ctx, err, errUpdateUser)
+	}
+
+	setUserETag(ctx, user, false)
+
+	return ctx.JSON(http.StatusOK, user)
+}
+
+// credentialsReplaced sends a verification email when the email of a user was replaced, and revokes the refresh tokens
+// of the user when their password was, so that sessions started with the previous password end
+func (h *Handler) credentialsReplaced(ctx context.Context, id string, replacement *repository.UserReplacement) error {
+	if replacement.EmailChanged {
+		h.sendEmailVerification(ctx, id, replacement.Email)
+	}
+
+	if replacement.Password != "" {
+		return h.repo.RevokeUserRefreshTokens(ctx, id)
+	}
+
+	return nil
+}
+
+// hashNewPassword checks a new password against the password policy and hashes it. When the password is rejected or
+// cannot be hashed it renders the error and reports that it did so.
+func (h *Handler) hashNewPassword(ctx echo.Context, password string, personalInfo ...string) (string, bool, error) {
+	if rejected, err := h.enforcePasswordPolicy(ctx, password, personalInfo...); rejected {
+		return "", true, err
+	}
+
+	hash, err := h.passwords.Hash(password)
+	if err != nil {
+		return "", true, renderServerError(ctx, err, errEncryptPwd)
+	}
+
+	return hash, false, nil
+}
+
+// DeleteUser soft deletes a user, who can be restored until purged
+func (h *Handler) DeleteUser(ctx echo.Context, id string, params api.DeleteUserParams) error {
+	claims, permissions, httpErr := h.authorize(ctx)
+	if httpErr != nil {
+		return renderError(ctx, httpErr)
+	}
+	if !canDeleteUser(claims, permissions, id) {
+		return renderProblem(ctx, http.StatusForbidden, errForbidden)
+	}
+	if httpErr = h.requireVerifiedEmail(claims); httpErr != nil {
+		return renderError(ctx, httpErr)
+	}
+
+	versions, httpErr := h.ifMatch(params.IfMatch)
+	if httpErr != nil {
+		return renderError(ctx, httpErr)
+	}
+
+	if err := h.repo.DeleteUser(ctx.Request().Context(), id, versions); err != nil {
+		return renderRepositoryError(ctx, err, errDeleteUser)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// RestoreUser restores a soft deleted user
+func (h *Handler) RestoreUser(ctx echo.Context, id string) error {
+	if httpErr := h.requireSensitivePermission(ctx, permission.UsersRestore); httpErr != nil {
+		return renderError(ctx, httpErr)
+	}
+
+	user, err := h.repo.RestoreUser(ctx.Request().Context(), id)
+	if err != nil {
+		return renderRepositoryError(ctx, err, errRestoreUser)
+	}
+
+	setUserETag(ctx, user, false)
+
+	return ctx.JSON(http.StatusOK, user)
+}
+
+// SetUserRoles replaces the roles of a user
+func (h *Handler) SetUserRoles(ctx echo.Context, id string) error {
+	if httpErr := h.requireSensitivePermission(ctx, permission.UsersRolesAssign); httpErr != nil {
+		return renderError(ctx, httpErr)
+	}
+
+	body := new(api.SetUserRolesRequest)
+	if err := ctx.Bind(body); err != nil {
+		logrus.WithError(err).Error(errParseBody)
+		return renderProblem(ctx, http.StatusBadRequest, errParseBody)
+	}
 
-		body.Password = &p
+	unknown, err := h.unknownRoles(ctx.Request().Context(), body.Roles)
+	if err != nil {
+		return renderServerError(ctx, err, errSetRoles)
+	}
+	if len(unknown) > 0 {
+		return renderProblem(ctx, http.StatusBadRequest, fmt.Sprintf("%s: %s", errUnknownRole, strings.Join(unknown, ", ")))
 	}
 
-	user, err := h.repo.UpdateUser(ctx.Request().Context(), id, body)
+	user, err := h.repo.SetUserRoles(ctx.Request().Context(), id, body.Roles)
 	if err != nil {
-		logrus.WithError(err).Error(errUpdateUser)
-		return ctx.JSON(http.StatusInternalServerError, api.Error{Message: errUpdateUser})
+		return renderRepositoryError(ctx, err, errSetRoles)
 	}
 
+	setUserETag(ctx, user, false)
+
 	return ctx.JSON(http.StatusOK, user)
 }
 
-// DeleteUser deletes a user
-func (h *Handler) DeleteUser(ctx echo.Context, id string) error {
-	if err := h.repo.DeleteUser(ctx.Request().Context(), id); err != nil {
-		logrus.WithError(err).Error(errDeleteUser)
-		return ctx.JSON(http.StatusInternalServerError, api.Error{Message: errDeleteUser})
+// renderRepositoryError renders an error of the repository for an operation on a user: 400 for malformed ids,
+// 404 for unknown users and 409 for conflicting users. Other errors are rendered as server errors.
+func renderRepositoryError(ctx echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, repository.ErrInvalidID):
+		return renderProblem(ctx, http.StatusBadRequest, errInvalidID)
+	case errors.Is(err, repository.ErrNotFound):
+		return renderProblem(ctx, http.StatusNotFound, errUserNotFound)
+	case errors.Is(err, repository.ErrConflict):
+		return renderProblem(ctx, http.StatusConflict, conflictMessage(err))
+	case errors.Is(err, repository.ErrVersionMismatch):
+		return renderProblem(ctx, http.StatusPreconditionFailed, errPreconditionFailed)
+	}
+
+	return renderServerError(ctx, err, message)
+}
+
+// conflictMessage names the field a user conflicts with an existing user on, when it is known
+func conflictMessage(err error) string {
+	var conflict *repository.ConflictError
+	if errors.As(err, &conflict) {
+		return fmt.Sprintf("%s with this %s", errUserExists, conflict.Field)
+	}
+
+	return errUserExists
+}
+
+// renderServerError logs an unexpected error and renders it with the given message
+func renderServerError(ctx echo.Context, err error, message string) error {
+	return renderError(ctx, serverError(err, message))
+}
+
+// serverError logs an unexpected error and returns it with the given message. Errors caused by the database being
+// unavailable are returned as a 503 so that clients can retry.
+func serverError(err error, message string) *echo.HTTPError {
+	logrus.WithError(err).Error(message)
+
+	if errors.Is(err, repository.ErrUnavailable) {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, errUnavailable)
 	}
 
-	return ctx.JSON(http.StatusNoContent, nil)
+	return echo.NewHTTPError(http.StatusInternalServerError, message)
 }
@@ -2,6 +2,8 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -10,6 +12,13 @@ import (
 	"time"
 
 	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/auth"
+	"github.com/danielMensah/user-management/internal/config"
+	"github.com/danielMensah/user-management/internal/mail"
+	"github.com/danielMensah/user-management/internal/pagination"
+	"github.com/danielMensah/user-management/internal/permission"
+	"github.com/danielMensah/user-management/internal/query"
+	"github.com/danielMensah/user-management/internal/repository"
 	mongoRepo "github.com/danielMensah/user-management/internal/repository/mongo"
 	"github.com/labstack/echo/v4"
 	"github.com/sirupsen/logrus"
@@ -41,8 +50,7 @@ func TestMain(m *testing.M) {
 	}
 
 	logrus.Info("dates initialized")
-	m.Run()
-	os.Exit(0)
+	os.Exit(m.Run())
 }
 
 func setUpRequest(method, endpoint, body string) (echo.Context, *httptest.ResponseRecorder) {
@@ -59,7 +67,7 @@ func TestNewUserHandlers(t *testing.T) {
 	defer mt.Close()
 
 	repo := mongoRepo.New(mt.DB)
-	handlers := New(repo)
+	handlers := New(repo, nil, nil, testPasswords, newPasswordPolicy(t), newCursorCodec(t), nil, &config.Config{})
 	assert.NotNil(t, handlers)
 }
 
@@ -67,59 +75,231 @@ func TestService_GetUsers(t *testing.T) {
 	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
 	defer mt.Close()
 
+	user := bson.D{
+		{"_id", hexID},
+		{"first_name", "john"},
+		{"last_name", "doe"},
+		{"nickname", "jd"},
+		{"email", "jd@jd@mensah.com.com"},
+		{"country", "UK"},
+		{"created_at", createdAt},
+		{"updated_at", updatedAt},
+	}
+	apiUser := api.User{
+		Id:        hexID,
+		FirstName: "john",
+		LastName:  "doe",
+		Nickname:  "jd",
+		Email:     "jd@jd@mensah.com.com",
+		Country:   "UK",
+		CreatedAt: createdAt,
+		UpdatedAt: updatedAt,
+	}
+	deletedUser := append(bson.D{{"deleted_at", updatedAt}}, user...)
+	apiDeletedUser := apiUser
+	apiDeletedUser.DeletedAt = &updatedAt
+
+	defaultSort := query.DefaultSort.String()
+	values := []string{"2020-01-01T00:00:00Z", hexID}
+	cursors := newCursorCodec(t)
+	next, err := cursors.Encode(&pagination.Cursor{Sort: defaultSort, Values: values})
+	require.NoError(t, err)
+	prev, err := cursors.Encode(&pagination.Cursor{Sort: defaultSort, Values: values, Before: true})
+	require.NoError(t, err)
+	last, err := cursors.Encode(pagination.End(defaultSort))
+	require.NoError(t, err)
+	nicknameLast, err := cursors.Encode(pagination.End("nickname,_id"))
+	require.NoError(t, err)
+
+	skip := int64(20)
+	firstAndLast := `</users>; rel="first", </users?cursor=` + last + `>; rel="last"`
+
 	tests := []struct {
 		name             string
 		params           api.GetUsersParams
 		mockResponses    []bson.D
 		mockError        bool
+		total            int64
 		expectedStatus   int
 		expectedResponse api.GetUsersResponse
+		expectedTotal    string
+		expectedLink     string
+		expectedFields   []map[string]interface{}
 		expectedErr      api.Error
+		caller           *auth.Claims
 	}{
 		{
-			name: "can get users",
+			name:           "can get users",
+			params:         api.GetUsersParams{Limit: 10},
+			mockResponses:  []bson.D{user},
+			total:          1,
+			expectedStatus: http.StatusOK,
+			expectedResponse: api.GetUsersResponse{
+				Users: &[]api.User{apiUser},
+				Total: pint64(1),
+				Page:  pint64(1),
+				Limit: 10,
+			},
+			expectedTotal: "1",
+			expectedLink:  firstAndLast,
+		},
+		{
+			name:           "manager can get users of their country",
+			params:         api.GetUsersParams{Limit: 10},
+			caller:         newClaims(hexID, "UK", permission.RoleManager),
+			mockResponses:  []bson.D{user},
+			total:          1,
+			expectedStatus: http.StatusOK,
+			expectedResponse: api.GetUsersResponse{
+				Users: &[]api.User{apiUser},
+				Total: pint64(1),
+				Page:  pint64(1),
+				Limit: 10,
+			},
+			expectedTotal: "1",
+			expectedLink:  firstAndLast,
+		},
+		{
+			name: "manager cannot get users of another country",
 			params: api.GetUsersParams{
-				Page:  0,
+				Country: pstring("US"),
+				Limit:   10,
+			},
+			caller:         newClaims(hexID, "UK", permission.RoleManager),
+			expectedStatus: http.StatusForbidden,
+			expectedErr: api.Error{
+				Detail: errForbidden,
+			},
+		},
+		{
+			name:           "user cannot get users",
+			params:         api.GetUsersParams{Limit: 10},
+			caller:         newClaims(hexID, "UK", permission.RoleUser),
+			expectedStatus: http.StatusForbidden,
+			expectedErr: api.Error{
+				Detail: errForbidden,
+			},
+		},
+		{
+			name:           "admin can get deleted users",
+			params:         api.GetUsersParams{Limit: 10, IncludeDeleted: pbool(true)},
+			mockResponses:  []bson.D{deletedUser},
+			total:          1,
+			expectedStatus: http.StatusOK,
+			expectedResponse: api.GetUsersResponse{
+				Users: &[]api.User{apiDeletedUser},
+				Total: pint64(1),
+				Page:  pint64(1),
 				Limit: 10,
 			},
-			mockResponses: []bson.D{
-				mtest.CreateSuccessResponse(bson.D{
-					{"_id", hexID},
-					{"first_name", "john"},
-					{"last_name", "doe"},
-					{"nickname", "jd"},
-					{"email", "jd@jd@mensah.com.com"},
-					{"country", "UK"},
-					{"created_at", createdAt},
-					{"updated_at", updatedAt},
-				}...),
+			expectedTotal: "1",
+			expectedLink:  firstAndLast,
+		},
+		{
+			name:           "manager cannot get deleted users",
+			params:         api.GetUsersParams{Limit: 10, IncludeDeleted: pbool(true)},
+			caller:         newClaims(hexID, "UK", permission.RoleManager),
+			expectedStatus: http.StatusForbidden,
+			expectedErr: api.Error{
+				Detail: errForbidden,
 			},
+		},
+		{
+			name:           "links to the next and previous pages",
+			params:         api.GetUsersParams{Page: &skip, Limit: 10},
+			mockResponses:  []bson.D{user, user, user, user, user, user, user, user, user, user, user},
+			total:          45,
 			expectedStatus: http.StatusOK,
 			expectedResponse: api.GetUsersResponse{
-				Users: &[]api.User{
-					{
-						Id:        hexID,
-						FirstName: "john",
-						LastName:  "doe",
-						Nickname:  "jd",
-						Email:     "jd@jd@mensah.com.com",
-						Country:   "UK",
-						CreatedAt: createdAt,
-						UpdatedAt: updatedAt,
-					},
-				},
+				Users:   &[]api.User{apiUser, apiUser, apiUser, apiUser, apiUser, apiUser, apiUser, apiUser, apiUser, apiUser},
+				Total:   pint64(45),
+				Page:    pint64(3),
+				Limit:   10,
+				HasMore: true,
+				Next:    &next,
+				Prev:    &prev,
+			},
+			expectedTotal: "45",
+			expectedLink:  firstAndLast + `, </users?cursor=` + next + `>; rel="next", </users?cursor=` + prev + `>; rel="prev"`,
+		},
+		{
+			name:           "gets the page after a cursor without counting users",
+			params:         api.GetUsersParams{Limit: 10, Cursor: &next, Total: pbool(false)},
+			mockResponses:  []bson.D{user},
+			expectedStatus: http.StatusOK,
+			expectedResponse: api.GetUsersResponse{
+				Users: &[]api.User{apiUser},
+				Limit: 10,
+				Prev:  &prev,
 			},
+			expectedLink: firstAndLast + `, </users?cursor=` + prev + `>; rel="prev"`,
 		},
 		{
-			name: "error getting users",
+			name: "filters and sorts users",
 			params: api.GetUsersParams{
-				Page:  0,
+				Limit:  10,
+				Filter: &[]string{"nickname:prefix:j", "created_at:gte:2020-01-01T00:00:00Z"},
+				Sort:   pstring("nickname"),
+				Total:  pbool(false),
+			},
+			mockResponses:  []bson.D{user},
+			expectedStatus: http.StatusOK,
+			expectedResponse: api.GetUsersResponse{
+				Users: &[]api.User{apiUser},
+				Page:  pint64(1),
 				Limit: 10,
 			},
+			expectedLink: `</users>; rel="first", </users?cursor=` + nicknameLast + `>; rel="last"`,
+		},
+		{
+			name:           "gets the requested fields of users",
+			params:         api.GetUsersParams{Limit: 10, Fields: &[]string{"_id", "nickname"}, Total: pbool(false)},
+			mockResponses:  []bson.D{user},
+			expectedStatus: http.StatusOK,
+			expectedFields: []map[string]interface{}{{"_id": hexID, "nickname": "jd"}},
+			expectedLink:   firstAndLast,
+		},
+		{
+			name:           "unknown filter field",
+			params:         api.GetUsersParams{Limit: 10, Filter: &[]string{"password:eq:secret"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errInvalidQuery,
+				Errors: &[]api.FieldError{{Parameter: pstring(query.ParamFilter), Reason: `unknown field "password"`}},
+			},
+		},
+		{
+			name:           "unsortable field",
+			params:         api.GetUsersParams{Limit: 10, Sort: pstring("-roles")},
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errInvalidQuery,
+				Errors: &[]api.FieldError{{Parameter: pstring(query.ParamSort), Reason: `field "roles" is not sortable`}},
+			},
+		},
+		{
+			name:           "cursor issued for another sort",
+			params:         api.GetUsersParams{Limit: 10, Cursor: &next, Sort: pstring("nickname")},
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errInvalidCursor,
+			},
+		},
+		{
+			name:           "invalid cursor",
+			params:         api.GetUsersParams{Limit: 10, Cursor: pstring(next + "x")},
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errInvalidCursor,
+			},
+		},
+		{
+			name:             "error getting users",
+			params:           api.GetUsersParams{Limit: 10},
 			expectedStatus:   http.StatusInternalServerError,
 			expectedResponse: api.GetUsersResponse{},
 			expectedErr: api.Error{
-				Message: errGetUsers,
+				Detail: errGetUsers,
 			},
 			mockError: true,
 		},
@@ -146,32 +326,240 @@ func TestService_GetUsers(t *testing.T) {
 				}
 
 				r = append(r, mtest.CreateCursorResponse(0, "foo.bar", mtest.NextBatch))
+				r = append(r, mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{{"n", tt.total}}))
 				mt.AddMockResponses(r...)
 			}
 
 			repo := mongoRepo.New(mt.DB)
-			h := &Handler{repo}
+			h := New(repo, nil, nil, testPasswords, newPasswordPolicy(t), newCursorCodec(t), nil, &config.Config{})
 
 			ctx, response := setUpRequest(echo.GET, "/users", "")
+			authenticate(ctx, tt.caller)
 
 			err := h.GetUsers(ctx, tt.params)
 			require.NoError(t, err)
 
 			assert.Equal(t, tt.expectedStatus, response.Code)
 
-			if tt.expectedErr.Message != "" {
-				var responseBody api.Error
+			if tt.expectedErr.Detail != "" {
+				assertProblem(t, response, tt.expectedErr)
+			} else if tt.expectedFields != nil {
+				var responseBody struct {
+					Users []map[string]interface{} `json:"users"`
+					Limit int64                    `json:"limit"`
+				}
+				require.NoError(t, json.Unmarshal(response.Body.Bytes(), &responseBody))
+
+				assert.Equal(t, tt.expectedFields, responseBody.Users)
+				assert.Equal(t, tt.params.Limit, responseBody.Limit)
+				assert.Equal(t, tt.expectedLink, response.Header().Get(headerLink))
+			} else {
+				var responseBody api.GetUsersResponse
 				err = json.Unmarshal(response.Body.Bytes(), &responseBody)
 				require.NoError(t, err)
 
-				assert.Equal(t, tt.expectedErr, responseBody)
+				assert.Equal(t, tt.expectedResponse, responseBody)
+				assert.Equal(t, tt.expectedTotal, response.Header().Get(headerTotalCount))
+				assert.Equal(t, tt.expectedLink, response.Header().Get(headerLink))
+			}
+		})
+	}
+}
+
+func TestHandler_GetUser(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	user := mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{
+		{"_id", hexID},
+		{"first_name", "john"},
+		{"last_name", "doe"},
+		{"nickname", "jd"},
+		{"email", "jd@jd@mensah.com.com"},
+		{"country", "UK"},
+		{"created_at", createdAt},
+		{"updated_at", updatedAt},
+		{"version", 3},
+	})
+
+	tests := []struct {
+		name             string
+		id               string
+		params           api.GetUserParams
+		mockResponse     bson.D
+		expectedStatus   int
+		expectedResponse api.User
+		expectedFields   map[string]interface{}
+		expectedETag     string
+		expectedErr      api.Error
+		caller           *auth.Claims
+	}{
+		{
+			name:           "can get user",
+			id:             hexID,
+			mockResponse:   user,
+			expectedStatus: http.StatusOK,
+			expectedResponse: api.User{
+				Id:        hexID,
+				FirstName: "john",
+				LastName:  "doe",
+				Nickname:  "jd",
+				Email:     "jd@jd@mensah.com.com",
+				Country:   "UK",
+				CreatedAt: createdAt,
+				UpdatedAt: updatedAt,
+				Version:   3,
+			},
+			expectedETag: `"3"`,
+		},
+		{
+			name:           "user can get themselves",
+			id:             hexID,
+			mockResponse:   user,
+			caller:         newClaims(hexID, "US", permission.RoleUser),
+			expectedStatus: http.StatusOK,
+			expectedResponse: api.User{
+				Id:        hexID,
+				FirstName: "john",
+				LastName:  "doe",
+				Nickname:  "jd",
+				Email:     "jd@jd@mensah.com.com",
+				Country:   "UK",
+				CreatedAt: createdAt,
+				UpdatedAt: updatedAt,
+				Version:   3,
+			},
+			expectedETag: `"3"`,
+		},
+		{
+			name:           "gets the requested fields of a user",
+			id:             hexID,
+			params:         api.GetUserParams{Fields: &[]string{"nickname", "country"}},
+			mockResponse:   user,
+			caller:         newClaims(primitive.NewObjectID().Hex(), "UK", permission.RoleManager),
+			expectedStatus: http.StatusOK,
+			expectedFields: map[string]interface{}{"nickname": "jd", "country": "UK"},
+			expectedETag:   `W/"3"`,
+		},
+		{
+			name:           "not modified since the version of the client",
+			id:             hexID,
+			params:         api.GetUserParams{IfNoneMatch: pstring(`"1", W/"3"`)},
+			mockResponse:   user,
+			expectedStatus: http.StatusNotModified,
+			expectedETag:   `"3"`,
+		},
+		{
+			name:           "modified since the version of the client",
+			id:             hexID,
+			params:         api.GetUserParams{IfNoneMatch: pstring(`"2"`)},
+			mockResponse:   user,
+			expectedStatus: http.StatusOK,
+			expectedResponse: api.User{
+				Id:        hexID,
+				FirstName: "john",
+				LastName:  "doe",
+				Nickname:  "jd",
+				Email:     "jd@jd@mensah.com.com",
+				Country:   "UK",
+				CreatedAt: createdAt,
+				UpdatedAt: updatedAt,
+				Version:   3,
+			},
+			expectedETag: `"3"`,
+		},
+		{
+			name:           "unknown field",
+			id:             hexID,
+			params:         api.GetUserParams{Fields: &[]string{"password"}},
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errInvalidQuery,
+				Errors: &[]api.FieldError{{Parameter: pstring(query.ParamFields), Reason: `unknown field "password"`}},
+			},
+		},
+		{
+			name:           "user cannot get another user, whether they exist or not",
+			id:             hexID,
+			caller:         newClaims(primitive.NewObjectID().Hex(), "UK", permission.RoleUser),
+			expectedStatus: http.StatusForbidden,
+			expectedErr: api.Error{
+				Detail: errForbidden,
+			},
+		},
+		{
+			name:           "manager does not find a user of another country",
+			id:             hexID,
+			mockResponse:   user,
+			caller:         newClaims(primitive.NewObjectID().Hex(), "US", permission.RoleManager),
+			expectedStatus: http.StatusNotFound,
+			expectedErr: api.Error{
+				Detail: errUserNotFound,
+			},
+		},
+		{
+			name:           "user not found",
+			id:             hexID,
+			mockResponse:   mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch),
+			expectedStatus: http.StatusNotFound,
+			expectedErr: api.Error{
+				Detail: errUserNotFound,
+			},
+		},
+		{
+			name:           "malformed id",
+			id:             "not-an-object-id",
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errInvalidID,
+			},
+		},
+		{
+			name:           "error getting user",
+			id:             hexID,
+			mockResponse:   bson.D{{"ok", 0}},
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr: api.Error{
+				Detail: errGetUser,
+			},
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			h := New(mongoRepo.New(mt.DB), nil, nil, testPasswords, newPasswordPolicy(t), newCursorCodec(t), nil, &config.Config{})
+
+			ctx, response := setUpRequest(echo.GET, "/users/:id", "")
+			authenticate(ctx, tt.caller)
+
+			err := h.GetUser(ctx, tt.id, tt.params)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+			assert.Equal(t, tt.expectedETag, response.Header().Get(headerETag))
+
+			if tt.expectedErr.Detail != "" {
+				assertProblem(t, response, tt.expectedErr)
+			} else if tt.expectedStatus == http.StatusNotModified {
+				assert.Empty(t, response.Body.Bytes())
+			} else if tt.expectedFields != nil {
+				var responseBody map[string]interface{}
+				require.NoError(t, json.Unmarshal(response.Body.Bytes(), &responseBody))
+				assert.Equal(t, tt.expectedFields, responseBody)
+
+				projection := mt.GetStartedEvent().Command.Lookup("projection").Document()
+				assert.Equal(t, int32(1), projection.Lookup("nickname").Int32())
+				assert.Equal(t, int32(1), projection.Lookup("country").Int32())
+				assert.Equal(t, int32(1), projection.Lookup("version").Int32())
 			} else {
-				var responseBody api.GetUsersResponse
+				var responseBody api.User
 				err = json.Unmarshal(response.Body.Bytes(), &responseBody)
 				require.NoError(t, err)
 
 				assert.Equal(t, tt.expectedResponse, responseBody)
-				assert.NoError(t, err)
 			}
 		})
 	}
@@ -184,14 +572,22 @@ func TestService_CreateUser(t *testing.T) {
 	tests := []struct {
 		name           string
 		body           string
-		mockResponse   bson.D
+		mockResponses  []bson.D
 		expectedStatus int
+		expectedMails  int
 		expectedErr    api.Error
 	}{
 		{
 			name:           "can create user",
 			body:           `{"first_name":"john","last_name":"doe","nickname":"jd","email":"jd@jd@mensah.com.com","password":"password","country":"UK"}`,
-			mockResponse:   bson.D{{"ok", 1}},
+			mockResponses:  []bson.D{{{"ok", 1}}, {{"ok", 1}}},
+			expectedStatus: http.StatusCreated,
+			expectedMails:  1,
+		},
+		{
+			name:           "creates user when the verification cannot be sent",
+			body:           `{"first_name":"john","last_name":"doe","nickname":"jd","email":"jd@jd@mensah.com.com","password":"password","country":"UK"}`,
+			mockResponses:  []bson.D{{{"ok", 1}}, {{"ok", 0}}},
 			expectedStatus: http.StatusCreated,
 		},
 		{
@@ -199,16 +595,40 @@ func TestService_CreateUser(t *testing.T) {
 			body:           `{"`,
 			expectedStatus: http.StatusBadRequest,
 			expectedErr: api.Error{
-				Message: errParseBody,
+				Detail: errParseBody,
+			},
+		},
+		{
+			name:           "password violates the policy",
+			body:           `{"first_name":"john","last_name":"doe","nickname":"johnny","email":"jd@jd@mensah.com.com","password":"Johnny-2020","country":"UK"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errWeakPassword,
+				Code:   pstring(codePasswordPolicy),
+				Violations: &[]api.PasswordViolation{
+					{Rule: auth.PasswordRulePersonalInfo, Message: "must not contain the email or nickname"},
+				},
+			},
+		},
+		{
+			name: "email already in use",
+			body: `{"first_name":"john","last_name":"doe","nickname":"jd","email":"jd@jd@mensah.com.com","password":"password","country":"UK"}`,
+			mockResponses: []bson.D{mtest.CreateWriteErrorsResponse(mtest.WriteError{
+				Code:    11000,
+				Message: `E11000 duplicate key error collection: test.users index: email_deleted_at_unique dup key: { email: "jd@jd@mensah.com.com" }`,
+			})},
+			expectedStatus: http.StatusConflict,
+			expectedErr: api.Error{
+				Detail: "user already exists with this email",
 			},
 		},
 		{
 			name:           "error creating user",
 			body:           `{"first_name":"john","last_name":"doe","nickname":"jd","email":"jd@jd@mensah.com.com","password":"password","country":"UK"}`,
-			mockResponse:   bson.D{{"ok", 0}},
+			mockResponses:  []bson.D{{{"ok", 0}}},
 			expectedStatus: http.StatusInternalServerError,
 			expectedErr: api.Error{
-				Message: errCreateUser,
+				Detail: errCreateUser,
 			},
 		},
 	}
@@ -216,10 +636,11 @@ func TestService_CreateUser(t *testing.T) {
 		mt.Run(tt.name, func(mt *mtest.T) {
 			defer teardown(mt)
 
-			mt.AddMockResponses(tt.mockResponse)
+			mt.AddMockResponses(tt.mockResponses...)
 
+			dir := t.TempDir()
 			repo := mongoRepo.New(mt.DB)
-			s := &Handler{repo}
+			s := New(repo, nil, nil, testPasswords, newPasswordPolicy(t), newCursorCodec(t), mail.NewFileSender(dir, "from@example.com"), &config.Config{})
 
 			ctx, response := setUpRequest(echo.POST, "/users", tt.body)
 
@@ -228,12 +649,12 @@ func TestService_CreateUser(t *testing.T) {
 
 			assert.Equal(t, tt.expectedStatus, response.Code)
 
-			if tt.expectedErr.Message != "" {
-				var responseBody api.Error
-				err = json.Unmarshal(response.Body.Bytes(), &responseBody)
-				require.NoError(t, err)
+			mails, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			assert.Len(t, mails, tt.expectedMails)
 
-				assert.Equal(t, tt.expectedErr, responseBody)
+			if tt.expectedErr.Detail != "" {
+				assertProblem(t, response, tt.expectedErr)
 			} else {
 				var responseBody api.CreateUserResponse
 				err = json.Unmarshal(response.Body.Bytes(), &responseBody)
@@ -241,6 +662,7 @@ func TestService_CreateUser(t *testing.T) {
 
 				assert.NotEmpty(t, responseBody.Id)
 				assert.NoError(t, err)
+				assert.Equal(t, `"1"`, response.Header().Get(headerETag))
 			}
 		})
 	}
@@ -250,32 +672,62 @@ func TestHandler_UpdateUser(t *testing.T) {
 	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
 	defer mt.Close()
 
+	credentials := mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{
+		{"_id", hexID},
+		{"email", "jd@jd@mensah.com.com"},
+		{"country", "UK"},
+	})
+	updated := bson.D{
+		{"ok", 1},
+		{"value", bson.D{
+			{"_id", hexID},
+			{"first_name", "john"},
+			{"last_name", "doe"},
+			{"nickname", "jd"},
+			{"email", "jd@jd@mensah.com.com"},
+			{"country", "UK"},
+			{"created_at", createdAt},
+			{"updated_at", updatedAt},
+			{"version", 4},
+		}},
+	}
+	body := `{"first_name":"john","last_name":"doe","nickname":"jd","email":"jd@jd@mensah.com.com","country":"UK"}`
+
 	tests := []struct {
 		name             string
 		id               string
 		body             string
-		mockResponse     bson.D
+		params           api.UpdateUserParams
+		mockResponses    []bson.D
 		expectedStatus   int
 		expectedResponse api.User
 		expectedErr      api.Error
+		caller           *auth.Claims
 	}{
 		{
-			name: "can update user",
-			id:   hexID,
-			body: `{"first_name":"john"}`,
-			mockResponse: bson.D{
-				{"ok", 1},
-				{"value", bson.D{
-					{"_id", hexID},
-					{"first_name", "john"},
-					{"last_name", "doe"},
-					{"nickname", "jd"},
-					{"email", "jd@jd@mensah.com.com"},
-					{"country", "UK"},
-					{"created_at", createdAt},
-					{"updated_at", updatedAt},
-				}},
+			name:           "can update user",
+			id:             hexID,
+			body:           body,
+			mockResponses:  []bson.D{credentials, updated},
+			expectedStatus: http.StatusOK,
+			expectedResponse: api.User{
+				Id:        hexID,
+				FirstName: "john",
+				LastName:  "doe",
+				Nickname:  "jd",
+				Email:     "jd@jd@mensah.com.com",
+				Country:   "UK",
+				CreatedAt: createdAt,
+				UpdatedAt: updatedAt,
+				Version:   4,
 			},
+		},
+		{
+			name:           "can update the version of the client",
+			id:             hexID,
+			body:           body,
+			params:         api.UpdateUserParams{IfMatch: pstring(`"3"`)},
+			mockResponses:  []bson.D{credentials, updated},
 			expectedStatus: http.StatusOK,
 			expectedResponse: api.User{
 				Id:        hexID,
@@ -286,6 +738,22 @@ func TestHandler_UpdateUser(t *testing.T) {
 				Country:   "UK",
 				CreatedAt: createdAt,
 				UpdatedAt: updatedAt,
+				Version:   4,
+			},
+		},
+		{
+			name:   "user changed since the version of the client",
+			id:     hexID,
+			body:   body,
+			params: api.UpdateUserParams{IfMatch: pstring(`"2"`)},
+			mockResponses: []bson.D{
+				credentials,
+				{{"ok", 1}, {"value", nil}},
+				mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{{"n", 1}}),
+			},
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedErr: api.Error{
+				Detail: errPreconditionFailed,
 			},
 		},
 		{
@@ -294,17 +762,98 @@ func TestHandler_UpdateUser(t *testing.T) {
 			body:           `{"`,
 			expectedStatus: http.StatusBadRequest,
 			expectedErr: api.Error{
-				Message: errParseBody,
+				Detail: errParseBody,
 			},
 		},
 		{
-			name:           "error updating user",
+			name:           "user can update themselves",
 			id:             hexID,
-			body:           `{"first_name":"john"}`,
-			mockResponse:   bson.D{{"ok", 0}},
+			body:           body,
+			mockResponses:  []bson.D{credentials, updated},
+			caller:         newClaims(hexID, "UK", permission.RoleUser),
+			expectedStatus: http.StatusOK,
+			expectedResponse: api.User{
+				Id:        hexID,
+				FirstName: "john",
+				LastName:  "doe",
+				Nickname:  "jd",
+				Email:     "jd@jd@mensah.com.com",
+				Country:   "UK",
+				CreatedAt: createdAt,
+				UpdatedAt: updatedAt,
+				Version:   4,
+			},
+		},
+		{
+			name:           "manager cannot move themselves to another country",
+			id:             hexID,
+			body:           strings.Replace(body, `"country":"UK"`, `"country":"FR"`, 1),
+			mockResponses:  []bson.D{credentials},
+			caller:         newClaims(hexID, "UK", permission.RoleManager),
+			expectedStatus: http.StatusForbidden,
+			expectedErr: api.Error{
+				Detail: errForbiddenCountry,
+			},
+		},
+		{
+			name:           "admin can move a user to another country",
+			id:             hexID,
+			body:           strings.Replace(body, `"country":"UK"`, `"country":"FR"`, 1),
+			mockResponses:  []bson.D{credentials, updated},
+			expectedStatus: http.StatusOK,
+			expectedResponse: api.User{
+				Id:        hexID,
+				FirstName: "john",
+				LastName:  "doe",
+				Nickname:  "jd",
+				Email:     "jd@jd@mensah.com.com",
+				Country:   "UK",
+				CreatedAt: createdAt,
+				UpdatedAt: updatedAt,
+				Version:   4,
+			},
+		},
+		{
+			name:           "user cannot update another user, whether they exist or not",
+			id:             hexID,
+			body:           body,
+			caller:         newClaims(primitive.NewObjectID().Hex(), "UK", permission.RoleUser),
+			expectedStatus: http.StatusForbidden,
+			expectedErr: api.Error{
+				Detail: errForbidden,
+			},
+		},
+		{
+			name:           "user not found",
+			id:             hexID,
+			body:           body,
+			mockResponses:  []bson.D{mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch)},
+			expectedStatus: http.StatusNotFound,
+			expectedErr: api.Error{
+				Detail: errUserNotFound,
+			},
+		},
+		{
+			name: "nickname already in use",
+			id:   hexID,
+			body: body,
+			mockResponses: []bson.D{credentials, mtest.CreateCommandErrorResponse(mtest.CommandError{
+				Code:    11000,
+				Message: `E11000 duplicate key error collection: test.users index: nickname_deleted_at_unique dup key: { nickname: "jd" }`,
+			})},
+			expectedStatus: http.StatusConflict,
+			expectedErr: api.Error{
+				Detail: "user already exists with this nickname",
+			},
+		},
+		{
+			name:           "error updating user",
+			id:             hexID,
+			body:           body,
+			mockResponses:  []bson.D{credentials, {{"ok", 0}}},
 			expectedStatus: http.StatusInternalServerError,
 			expectedErr: api.Error{
-				Message: errUpdateUser,
+				Detail: errUpdateUser,
 			},
 		},
 	}
@@ -312,31 +861,239 @@ func TestHandler_UpdateUser(t *testing.T) {
 		mt.Run(tt.name, func(mt *mtest.T) {
 			defer teardown(mt)
 
-			mt.AddMockResponses(tt.mockResponse)
+			mt.AddMockResponses(tt.mockResponses...)
 
 			repo := mongoRepo.New(mt.DB)
-			s := &Handler{repo}
+			s := New(repo, nil, nil, testPasswords, newPasswordPolicy(t), newCursorCodec(t), nil, &config.Config{})
 
 			ctx, response := setUpRequest(echo.PUT, "/users/:hexID", tt.body)
+			authenticate(ctx, tt.caller)
 
-			err := s.UpdateUser(ctx, tt.id)
+			err := s.UpdateUser(ctx, tt.id, tt.params)
 			require.NoError(t, err)
 
 			assert.Equal(t, tt.expectedStatus, response.Code)
 
-			if tt.expectedErr.Message != "" {
-				var responseBody api.Error
-				err = json.Unmarshal(response.Body.Bytes(), &responseBody)
-				require.NoError(t, err)
-
-				assert.Equal(t, tt.expectedErr, responseBody)
+			if tt.expectedErr.Detail != "" {
+				assertProblem(t, response, tt.expectedErr)
 			} else {
 				var responseBody api.User
 				err = json.Unmarshal(response.Body.Bytes(), &responseBody)
 				require.NoError(t, err)
 
 				assert.Equal(t, tt.expectedResponse, responseBody)
-				assert.NoError(t, err)
+				assert.Equal(t, `"4"`, response.Header().Get(headerETag))
+			}
+		})
+	}
+}
+
+func TestHandler_UpdateUser_Email(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	credentials := mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{
+		{"_id", hexID},
+		{"email", "jd@jd@mensah.com.com"},
+		{"email_verified", true},
+	})
+	updated := bson.D{
+		{"ok", 1},
+		{"value", bson.D{{"_id", hexID}}},
+	}
+
+	tests := []struct {
+		name                 string
+		body                 string
+		requireVerifiedEmail bool
+		mockResponses        []bson.D
+		expectedStatus       int
+		expectedMails        int
+		expectedErr          api.Error
+	}{
+		{
+			name:           "sends verification when the email changes",
+			body:           `{"first_name":"john","last_name":"doe","nickname":"jd","email":"new@mensah.com","country":"UK"}`,
+			mockResponses:  []bson.D{credentials, updated, {{"ok", 1}}},
+			expectedStatus: http.StatusOK,
+			expectedMails:  1,
+		},
+		{
+			name:           "keeps verification when the email is unchanged",
+			body:           `{"first_name":"john","last_name":"doe","nickname":"jd","email":"jd@jd@mensah.com.com","country":"UK"}`,
+			mockResponses:  []bson.D{credentials, updated},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:                 "caller without a verified email cannot change the email",
+			body:                 `{"first_name":"john","last_name":"doe","nickname":"jd","email":"new@mensah.com","country":"UK"}`,
+			requireVerifiedEmail: true,
+			mockResponses:        []bson.D{credentials},
+			expectedStatus:       http.StatusForbidden,
+			expectedErr: api.Error{
+				Detail: errEmailNotVerified,
+			},
+		},
+		{
+			name:                 "caller without a verified email can update the profile",
+			body:                 `{"first_name":"johnny","last_name":"doe","nickname":"jd","email":"jd@jd@mensah.com.com","country":"UK"}`,
+			requireVerifiedEmail: true,
+			mockResponses:        []bson.D{credentials, updated},
+			expectedStatus:       http.StatusOK,
+		},
+		{
+			name:           "error finding user",
+			body:           `{"first_name":"john","last_name":"doe","nickname":"jd","email":"new@mensah.com","country":"UK"}`,
+			mockResponses:  []bson.D{{{"ok", 0}}},
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr: api.Error{
+				Detail: errUpdateUser,
+			},
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponses...)
+
+			dir := t.TempDir()
+			cfg := &config.Config{RequireVerifiedEmail: tt.requireVerifiedEmail}
+			s := New(mongoRepo.New(mt.DB), nil, nil, testPasswords, newPasswordPolicy(t), newCursorCodec(t), mail.NewFileSender(dir, "from@example.com"), cfg)
+
+			ctx, response := setUpRequest(echo.PUT, "/users/:hexID", tt.body)
+			authenticate(ctx, nil)
+
+			err := s.UpdateUser(ctx, hexID, api.UpdateUserParams{})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+
+			mails, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			assert.Len(t, mails, tt.expectedMails)
+
+			if tt.expectedErr.Detail != "" {
+				assertProblem(t, response, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestHandler_UpdateUser_Password(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	credentials := mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{
+		{"_id", hexID},
+		{"email", "jd@jd@mensah.com.com"},
+		{"nickname", "johnny"},
+	})
+	replacement := func(nickname, password string) string {
+		return fmt.Sprintf(
+			`{"first_name":"john","last_name":"doe","nickname":%q,"email":"jd@jd@mensah.com.com","country":"UK","password":%q}`,
+			nickname, password,
+		)
+	}
+	updated := bson.D{
+		{"ok", 1},
+		{"value", bson.D{{"_id", hexID}}},
+	}
+
+	revoked := bson.D{{"ok", 1}, {"n", 1}, {"nModified", 1}}
+
+	tests := []struct {
+		name            string
+		body            string
+		mockResponses   []bson.D
+		expectedStatus  int
+		expectedRevoked bool
+		expectedErr     api.Error
+	}{
+		{
+			name:            "changes password and revokes refresh tokens",
+			body:            replacement("johnny", "correct-Horse-battery-1"),
+			mockResponses:   []bson.D{credentials, updated, revoked},
+			expectedStatus:  http.StatusOK,
+			expectedRevoked: true,
+		},
+		{
+			name:           "keeps refresh tokens without a new password",
+			body:           replacement("johnny", ""),
+			mockResponses:  []bson.D{credentials, updated},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:            "error revoking refresh tokens",
+			body:            replacement("johnny", "correct-Horse-battery-1"),
+			mockResponses:   []bson.D{credentials, updated, {{"ok", 0}}},
+			expectedStatus:  http.StatusInternalServerError,
+			expectedRevoked: true,
+			expectedErr: api.Error{
+				Detail: errUpdateUser,
+			},
+		},
+		{
+			name:           "password contains the current nickname",
+			body:           replacement("johnny", "Johnny-2020"),
+			mockResponses:  []bson.D{credentials},
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errWeakPassword,
+				Code:   pstring(codePasswordPolicy),
+				Violations: &[]api.PasswordViolation{
+					{Rule: auth.PasswordRulePersonalInfo, Message: "must not contain the email or nickname"},
+				},
+			},
+		},
+		{
+			name:           "password contains the new nickname",
+			body:           replacement("bobby", "Bobby-2020"),
+			mockResponses:  []bson.D{credentials},
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errWeakPassword,
+				Code:   pstring(codePasswordPolicy),
+				Violations: &[]api.PasswordViolation{
+					{Rule: auth.PasswordRulePersonalInfo, Message: "must not contain the email or nickname"},
+				},
+			},
+		},
+		{
+			name:           "error finding user",
+			body:           replacement("johnny", "correct-Horse-battery-1"),
+			mockResponses:  []bson.D{{{"ok", 0}}},
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr: api.Error{
+				Detail: errUpdateUser,
+			},
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponses...)
+
+			s := New(mongoRepo.New(mt.DB), nil, nil, testPasswords, newPasswordPolicy(t), newCursorCodec(t), nil, &config.Config{})
+
+			ctx, response := setUpRequest(echo.PUT, "/users/:hexID", tt.body)
+			authenticate(ctx, nil)
+
+			err := s.UpdateUser(ctx, hexID, api.UpdateUserParams{})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+
+			var revokedTokens bool
+			for _, started := range mt.GetAllStartedEvents() {
+				collection, _ := started.Command.Lookup("update").StringValueOK()
+				revokedTokens = revokedTokens || collection == "refresh_tokens"
+			}
+			assert.Equal(t, tt.expectedRevoked, revokedTokens)
+
+			if tt.expectedErr.Detail != "" {
+				assertProblem(t, response, tt.expectedErr)
 			}
 		})
 	}
@@ -349,14 +1106,17 @@ func TestHandler_DeleteUser(t *testing.T) {
 	tests := []struct {
 		name           string
 		id             string
-		mockResponse   bson.D
+		params         api.DeleteUserParams
+		requireIfMatch bool
+		mockResponses  []bson.D
 		expectedStatus int
 		expectedErr    api.Error
+		caller         *auth.Claims
 	}{
 		{
 			name: "can delete user",
 			id:   hexID,
-			mockResponse: bson.D{
+			mockResponses: []bson.D{{
 				{"ok", 1},
 				{"value", bson.D{
 					{"_id", hexID},
@@ -369,16 +1129,306 @@ func TestHandler_DeleteUser(t *testing.T) {
 					{"created_at", createdAt},
 					{"updated_at", updatedAt},
 				}},
-			},
+			}},
 			expectedStatus: http.StatusNoContent,
 		},
+		{
+			name:           "user changed since the version of the client",
+			id:             hexID,
+			params:         api.DeleteUserParams{IfMatch: pstring(`"2"`)},
+			mockResponses:  []bson.D{{{"ok", 1}, {"value", nil}}, mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{{"n", 1}})},
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedErr: api.Error{
+				Detail: errPreconditionFailed,
+			},
+		},
+		{
+			name:           "weak etags never match",
+			id:             hexID,
+			params:         api.DeleteUserParams{IfMatch: pstring(`W/"2"`)},
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedErr: api.Error{
+				Detail: errPreconditionFailed,
+			},
+		},
+		{
+			name:           "If-Match is required",
+			id:             hexID,
+			requireIfMatch: true,
+			expectedStatus: http.StatusPreconditionRequired,
+			expectedErr: api.Error{
+				Detail: errPreconditionRequired,
+			},
+		},
+		{
+			name:           "manager cannot delete another user",
+			id:             hexID,
+			caller:         newClaims(primitive.NewObjectID().Hex(), "UK", permission.RoleManager),
+			expectedStatus: http.StatusForbidden,
+			expectedErr: api.Error{
+				Detail: errForbidden,
+			},
+		},
+		{
+			name:           "user not found",
+			id:             hexID,
+			mockResponses:  []bson.D{{{"ok", 1}, {"value", nil}}},
+			expectedStatus: http.StatusNotFound,
+			expectedErr: api.Error{
+				Detail: errUserNotFound,
+			},
+		},
+		{
+			name:           "malformed id",
+			id:             "not-an-object-id",
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errInvalidID,
+			},
+		},
 		{
 			name:           "error deleting user",
 			id:             hexID,
+			mockResponses:  []bson.D{{{"ok", 0}}},
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr: api.Error{
+				Detail: errDeleteUser,
+			},
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponses...)
+
+			repo := mongoRepo.New(mt.DB)
+			s := New(repo, nil, nil, testPasswords, newPasswordPolicy(t), newCursorCodec(t), nil, &config.Config{RequireIfMatch: tt.requireIfMatch})
+
+			ctx, response := setUpRequest(echo.PUT, "/users/:hexID", "")
+			authenticate(ctx, tt.caller)
+
+			err := s.DeleteUser(ctx, tt.id, tt.params)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+
+			if tt.expectedErr.Detail != "" {
+				assertProblem(t, response, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestHandler_RestoreUser(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tests := []struct {
+		name           string
+		mockResponse   bson.D
+		caller         *auth.Claims
+		expectedStatus int
+		expectedErr    api.Error
+	}{
+		{
+			name:           "admin can restore user",
+			mockResponse:   bson.D{{"ok", 1}, {"value", bson.D{{"_id", hexID}, {"nickname", "jd"}, {"version", 5}}}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "user cannot restore themselves",
+			caller:         newClaims(hexID, "UK", permission.RoleUser),
+			expectedStatus: http.StatusForbidden,
+			expectedErr: api.Error{
+				Detail: errForbidden,
+			},
+		},
+		{
+			name:           "user is not deleted",
+			mockResponse:   bson.D{{"ok", 1}, {"value", nil}},
+			expectedStatus: http.StatusNotFound,
+			expectedErr: api.Error{
+				Detail: errUserNotFound,
+			},
+		},
+		{
+			name: "email taken since the user was deleted",
+			mockResponse: mtest.CreateCommandErrorResponse(mtest.CommandError{
+				Code:    11000,
+				Message: `E11000 duplicate key error collection: test.users index: email_deleted_at_unique dup key`,
+			}),
+			expectedStatus: http.StatusConflict,
+			expectedErr: api.Error{
+				Detail: errUserExists + " with this email",
+			},
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			h := New(mongoRepo.New(mt.DB), nil, nil, testPasswords, newPasswordPolicy(t), newCursorCodec(t), nil, &config.Config{})
+
+			ctx, response := setUpRequest(echo.POST, "/users/"+hexID+"/restore", "")
+			authenticate(ctx, tt.caller)
+
+			err := h.RestoreUser(ctx, hexID)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+
+			if tt.expectedErr.Detail != "" {
+				assertProblem(t, response, tt.expectedErr)
+				return
+			}
+
+			var responseBody api.User
+			require.NoError(t, json.Unmarshal(response.Body.Bytes(), &responseBody))
+			assert.Equal(t, hexID, responseBody.Id)
+			assert.Equal(t, `"5"`, response.Header().Get(headerETag))
+		})
+	}
+}
+
+func TestRenderRepositoryError(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedErr    api.Error
+	}{
+		{
+			name:           "invalid id",
+			err:            fmt.Errorf("failed: %w", repository.ErrInvalidID),
+			expectedStatus: http.StatusBadRequest,
+			expectedErr:    api.Error{Detail: errInvalidID},
+		},
+		{
+			name:           "not found",
+			err:            fmt.Errorf("failed: %w", repository.ErrNotFound),
+			expectedStatus: http.StatusNotFound,
+			expectedErr:    api.Error{Detail: errUserNotFound},
+		},
+		{
+			name:           "conflict",
+			err:            fmt.Errorf("failed: %w", repository.ErrConflict),
+			expectedStatus: http.StatusConflict,
+			expectedErr:    api.Error{Detail: errUserExists},
+		},
+		{
+			name:           "unavailable",
+			err:            fmt.Errorf("failed: %w", repository.ErrUnavailable),
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedErr:    api.Error{Detail: errUnavailable},
+		},
+		{
+			name:           "unexpected error",
+			err:            errors.New("boom"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr:    api.Error{Detail: errUpdateUser},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, response := setUpRequest(echo.PUT, "/users/:hexID", "")
+
+			err := renderRepositoryError(ctx, tt.err, errUpdateUser)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+
+			assertProblem(t, response, tt.expectedErr)
+		})
+	}
+}
+
+func TestHandler_SetUserRoles(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tests := []struct {
+		name             string
+		id               string
+		body             string
+		mockResponse     bson.D
+		caller           *auth.Claims
+		expectedStatus   int
+		expectedResponse api.User
+		expectedErr      api.Error
+	}{
+		{
+			name: "admin can set user roles",
+			id:   hexID,
+			body: `{"roles":["manager"]}`,
+			mockResponse: bson.D{
+				{"ok", 1},
+				{"value", bson.D{
+					{"_id", hexID},
+					{"first_name", "john"},
+					{"last_name", "doe"},
+					{"nickname", "jd"},
+					{"email", "jd@jd@mensah.com.com"},
+					{"country", "UK"},
+					{"created_at", createdAt},
+					{"updated_at", updatedAt},
+					{"roles", bson.A{"manager"}},
+				}},
+			},
+			expectedStatus: http.StatusOK,
+			expectedResponse: api.User{
+				Id:        hexID,
+				FirstName: "john",
+				LastName:  "doe",
+				Nickname:  "jd",
+				Email:     "jd@jd@mensah.com.com",
+				Country:   "UK",
+				CreatedAt: createdAt,
+				UpdatedAt: updatedAt,
+				Roles:     &api.Roles{permission.RoleManager},
+			},
+		},
+		{
+			name:           "manager cannot set user roles",
+			id:             hexID,
+			body:           `{"roles":["admin"]}`,
+			caller:         newClaims(hexID, "UK", permission.RoleManager),
+			expectedStatus: http.StatusForbidden,
+			expectedErr: api.Error{
+				Detail: errForbidden,
+			},
+		},
+		{
+			name:           "invalid request",
+			id:             hexID,
+			body:           `{"`,
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errParseBody,
+			},
+		},
+		{
+			name:           "unknown role",
+			id:             hexID,
+			body:           `{"roles":["user","support-agent"]}`,
+			mockResponse:   mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch),
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errUnknownRole + ": support-agent",
+			},
+		},
+		{
+			name:           "error setting user roles",
+			id:             hexID,
+			body:           `{"roles":["manager"]}`,
 			mockResponse:   bson.D{{"ok", 0}},
 			expectedStatus: http.StatusInternalServerError,
 			expectedErr: api.Error{
-				Message: errDeleteUser,
+				Detail: errSetRoles,
 			},
 		},
 	}
@@ -389,23 +1439,24 @@ func TestHandler_DeleteUser(t *testing.T) {
 			mt.AddMockResponses(tt.mockResponse)
 
 			repo := mongoRepo.New(mt.DB)
-			s := &Handler{repo}
+			h := New(repo, nil, nil, testPasswords, newPasswordPolicy(t), newCursorCodec(t), nil, &config.Config{})
 
-			ctx, response := setUpRequest(echo.PUT, "/users/:hexID", "")
+			ctx, response := setUpRequest(echo.PUT, "/users/:hexID/roles", tt.body)
+			authenticate(ctx, tt.caller)
 
-			err := s.DeleteUser(ctx, tt.id)
+			err := h.SetUserRoles(ctx, tt.id)
 			require.NoError(t, err)
 
 			assert.Equal(t, tt.expectedStatus, response.Code)
 
-			if tt.expectedErr.Message != "" {
-				var responseBody api.Error
+			if tt.expectedErr.Detail != "" {
+				assertProblem(t, response, tt.expectedErr)
+			} else {
+				var responseBody api.User
 				err = json.Unmarshal(response.Body.Bytes(), &responseBody)
 				require.NoError(t, err)
 
-				assert.Equal(t, tt.expectedErr, responseBody)
-			} else {
-				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedResponse, responseBody)
 			}
 		})
 	}
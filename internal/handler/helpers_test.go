@@ -1,12 +1,135 @@
 package handler
 
 import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/auth"
+	"github.com/danielMensah/user-management/internal/config"
+	"github.com/danielMensah/user-management/internal/pagination"
+	"github.com/danielMensah/user-management/internal/permission"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+	"golang.org/x/crypto/bcrypt"
 )
 
+// testPasswords hashes passwords with the lowest bcrypt cost to keep tests fast
+var testPasswords = auth.NewBcryptHasher(bcrypt.MinCost)
+
 func teardown(mt *mtest.T) {
 	mt.ClearMockResponses()
 	mt.ClearCollections()
 	mt.ClearFailPoints()
 	mt.ClearEvents()
 }
+
+func newTokenManager(t *testing.T) *auth.TokenManager {
+	t.Helper()
+
+	tokens, err := auth.New(&config.Config{
+		JWTAlgorithm:    "HS256",
+		JWTSecret:       "secret",
+		JWTIssuer:       "user-management",
+		JWTAudience:     "user-management",
+		AccessTokenTTL:  15 * time.Minute,
+		RefreshTokenTTL: time.Hour,
+		MFAChallengeTTL: 5 * time.Minute,
+	})
+	require.NoError(t, err)
+
+	return tokens
+}
+
+func newLockoutConfig() *config.Config {
+	return &config.Config{
+		LoginMaxAttempts:        3,
+		LoginAttemptWindow:      15 * time.Minute,
+		LoginLockoutDuration:    5 * time.Minute,
+		LoginMaxLockoutDuration: time.Hour,
+	}
+}
+
+// newPasswordPolicy only bounds the length of passwords so that tests unrelated to the policy can use any password
+func newPasswordPolicy(t *testing.T) *auth.PasswordPolicy {
+	t.Helper()
+
+	policy, err := auth.NewPasswordPolicy(&config.Config{PasswordMinLength: 1, PasswordMaxLength: 72})
+	require.NoError(t, err)
+
+	return policy
+}
+
+// newCursorCodec signs the cursors of user pages with a fixed secret
+func newCursorCodec(t *testing.T) *pagination.CursorCodec {
+	t.Helper()
+
+	cursors, err := pagination.NewCursorCodec(&config.Config{CursorSecret: "secret"})
+	require.NoError(t, err)
+
+	return cursors
+}
+
+func newTOTP(t *testing.T) *auth.TOTP {
+	t.Helper()
+
+	totp, err := auth.NewTOTP(&config.Config{
+		MFAEncryptionKey: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=",
+		MFAIssuer:        "user-management",
+	})
+	require.NoError(t, err)
+
+	return totp
+}
+
+// authenticate stores the claims of the caller in the echo context, defaulting to an admin
+func authenticate(ctx echo.Context, claims *auth.Claims) {
+	if claims == nil {
+		claims = newClaims(primitive.NewObjectID().Hex(), "", permission.RoleAdmin)
+	}
+
+	ctx.Set(auth.ClaimsContextKey, claims)
+}
+
+func newClaims(id, country string, roles ...string) *auth.Claims {
+	claims := &auth.Claims{Country: country, Roles: roles}
+	claims.Subject = id
+
+	return claims
+}
+
+func pstring(s string) *string {
+	return &s
+}
+
+func pint64(i int64) *int64 {
+	return &i
+}
+
+func pbool(b bool) *bool {
+	return &b
+}
+
+// assertProblem asserts that the response renders the expected problem details. The type, title and status are
+// checked against the status code of the response, so expected problems only need their detail and extensions.
+func assertProblem(t *testing.T, response *httptest.ResponseRecorder, expected api.Error) {
+	t.Helper()
+
+	assert.Equal(t, mimeProblemJSON, response.Header().Get(echo.HeaderContentType))
+
+	var responseBody api.Error
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &responseBody))
+	assert.NotNil(t, responseBody.Instance)
+	responseBody.Instance = nil
+
+	expected.Type = problemTypeBlank
+	expected.Title = http.StatusText(response.Code)
+	expected.Status = response.Code
+	assert.Equal(t, expected, responseBody)
+}
@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/auth"
+	"github.com/danielMensah/user-management/internal/importer"
+	"github.com/danielMensah/user-management/internal/permission"
+	"github.com/danielMensah/user-management/internal/repository"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	errImportUsers      = "failed to import users"
+	errUnsupportedFile  = "unsupported import media type"
+	errInvalidFile      = "invalid import file"
+	errTooManyRows      = "too many rows to import"
+	errInvalidImportRow = "user does not match the user schema"
+
+	// importBodyLimit is the largest file that can be imported, leaving room for MaxRows rows of long fields
+	importBodyLimit = "1M"
+
+	// schemaUserCreateData is the schema of the api specification imported users must match
+	schemaUserCreateData = "UserCreateData"
+)
+
+func init() {
+	// the request validator only decodes the bodies of media types it knows about
+	for _, mediaType := range []string{importer.MIMECSV, importer.MIMENDJSON} {
+		openapi3filter.RegisterBodyDecoder(mediaType, decodeImportFile)
+	}
+}
+
+// ImportUsers creates the users of a CSV or NDJSON file and reports the outcome of each row. Rows are validated like
+// the users created one at a time, and the rows that fail do not stop the others from being imported. A dry run only
+// validates the rows. Imported users are not sent a verification email.
+func (h *Handler) ImportUsers(ctx echo.Context, params api.ImportUsersParams) error {
+	if httpErr := h.requireSensitivePermission(ctx, permission.UsersImport); httpErr != nil {
+		return renderError(ctx, httpErr)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(ctx.Request().Header.Get(echo.HeaderContentType))
+	rows, err := importer.Read(mediaType, ctx.Request().Body)
+	if err != nil {
+		return renderImportError(ctx, err)
+	}
+
+	dryRun := params.DryRun != nil && *params.DryRun
+	report := api.ImportReport{DryRun: dryRun, Total: int64(len(rows)), Rows: make([]api.ImportRowResult, len(rows))}
+
+	// users holds the valid users to create, and imported the index of the row of each of them
+	users := make([]*api.UserCreateData, 0, len(rows))
+	imported := make([]int, 0, len(rows))
+	for i, row := range rows {
+		user, result := h.importRow(row, dryRun)
+		report.Rows[i] = result
+		if user != nil {
+			users = append(users, user)
+			imported = append(imported, i)
+		}
+	}
+
+	if !dryRun && len(users) > 0 {
+		created, err := h.repo.CreateUsers(ctx.Request().Context(), users)
+		if err != nil {
+			return renderServerError(ctx, err, errImportUsers)
+		}
+
+		for j, user := range created {
+			report.Rows[imported[j]] = createdRow(report.Rows[imported[j]].Row, user)
+		}
+	}
+
+	for _, result := range report.Rows {
+		if result.Status == api.Failed {
+			report.Failed++
+		}
+	}
+	report.Succeeded = report.Total - report.Failed
+
+	return ctx.JSON(http.StatusOK, report)
+}
+
+// ImportBodyLimit rejects the files imported through the api served at baseURL that are larger than importBodyLimit
+// with 413, before they are read
+func ImportBodyLimit(baseURL string) echo.MiddlewareFunc {
+	return middleware.BodyLimitWithConfig(middleware.BodyLimitConfig{
+		Skipper: func(ctx echo.Context) bool {
+			return ctx.Path() != baseURL+"/users/import"
+		},
+		Limit: importBodyLimit,
+	})
+}
+
+// importRow validates a row of an import file and returns the user to create from it, with the password hashed unless
+// it already is a bcrypt hash or the import is a dry run. Rows that cannot be imported have no user and a failed
+// result.
+func (h *Handler) importRow(row importer.Row, dryRun bool) (*api.UserCreateData, api.ImportRowResult) {
+	result := api.ImportRowResult{Row: int64(row.Number), Status: api.Valid}
+	if row.Err != nil {
+		return nil, failedRow(result.Row, row.Err.Error())
+	}
+
+	user, err := importedUser(row.Fields)
+	if err != nil {
+		result = failedRow(result.Row, errInvalidImportRow)
+		result.Errors = fieldErrors(err)
+		return nil, result
+	}
+
+	if auth.IsBcryptHash(user.Password) {
+		return user, result
+	}
+
+	violations, err := h.policy.Check(user.Password, string(user.Email), user.Nickname)
+	if err != nil {
+		logrus.WithError(err).Error(errCheckPassword)
+		return nil, failedRow(result.Row, errCheckPassword)
+	}
+	if len(violations) > 0 {
+		result = failedRow(result.Row, errWeakPassword)
+		result.Violations = passwordViolations(violations)
+		return nil, result
+	}
+
+	if dryRun {
+		return user, result
+	}
+
+	if user.Password, err = h.passwords.Hash(user.Password); err != nil {
+		logrus.WithError(err).Error(errImportUsers)
+		return nil, failedRow(result.Row, errEncryptPwd)
+	}
+
+	return user, result
+}
+
+// importedUser returns the user of the fields of a row once they are checked against the api specification
+func importedUser(fields map[string]interface{}) (*api.UserCreateData, error) {
+	schema, err := apiSchema(schemaUserCreateData)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = schema.VisitJSON(fields, openapi3.MultiErrors()); err != nil {
+		return nil, err
+	}
+
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	user := new(api.UserCreateData)
+	if err = json.Unmarshal(encoded, user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// createdRow returns the result of a row once its user was created, or could not be
+func createdRow(number int64, user repository.CreatedUser) api.ImportRowResult {
+	if user.Err == nil {
+		return api.ImportRowResult{Row: number, Status: api.Created, Id: &user.ID}
+	}
+
+	switch {
+	case errors.Is(user.Err, repository.ErrConflict):
+		return failedRow(number, conflictMessage(user.Err))
+	case errors.Is(user.Err, repository.ErrUnavailable):
+		return failedRow(number, errUnavailable)
+	default:
+		logrus.WithError(user.Err).Error(errCreateUser)
+		return failedRow(number, errCreateUser)
+	}
+}
+
+func failedRow(number int64, detail string) api.ImportRowResult {
+	return api.ImportRowResult{Row: number, Status: api.Failed, Detail: &detail}
+}
+
+// renderImportError renders the error of an import file that cannot be read at all
+func renderImportError(ctx echo.Context, err error) error {
+	switch {
+	case errors.Is(err, importer.ErrUnsupportedMediaType):
+		return renderProblem(ctx, http.StatusUnsupportedMediaType, errUnsupportedFile)
+	case errors.Is(err, importer.ErrTooManyRows):
+		return renderProblem(ctx, http.StatusRequestEntityTooLarge, errTooManyRows)
+	case errors.Is(err, importer.ErrInvalidFile):
+		logrus.WithError(err).Debug(errInvalidFile)
+		return renderProblem(ctx, http.StatusBadRequest, errInvalidFile)
+	default:
+		return renderServerError(ctx, err, errImportUsers)
+	}
+}
+
+// decodeImportFile decodes the body of an import file for the request validator, leaving its rows to the handler
+func decodeImportFile(body io.Reader, _ http.Header, _ *openapi3.SchemaRef, _ openapi3filter.EncodingFn) (interface{}, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, &openapi3filter.ParseError{Kind: openapi3filter.KindInvalidFormat, Cause: err}
+	}
+
+	return string(data), nil
+}
@@ -0,0 +1,239 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/auth"
+	"github.com/danielMensah/user-management/internal/config"
+	"github.com/danielMensah/user-management/internal/importer"
+	"github.com/danielMensah/user-management/internal/permission"
+	mongoRepo "github.com/danielMensah/user-management/internal/repository/mongo"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestHandler_ImportUsers(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	bcryptHash := "$2a$10$.vGA1O9wmRjrwAVXD98HNOgsNpDczlqm3Jq7KnEd1rVAGv3Fykk1a"
+	csvFile := "first_name,last_name,nickname,email,password,country\n" +
+		"john,doe,jd,jd@mensah.com,correct-Horse-battery-1,UK\n" +
+		"jane,doe,jane," + "jane@mensah.com," + bcryptHash + ",US\n" +
+		"jim,doe,jim,not-an-email,correct-Horse-battery-1,UK\n" +
+		"joe,doe,joe,joe@mensah.com," + strings.Repeat("a", 73) + ",UK\n"
+	ndjsonFile := `{"first_name":"john","last_name":"doe","nickname":"jd","email":"jd@mensah.com","password":"pw","country":"UK"}
+{"first_name":"jane","last_name":"doe","nickname":"jane","email":"jd@mensah.com","password":"pw","country":"US"}
+`
+
+	tests := []struct {
+		name             string
+		contentType      string
+		file             string
+		dryRun           bool
+		caller           *auth.Claims
+		mockResponses    []bson.D
+		expectedStatus   int
+		expectedErr      api.Error
+		expectedStatuses []api.ImportRowResultStatus
+		expectedDetails  map[int]string
+		expectedInserted int
+	}{
+		{
+			name:             "imports the valid rows of a csv file",
+			contentType:      importer.MIMECSV + "; charset=utf-8",
+			file:             csvFile,
+			mockResponses:    []bson.D{{{"ok", 1}, {"n", 2}}},
+			expectedStatus:   http.StatusOK,
+			expectedStatuses: []api.ImportRowResultStatus{api.Created, api.Created, api.Failed, api.Failed},
+			expectedDetails:  map[int]string{2: errInvalidImportRow, 3: errWeakPassword},
+			expectedInserted: 2,
+		},
+		{
+			name:        "rows conflicting with existing users",
+			contentType: importer.MIMENDJSON,
+			file:        ndjsonFile,
+			mockResponses: []bson.D{mtest.CreateWriteErrorsResponse(mtest.WriteError{
+				Index:   1,
+				Code:    11000,
+				Message: `E11000 duplicate key error collection: test.users index: email_deleted_at_unique dup key`,
+			})},
+			expectedStatus:   http.StatusOK,
+			expectedStatuses: []api.ImportRowResultStatus{api.Created, api.Failed},
+			expectedDetails:  map[int]string{1: errUserExists + " with this email"},
+			expectedInserted: 2,
+		},
+		{
+			name:             "dry run validates the rows without creating users",
+			contentType:      importer.MIMECSV,
+			file:             csvFile,
+			dryRun:           true,
+			expectedStatus:   http.StatusOK,
+			expectedStatuses: []api.ImportRowResultStatus{api.Valid, api.Valid, api.Failed, api.Failed},
+			expectedDetails:  map[int]string{2: errInvalidImportRow, 3: errWeakPassword},
+		},
+		{
+			name:           "user cannot import users",
+			contentType:    importer.MIMECSV,
+			file:           csvFile,
+			caller:         newClaims(hexID, "UK", permission.RoleUser),
+			expectedStatus: http.StatusForbidden,
+			expectedErr:    api.Error{Detail: errForbidden},
+		},
+		{
+			name:           "unsupported media type",
+			contentType:    echo.MIMEApplicationJSON,
+			file:           "[]",
+			expectedStatus: http.StatusUnsupportedMediaType,
+			expectedErr:    api.Error{Detail: errUnsupportedFile},
+		},
+		{
+			name:           "csv file without header",
+			contentType:    importer.MIMECSV,
+			expectedStatus: http.StatusBadRequest,
+			expectedErr:    api.Error{Detail: errInvalidFile},
+		},
+		{
+			name:           "too many rows",
+			contentType:    importer.MIMECSV,
+			file:           "first_name\n" + strings.Repeat("john\n", importer.MaxRows+1),
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedErr:    api.Error{Detail: errTooManyRows},
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponses...)
+
+			h := New(mongoRepo.New(mt.DB), nil, nil, testPasswords, newPasswordPolicy(t), newCursorCodec(t), nil, &config.Config{})
+
+			ctx, response := setUpRequest(echo.POST, "/users/import", tt.file)
+			ctx.Request().Header.Set(echo.HeaderContentType, tt.contentType)
+			authenticate(ctx, tt.caller)
+
+			err := h.ImportUsers(ctx, api.ImportUsersParams{DryRun: pbool(tt.dryRun)})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+
+			if tt.expectedErr.Detail != "" {
+				assertProblem(t, response, tt.expectedErr)
+				return
+			}
+
+			var report api.ImportReport
+			require.NoError(t, json.Unmarshal(response.Body.Bytes(), &report))
+			assert.Equal(t, tt.dryRun, report.DryRun)
+			assert.Equal(t, int64(len(tt.expectedStatuses)), report.Total)
+			assert.Equal(t, report.Total, report.Succeeded+report.Failed)
+			assert.Equal(t, int64(len(tt.expectedDetails)), report.Failed)
+
+			require.Len(t, report.Rows, len(tt.expectedStatuses))
+			for i, row := range report.Rows {
+				assert.Equal(t, int64(i+1), row.Row)
+				assert.Equal(t, tt.expectedStatuses[i], row.Status)
+				assert.Equal(t, row.Status == api.Created, row.Id != nil)
+				if detail, ok := tt.expectedDetails[i]; ok {
+					require.NotNil(t, row.Detail)
+					assert.Equal(t, detail, *row.Detail)
+				}
+			}
+
+			if tt.dryRun {
+				assert.Nil(t, mt.GetStartedEvent())
+				return
+			}
+
+			documents, err := mt.GetStartedEvent().Command.Lookup("documents").Array().Values()
+			require.NoError(t, err)
+			require.Len(t, documents, tt.expectedInserted)
+			assert.True(t, auth.IsBcryptHash(documents[0].Document().Lookup("password").StringValue()))
+			assert.Equal(t, *report.Rows[0].Id, documents[0].Document().Lookup("_id").ObjectID().Hex())
+		})
+	}
+}
+
+func TestHandler_importRow(t *testing.T) {
+	h := New(nil, nil, nil, testPasswords, newPasswordPolicy(t), newCursorCodec(t), nil, &config.Config{})
+
+	fields := map[string]interface{}{
+		"first_name": "john",
+		"last_name":  "doe",
+		"nickname":   "jd",
+		"email":      "jd@mensah.com",
+		"country":    "UK",
+	}
+
+	user, result := h.importRow(importer.Row{Number: 3, Fields: fields}, false)
+	assert.Nil(t, user)
+	assert.Equal(t, api.Failed, result.Status)
+	require.NotNil(t, result.Errors)
+	assert.Equal(t, []api.FieldError{{Pointer: pstring("/password"), Reason: `property "password" is missing`}}, *result.Errors)
+
+	fields["password"] = "correct-Horse-battery-1"
+	user, result = h.importRow(importer.Row{Number: 3, Fields: fields}, true)
+	require.NotNil(t, user)
+	assert.Equal(t, api.ImportRowResult{Row: 3, Status: api.Valid}, result)
+	assert.Equal(t, "correct-Horse-battery-1", user.Password)
+
+	user, _ = h.importRow(importer.Row{Number: 3, Fields: fields}, false)
+	require.NotNil(t, user)
+	matches, err := testPasswords.Verify(user.Password, "correct-Horse-battery-1")
+	require.NoError(t, err)
+	assert.True(t, matches)
+}
+
+func TestImportBodyLimit(t *testing.T) {
+	largeFile := "first_name\n" + strings.Repeat("john\n", 1<<18)
+
+	tests := []struct {
+		name           string
+		path           string
+		file           string
+		expectedStatus int
+	}{
+		{
+			name:           "file over the limit",
+			path:           "/api/v1/users/import",
+			file:           largeFile,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:           "file under the limit",
+			path:           "/api/v1/users/import",
+			file:           "first_name\n" + strings.Repeat("john\n", importer.MaxRows),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "operations that are not limited",
+			path:           "/api/v1/users",
+			file:           largeFile,
+			expectedStatus: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := echo.New()
+			router.HTTPErrorHandler = HTTPErrorHandler
+			router.POST(tt.path, func(ctx echo.Context) error { return ctx.NoContent(http.StatusOK) }, ImportBodyLimit("/api/v1"))
+
+			request := httptest.NewRequest(echo.POST, tt.path, strings.NewReader(tt.file))
+			request.Header.Set(echo.HeaderContentType, importer.MIMECSV)
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+		})
+	}
+}
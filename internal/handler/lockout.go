@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/danielMensah/user-management/internal/config"
+	"github.com/danielMensah/user-management/internal/permission"
+	"github.com/danielMensah/user-management/internal/repository"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+const (
+	errAccountLocked     = "account temporarily locked after too many failed logins"
+	errTooManyRequests   = "too many requests, try again later"
+	errRecordFailedLogin = "failed to record failed login"
+	errResetFailedLogins = "failed to reset failed logins"
+	errUnlockUser        = "failed to unlock user"
+	errUserNotFound      = "user not found"
+
+	codeAccountLocked = "account_locked"
+	codeRateLimited   = "rate_limited"
+)
+
+// UnlockUser lifts the lockout of a user and resets their failed logins
+func (h *Handler) UnlockUser(ctx echo.Context, id string) error {
+	if httpErr := h.requireSensitivePermission(ctx, permission.UsersUnlock); httpErr != nil {
+		return renderError(ctx, httpErr)
+	}
+
+	if err := h.repo.UnlockUser(ctx.Request().Context(), id); err != nil {
+		return renderRepositoryError(ctx, err, errUnlockUser)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// LoginRateLimiter limits how many login attempts a single IP address can make to the api served at baseURL, along
+// with how many password reset and verification emails it can have sent. IP addresses are the ones of the router's
+// IPExtractor.
+func LoginRateLimiter(cfg *config.Config, baseURL string) echo.MiddlewareFunc {
+	limited := map[string]bool{
+		baseURL + "/auth/login":              true,
+		baseURL + "/auth/mfa/verify":         true,
+		baseURL + "/auth/password/forgot":    true,
+		baseURL + "/auth/email/verification": true,
+	}
+
+	return middleware.RateLimiterWithConfig(middleware.RateLimiterConfig{
+		Skipper: func(ctx echo.Context) bool {
+			return !limited[ctx.Path()]
+		},
+		Store: middleware.NewRateLimiterMemoryStoreWithConfig(middleware.RateLimiterMemoryStoreConfig{
+			Rate:      rate.Limit(float64(cfg.LoginRateLimit) / time.Minute.Seconds()),
+			Burst:     cfg.LoginRateBurst,
+			ExpiresIn: time.Minute,
+		}),
+		DenyHandler: func(ctx echo.Context, identifier string, err error) error {
+			return writeProblem(ctx, problemWithCode(http.StatusTooManyRequests, errTooManyRequests, codeRateLimited))
+		},
+	})
+}
+
+// IPExtractor returns how the IP address of clients is found. Without trusted proxies it is the address of the
+// connection, so that clients cannot pick their IP address with headers. Behind trusted proxies it is the address
+// X-Forwarded-For gives right before the first untrusted one.
+func IPExtractor(cfg *config.Config) (echo.IPExtractor, error) {
+	if len(cfg.TrustedProxies) == 0 {
+		return echo.ExtractIPDirect(), nil
+	}
+
+	options := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, proxy := range cfg.TrustedProxies {
+		_, ipRange, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		options = append(options, echo.TrustIPRange(ipRange))
+	}
+
+	return echo.ExtractIPFromXFFHeader(options...), nil
+}
+
+// lockedUntil returns when the lockout of the user ends, if they are locked out
+func lockedUntil(creds *repository.Credentials) (time.Time, bool) {
+	if creds.Lockout.LockedUntil == nil || !time.Now().Before(*creds.Lockout.LockedUntil) {
+		return time.Time{}, false
+	}
+
+	return *creds.Lockout.LockedUntil, true
+}
+
+// accountLocked responds that the account is locked until the given time
+func accountLocked(ctx echo.Context, until time.Time) error {
+	retryAfter := int(time.Until(until).Round(time.Second).Seconds())
+	ctx.Response().Header().Set("Retry-After", strconv.Itoa(retryAfter))
+
+	return writeProblem(ctx, problemWithCode(http.StatusLocked, errAccountLocked, codeAccountLocked))
+}
+
+// failedLogin records a failed login of the user and responds with the given error, or that the account is locked
+// when the failure locked it
+func (h *Handler) failedLogin(ctx echo.Context, creds *repository.Credentials, message string) error {
+	until, locked, err := h.recordFailedLogin(ctx.Request().Context(), creds)
+	if err != nil {
+		return renderServerError(ctx, err, errRecordFailedLogin)
+	}
+
+	if locked {
+		return accountLocked(ctx, until)
+	}
+
+	return renderProblem(ctx, http.StatusUnauthorized, message)
+}
+
+// recordFailedLogin counts a failed login of the user and locks them out once they reach the maximum number of
+// attempts. Every lockout lasts twice as long as the previous one, up to the maximum lockout duration.
+func (h *Handler) recordFailedLogin(ctx context.Context, creds *repository.Credentials) (time.Time, bool, error) {
+	now := time.Now().UTC()
+
+	attempts, err := h.repo.RecordFailedLogin(ctx, creds.ID, now.Add(-h.cfg.LoginAttemptWindow))
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if attempts < h.cfg.LoginMaxAttempts {
+		return time.Time{}, false, nil
+	}
+
+	until := now.Add(lockoutDuration(h.cfg.LoginLockoutDuration, h.cfg.LoginMaxLockoutDuration, creds.Lockout.Lockouts))
+	if err = h.repo.LockUser(ctx, creds.ID, until); err != nil {
+		return time.Time{}, false, err
+	}
+
+	logrus.WithField("user_id", creds.ID).WithField("locked_until", until).Warn(errAccountLocked)
+
+	return until, true, nil
+}
+
+// resetFailedLogins forgets the failed logins of a user after they logged in. Failures are logged rather than
+// returned as they must not prevent the login.
+func (h *Handler) resetFailedLogins(ctx context.Context, creds *repository.Credentials) {
+	if creds.Lockout.FailedAttempts == 0 && creds.Lockout.Lockouts == 0 {
+		return
+	}
+
+	if err := h.repo.UnlockUser(ctx, creds.ID); err != nil {
+		logrus.WithError(err).WithField("user_id", creds.ID).Error(errResetFailedLogins)
+	}
+}
+
+// lockoutDuration doubles the base duration for every previous lockout, up to the maximum duration
+func lockoutDuration(base, max time.Duration, lockouts int) time.Duration {
+	duration := base
+	for i := 0; i < lockouts && duration < max; i++ {
+		duration *= 2
+	}
+
+	if duration > max {
+		return max
+	}
+
+	return duration
+}
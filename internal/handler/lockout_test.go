@@ -0,0 +1,167 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/auth"
+	"github.com/danielMensah/user-management/internal/config"
+	"github.com/danielMensah/user-management/internal/permission"
+	mongoRepo "github.com/danielMensah/user-management/internal/repository/mongo"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestHandler_UnlockUser(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tests := []struct {
+		name           string
+		mockResponse   bson.D
+		caller         *auth.Claims
+		expectedStatus int
+		expectedErr    api.Error
+	}{
+		{
+			name:           "admin can unlock user",
+			mockResponse:   bson.D{{"ok", 1}, {"n", 1}, {"nModified", 1}},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "user cannot unlock user",
+			caller:         newClaims(hexID, "UK", permission.RoleUser),
+			expectedStatus: http.StatusForbidden,
+			expectedErr: api.Error{
+				Detail: errForbidden,
+			},
+		},
+		{
+			name:           "user not found or deleted",
+			mockResponse:   bson.D{{"ok", 1}, {"n", 0}, {"nModified", 0}},
+			expectedStatus: http.StatusNotFound,
+			expectedErr: api.Error{
+				Detail: errUserNotFound,
+			},
+		},
+		{
+			name:           "error unlocking user",
+			mockResponse:   bson.D{{"ok", 0}},
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr: api.Error{
+				Detail: errUnlockUser,
+			},
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			h := New(mongoRepo.New(mt.DB), nil, nil, testPasswords, newPasswordPolicy(t), newCursorCodec(t), nil, &config.Config{})
+
+			ctx, response := setUpRequest(echo.POST, "/users/"+hexID+"/unlock", "")
+			authenticate(ctx, tt.caller)
+
+			err := h.UnlockUser(ctx, hexID)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+
+			if tt.expectedErr.Detail != "" {
+				assertProblem(t, response, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestLoginRateLimiter(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		trustedProxies []string
+		expectedStatus int
+	}{
+		{
+			name:           "login attempts of an ip address spoofing its forwarded address",
+			path:           "/api/v1/auth/login",
+			expectedStatus: http.StatusTooManyRequests,
+		},
+		{
+			name:           "password reset emails",
+			path:           "/api/v1/auth/password/forgot",
+			expectedStatus: http.StatusTooManyRequests,
+		},
+		{
+			name:           "verification emails",
+			path:           "/api/v1/auth/email/verification",
+			expectedStatus: http.StatusTooManyRequests,
+		},
+		{
+			name:           "clients forwarded by a trusted proxy",
+			path:           "/api/v1/auth/login",
+			trustedProxies: []string{"192.0.2.0/24"},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "operations that are not limited",
+			path:           "/api/v1/users",
+			expectedStatus: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{LoginRateLimit: 1, LoginRateBurst: 2, TrustedProxies: tt.trustedProxies}
+
+			extractor, err := IPExtractor(cfg)
+			require.NoError(t, err)
+
+			router := echo.New()
+			router.IPExtractor = extractor
+			router.POST(tt.path, func(ctx echo.Context) error { return ctx.NoContent(http.StatusOK) }, LoginRateLimiter(cfg, "/api/v1"))
+
+			send := func(forwardedFor string) *httptest.ResponseRecorder {
+				request := httptest.NewRequest(echo.POST, tt.path, nil)
+				request.RemoteAddr = "192.0.2.1:1234"
+				request.Header.Set(echo.HeaderXForwardedFor, forwardedFor)
+				request.Header.Set(echo.HeaderXRealIP, forwardedFor)
+				response := httptest.NewRecorder()
+				router.ServeHTTP(response, request)
+
+				return response
+			}
+
+			for i := 0; i < 2; i++ {
+				assert.Equal(t, http.StatusOK, send(fmt.Sprintf("203.0.113.%d", i)).Code)
+			}
+
+			response := send("203.0.113.2")
+			assert.Equal(t, tt.expectedStatus, response.Code)
+
+			if tt.expectedStatus == http.StatusTooManyRequests {
+				assertProblem(t, response, problemWithCode(http.StatusTooManyRequests, errTooManyRequests, codeRateLimited))
+			}
+		})
+	}
+}
+
+func TestIPExtractor(t *testing.T) {
+	_, err := IPExtractor(&config.Config{TrustedProxies: []string{"not-a-cidr"}})
+	assert.Error(t, err)
+}
+
+func TestLockoutDuration(t *testing.T) {
+	assert.Equal(t, 5*time.Minute, lockoutDuration(5*time.Minute, time.Hour, 0))
+	assert.Equal(t, 10*time.Minute, lockoutDuration(5*time.Minute, time.Hour, 1))
+	assert.Equal(t, 40*time.Minute, lockoutDuration(5*time.Minute, time.Hour, 3))
+	assert.Equal(t, time.Hour, lockoutDuration(5*time.Minute, time.Hour, 4))
+	assert.Equal(t, time.Hour, lockoutDuration(5*time.Minute, time.Hour, 1000))
+}
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"regexp"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// colon matches the colon custom methods are written after, as it is or percent-encoded
+const colon = `(?::|%3[aA])`
+
+// CustomMethods rewrites the paths of the custom methods of users served at baseURL, such as
+// POST /users/{id}:restore, POST /users:import and GET /users:export, to the paths they are routed to, such as
+// /users/{id}/restore. Echo reads a colon in a route as the start of a path param, so the routes cannot have one.
+// It must run before routing.
+func CustomMethods(baseURL string) echo.MiddlewareFunc {
+	users := regexp.QuoteMeta(baseURL + "/users")
+
+	return middleware.RewriteWithConfig(middleware.RewriteConfig{
+		RegexRules: map[*regexp.Regexp]string{
+			regexp.MustCompile(`^` + users + `/([^/?:%]+)` + colon + `restore(\?.*)?$`): baseURL + "/users/$1/restore$2",
+			regexp.MustCompile(`^` + users + colon + `(import|export)(\?.*)?$`):         baseURL + "/users/$1$2",
+		},
+	})
+}
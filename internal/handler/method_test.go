@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCustomMethods(t *testing.T) {
+	router := echo.New()
+	router.Pre(CustomMethods("/api/v1"))
+
+	echoRoute := func(ctx echo.Context) error {
+		return ctx.String(http.StatusOK, ctx.Path()+" "+ctx.Param("id")+" "+ctx.QueryString())
+	}
+	router.POST("/api/v1/users/:id/restore", echoRoute)
+	router.POST("/api/v1/users/import", echoRoute)
+	router.GET("/api/v1/users/export", echoRoute)
+
+	tests := []struct {
+		name           string
+		method         string
+		target         string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "restores a user",
+			method:         echo.POST,
+			target:         "/api/v1/users/" + hexID + ":restore",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "/api/v1/users/:id/restore " + hexID + " ",
+		},
+		{
+			name:           "imports users with query params",
+			method:         echo.POST,
+			target:         "/api/v1/users:import?dry_run=true",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "/api/v1/users/import  dry_run=true",
+		},
+		{
+			name:           "exports users with a percent-encoded colon",
+			method:         echo.GET,
+			target:         "/api/v1/users%3Aexport",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "/api/v1/users/export  ",
+		},
+		{
+			name:           "keeps the paths the methods are routed to",
+			method:         echo.POST,
+			target:         "/api/v1/users/" + hexID + "/restore",
+			expectedStatus: http.StatusOK,
+			expectedBody:   "/api/v1/users/:id/restore " + hexID + " ",
+		},
+		{
+			name:           "unknown custom method",
+			method:         echo.POST,
+			target:         "/api/v1/users:purge",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, httptest.NewRequest(tt.method, tt.target, nil))
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, response.Body.String())
+			}
+		})
+	}
+}
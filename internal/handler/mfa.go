@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/auth"
+	"github.com/danielMensah/user-management/internal/repository"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	errVerifyMFA         = "failed to verify mfa code"
+	errInvalidMFAToken   = "invalid or expired mfa token"
+	errInvalidMFACode    = "invalid mfa code"
+	errMFACodeRequired   = "either code or recovery_code is required"
+	errEnrollMFA         = "failed to enroll mfa"
+	errConfirmMFA        = "failed to confirm mfa enrollment"
+	errNoPendingMFA      = "no pending mfa enrollment"
+	errMFAAlreadyEnabled = "mfa already enabled"
+	errMFANotEnabled     = "mfa not enabled"
+	errGenerateRecovery  = "failed to generate recovery codes"
+	errMFAChallengeIssue = "failed to issue mfa challenge"
+)
+
+// VerifyMfa exchanges an mfa challenge token and a totp or recovery code for an access and refresh token
+func (h *Handler) VerifyMfa(ctx echo.Context) error {
+	body := new(api.VerifyMfaRequest)
+	if err := ctx.Bind(body); err != nil {
+		logrus.WithError(err).Error(errParseBody)
+		return renderProblem(ctx, http.StatusBadRequest, errParseBody)
+	}
+
+	if body.Code == nil && body.RecoveryCode == nil {
+		return renderProblem(ctx, http.StatusBadRequest, errMFACodeRequired)
+	}
+
+	userID, err := h.tokens.ParseMFAToken(body.MfaToken)
+	if err != nil {
+		return renderProblem(ctx, http.StatusUnauthorized, errInvalidMFAToken)
+	}
+
+	reqCtx := ctx.Request().Context()
+
+	creds, err := h.repo.GetCredentialsByID(reqCtx, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return renderProblem(ctx, http.StatusUnauthorized, errInvalidMFAToken)
+		}
+
+		return renderServerError(ctx, err, errVerifyMFA)
+	}
+
+	if !creds.MFA.Enabled {
+		return renderProblem(ctx, http.StatusUnauthorized, errInvalidMFAToken)
+	}
+
+	if until, locked := lockedUntil(creds); locked {
+		return accountLocked(ctx, until)
+	}
+
+	if body.RecoveryCode != nil {
+		recoveryCode := auth.HashOpaqueToken(normalizeRecoveryCode(*body.RecoveryCode))
+		err = h.repo.UseMFARecoveryCode(reqCtx, creds.ID, recoveryCode)
+	} else {
+		err = h.useTOTPCode(reqCtx, creds, *body.Code)
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return h.failedLogin(ctx, creds, errInvalidMFACode)
+		}
+
+		return renderServerError(ctx, err, errVerifyMFA)
+	}
+
+	tokens, err := h.issueTokens(reqCtx, creds, "")
+	if err != nil {
+		return renderServerError(ctx, err, errIssueToken)
+	}
+
+	h.resetFailedLogins(reqCtx, creds)
+
+	return ctx.JSON(http.StatusOK, tokens)
+}
+
+// EnrollTotp generates a new totp secret for the caller. It only becomes active once confirmed.
+func (h *Handler) EnrollTotp(ctx echo.Context) error {
+	claims, ok := auth.ClaimsFromContext(ctx)
+	if !ok {
+		return renderProblem(ctx, http.StatusUnauthorized, errUnauthenticated)
+	}
+
+	creds, httpErr := h.callerCredentials(ctx, claims, errEnrollMFA)
+	if httpErr != nil {
+		return renderError(ctx, httpErr)
+	}
+
+	if creds.MFA.Enabled {
+		return renderProblem(ctx, http.StatusConflict, errMFAAlreadyEnabled)
+	}
+
+	enrollment, err := h.totp.Enroll(creds.Email)
+	if err != nil {
+		return renderServerError(ctx, err, errEnrollMFA)
+	}
+
+	if err = h.repo.SetPendingMFASecret(ctx.Request().Context(), creds.ID, enrollment.EncryptedSecret); err != nil {
+		return renderServerError(ctx, err, errEnrollMFA)
+	}
+
+	return ctx.JSON(http.StatusOK, api.EnrollTotpResponse{Secret: enrollment.Secret, OtpauthUri: enrollment.URI})
+}
+
+// ConfirmTotp enables mfa for the caller once they present a code generated from their pending secret and
+// returns their recovery codes
+func (h *Handler) ConfirmTotp(ctx echo.Context) error {
+	claims, ok := auth.ClaimsFromContext(ctx)
+	if !ok {
+		return renderProblem(ctx, http.StatusUnauthorized, errUnauthenticated)
+	}
+
+	body := new(api.ConfirmTotpRequest)
+	if err := ctx.Bind(body); err != nil {
+		logrus.WithError(err).Error(errParseBody)
+		return renderProblem(ctx, http.StatusBadRequest, errParseBody)
+	}
+
+	creds, httpErr := h.callerCredentials(ctx, claims, errConfirmMFA)
+	if httpErr != nil {
+		return renderError(ctx, httpErr)
+	}
+
+	if creds.MFA.PendingSecret == "" {
+		return renderProblem(ctx, http.StatusBadRequest, errNoPendingMFA)
+	}
+
+	step, valid, err := h.totp.Validate(creds.MFA.PendingSecret, body.Code)
+	if err != nil {
+		return renderServerError(ctx, err, errConfirmMFA)
+	}
+	if !valid {
+		return renderProblem(ctx, http.StatusBadRequest, errInvalidMFACode)
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return renderServerError(ctx, err, errGenerateRecovery)
+	}
+
+	err = h.repo.EnableMFA(ctx.Request().Context(), creds.ID, creds.MFA.PendingSecret, step, hashes)
+	if err != nil {
+		// another enrollment replaced the pending secret in the meantime
+		if errors.Is(err, repository.ErrNotFound) {
+			return renderProblem(ctx, http.StatusBadRequest, errNoPendingMFA)
+		}
+
+		return renderServerError(ctx, err, errConfirmMFA)
+	}
+
+	return ctx.JSON(http.StatusOK, api.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the caller, invalidating the previous ones
+func (h *Handler) RegenerateRecoveryCodes(ctx echo.Context) error {
+	claims, ok := auth.ClaimsFromContext(ctx)
+	if !ok {
+		return renderProblem(ctx, http.StatusUnauthorized, errUnauthenticated)
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return renderServerError(ctx, err, errGenerateRecovery)
+	}
+
+	if err = h.repo.SetMFARecoveryCodes(ctx.Request().Context(), claims.Subject, hashes); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return renderProblem(ctx, http.StatusBadRequest, errMFANotEnabled)
+		}
+
+		return renderServerError(ctx, err, errGenerateRecovery)
+	}
+
+	return ctx.JSON(http.StatusOK, api.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// mfaChallenge issues a challenge token the user has to exchange along with a code for their tokens
+func (h *Handler) mfaChallenge(ctx echo.Context, creds *repository.Credentials) error {
+	token, err := h.tokens.IssueMFAToken(creds.ID)
+	if err != nil {
+		return renderServerError(ctx, err, errMFAChallengeIssue)
+	}
+
+	return ctx.JSON(http.StatusAccepted, api.MfaChallengeResponse{
+		MfaToken:  token,
+		ExpiresIn: int64(h.tokens.MFATTL().Seconds()),
+	})
+}
+
+// useTOTPCode validates a code against the secret of the user and records its time step so it cannot be
+// replayed. Invalid and replayed codes are reported as not found.
+func (h *Handler) useTOTPCode(ctx context.Context, creds *repository.Credentials, code string) error {
+	step, valid, err := h.totp.Validate(creds.MFA.Secret, strings.TrimSpace(code))
+	if err != nil {
+		return err
+	}
+	if !valid {
+		return repository.ErrNotFound
+	}
+
+	return h.repo.UseMFAStep(ctx, creds.ID, step)
+}
+
+// callerCredentials returns the credentials of the authenticated caller
+func (h *Handler) callerCredentials(ctx echo.Context, claims *auth.Claims, errMsg string) (*repository.Credentials, *echo.HTTPError) {
+	creds, err := h.repo.GetCredentialsByID(ctx.Request().Context(), claims.Subject)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, echo.NewHTTPError(http.StatusUnauthorized, errUnauthenticated)
+		}
+
+		return nil, serverError(err, errMsg)
+	}
+
+	return creds, nil
+}
+
+// newRecoveryCodes generates recovery codes along with the hashes to store
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := auth.NewRecoveryCodes()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = auth.HashOpaqueToken(code)
+	}
+
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.TrimSpace(code))
+}
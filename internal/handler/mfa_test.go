@@ -0,0 +1,419 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/auth"
+	"github.com/danielMensah/user-management/internal/config"
+	mongoRepo "github.com/danielMensah/user-management/internal/repository/mongo"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestHandler_Login_MFAChallenge(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	hash, err := testPasswords.Hash("password")
+	require.NoError(t, err)
+
+	mt.Run("users with mfa enabled get a challenge", func(mt *mtest.T) {
+		defer teardown(mt)
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{
+			{"_id", hexID},
+			{"email", "jd@jd@mensah.com.com"},
+			{"password", hash},
+			{"mfa", bson.D{{"enabled", true}, {"secret", "secret"}}},
+		}))
+
+		tokens := newTokenManager(t)
+		h := New(mongoRepo.New(mt.DB), tokens, newTOTP(t), testPasswords, newPasswordPolicy(t), newCursorCodec(t), nil, &config.Config{})
+
+		ctx, response := setUpRequest(echo.POST, "/auth/login", `{"email":"jd@jd@mensah.com.com","password":"password"}`)
+
+		err := h.Login(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, http.StatusAccepted, response.Code)
+
+		var responseBody api.MfaChallengeResponse
+		err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+		require.NoError(t, err)
+
+		assert.Equal(t, int64(300), responseBody.ExpiresIn)
+
+		userID, err := tokens.ParseMFAToken(responseBody.MfaToken)
+		require.NoError(t, err)
+		assert.Equal(t, hexID, userID)
+	})
+}
+
+func TestHandler_VerifyMfa(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tokens := newTokenManager(t)
+	totp := newTOTP(t)
+
+	mfaToken, err := tokens.IssueMFAToken(hexID)
+	require.NoError(t, err)
+
+	enrollment, err := totp.Enroll("jd@jd@mensah.com.com")
+	require.NoError(t, err)
+
+	credentials := func(enabled bool) bson.D {
+		return mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{
+			{"_id", hexID},
+			{"email", "jd@jd@mensah.com.com"},
+			{"mfa", bson.D{{"enabled", enabled}, {"secret", enrollment.EncryptedSecret}}},
+		})
+	}
+	request := func(fields string) string {
+		return `{"mfa_token":"` + mfaToken + `",` + fields + `}`
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		mockResponses  []bson.D
+		expectedStatus int
+		expectedErr    api.Error
+	}{
+		{
+			name: "can verify recovery code",
+			body: request(`"recovery_code":" ABCD-efgh "`),
+			mockResponses: []bson.D{
+				credentials(true),
+				{{"ok", 1}, {"n", 1}, {"nModified", 1}},
+				{{"ok", 1}},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "used recovery code",
+			body: request(`"recovery_code":"abcd-efgh"`),
+			mockResponses: []bson.D{
+				credentials(true),
+				{{"ok", 1}, {"n", 0}, {"nModified", 0}},
+				failedAttempts(1),
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedErr: api.Error{
+				Detail: errInvalidMFACode,
+			},
+		},
+		{
+			name: "wrong code",
+			body: request(`"code":"abcdef"`),
+			mockResponses: []bson.D{
+				credentials(true),
+				failedAttempts(1),
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedErr: api.Error{
+				Detail: errInvalidMFACode,
+			},
+		},
+		{
+			name: "too many wrong codes lock the account",
+			body: request(`"code":"abcdef"`),
+			mockResponses: []bson.D{
+				credentials(true),
+				failedAttempts(3),
+				{{"ok", 1}, {"n", 1}, {"nModified", 1}},
+			},
+			expectedStatus: http.StatusLocked,
+			expectedErr:    problemWithCode(http.StatusLocked, errAccountLocked, codeAccountLocked),
+		},
+		{
+			name:           "missing code",
+			body:           `{"mfa_token":"` + mfaToken + `"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errMFACodeRequired,
+			},
+		},
+		{
+			name:           "invalid mfa token",
+			body:           `{"mfa_token":"token","code":"123456"}`,
+			expectedStatus: http.StatusUnauthorized,
+			expectedErr: api.Error{
+				Detail: errInvalidMFAToken,
+			},
+		},
+		{
+			name: "mfa no longer enabled",
+			body: request(`"code":"123456"`),
+			mockResponses: []bson.D{
+				credentials(false),
+			},
+			expectedStatus: http.StatusUnauthorized,
+			expectedErr: api.Error{
+				Detail: errInvalidMFAToken,
+			},
+		},
+		{
+			name:           "error finding user",
+			body:           request(`"code":"123456"`),
+			mockResponses:  []bson.D{{{"ok", 0}}},
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr: api.Error{
+				Detail: errVerifyMFA,
+			},
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponses...)
+
+			h := New(mongoRepo.New(mt.DB), tokens, totp, testPasswords, newPasswordPolicy(t), newCursorCodec(t), nil, newLockoutConfig())
+
+			ctx, response := setUpRequest(echo.POST, "/auth/mfa/verify", tt.body)
+
+			err := h.VerifyMfa(ctx)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+
+			if tt.expectedErr.Detail != "" {
+				assertProblem(t, response, tt.expectedErr)
+			} else {
+				var responseBody api.TokenResponse
+				err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+				require.NoError(t, err)
+
+				assert.NotEmpty(t, responseBody.AccessToken)
+
+				mt.GetStartedEvent() // find
+				pull := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+				assert.Equal(t, auth.HashOpaqueToken("abcd-efgh"), pull.Lookup("u", "$pull", "mfa.recovery_codes").StringValue())
+			}
+		})
+	}
+}
+
+func TestHandler_EnrollTotp(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	credentials := func(enabled bool) bson.D {
+		return mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{
+			{"_id", hexID},
+			{"email", "jd@jd@mensah.com.com"},
+			{"mfa", bson.D{{"enabled", enabled}}},
+		})
+	}
+
+	tests := []struct {
+		name           string
+		caller         *auth.Claims
+		mockResponses  []bson.D
+		expectedStatus int
+		expectedErr    api.Error
+	}{
+		{
+			name:   "can enroll",
+			caller: newClaims(hexID, "UK"),
+			mockResponses: []bson.D{
+				credentials(false),
+				{{"ok", 1}, {"n", 1}, {"nModified", 1}},
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "mfa already enabled",
+			caller:         newClaims(hexID, "UK"),
+			mockResponses:  []bson.D{credentials(true)},
+			expectedStatus: http.StatusConflict,
+			expectedErr: api.Error{
+				Detail: errMFAAlreadyEnabled,
+			},
+		},
+		{
+			name:   "error storing secret",
+			caller: newClaims(hexID, "UK"),
+			mockResponses: []bson.D{
+				credentials(false),
+				{{"ok", 0}},
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr: api.Error{
+				Detail: errEnrollMFA,
+			},
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponses...)
+
+			h := New(mongoRepo.New(mt.DB), nil, newTOTP(t), testPasswords, newPasswordPolicy(t), newCursorCodec(t), nil, &config.Config{})
+
+			ctx, response := setUpRequest(echo.POST, "/auth/mfa/totp", "")
+			authenticate(ctx, tt.caller)
+
+			err := h.EnrollTotp(ctx)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+
+			if tt.expectedErr.Detail != "" {
+				assertProblem(t, response, tt.expectedErr)
+			} else {
+				var responseBody api.EnrollTotpResponse
+				err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+				require.NoError(t, err)
+
+				assert.True(t, strings.HasPrefix(responseBody.OtpauthUri, "otpauth://totp/"))
+				assert.Contains(t, responseBody.OtpauthUri, responseBody.Secret)
+
+				mt.GetStartedEvent() // find
+				stored := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+				assert.NotEqual(t, responseBody.Secret, stored.Lookup("u", "$set", "mfa.pending_secret").StringValue())
+			}
+		})
+	}
+}
+
+func TestHandler_ConfirmTotp(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	totp := newTOTP(t)
+
+	enrollment, err := totp.Enroll("jd@jd@mensah.com.com")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		body           string
+		mockResponses  []bson.D
+		expectedStatus int
+		expectedErr    api.Error
+	}{
+		{
+			name: "no pending enrollment",
+			body: `{"code":"123456"}`,
+			mockResponses: []bson.D{
+				mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{{"_id", hexID}}),
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errNoPendingMFA,
+			},
+		},
+		{
+			name: "invalid code",
+			body: `{"code":"abcdef"}`,
+			mockResponses: []bson.D{
+				mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{
+					{"_id", hexID},
+					{"mfa", bson.D{{"pending_secret", enrollment.EncryptedSecret}}},
+				}),
+			},
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errInvalidMFACode,
+			},
+		},
+		{
+			name:           "invalid request",
+			body:           `{"`,
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errParseBody,
+			},
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponses...)
+
+			h := New(mongoRepo.New(mt.DB), nil, totp, testPasswords, newPasswordPolicy(t), newCursorCodec(t), nil, &config.Config{})
+
+			ctx, response := setUpRequest(echo.POST, "/auth/mfa/totp/confirm", tt.body)
+			authenticate(ctx, newClaims(hexID, "UK"))
+
+			err := h.ConfirmTotp(ctx)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+
+			assertProblem(t, response, tt.expectedErr)
+		})
+	}
+}
+
+func TestHandler_RegenerateRecoveryCodes(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tests := []struct {
+		name           string
+		mockResponse   bson.D
+		expectedStatus int
+		expectedErr    api.Error
+	}{
+		{
+			name:           "can regenerate recovery codes",
+			mockResponse:   bson.D{{"ok", 1}, {"n", 1}, {"nModified", 1}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "mfa not enabled",
+			mockResponse:   bson.D{{"ok", 1}, {"n", 0}, {"nModified", 0}},
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errMFANotEnabled,
+			},
+		},
+		{
+			name:           "error storing recovery codes",
+			mockResponse:   bson.D{{"ok", 0}},
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr: api.Error{
+				Detail: errGenerateRecovery,
+			},
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			h := New(mongoRepo.New(mt.DB), nil, nil, testPasswords, newPasswordPolicy(t), newCursorCodec(t), nil, &config.Config{})
+
+			ctx, response := setUpRequest(echo.POST, "/auth/mfa/recovery-codes", "")
+			authenticate(ctx, newClaims(hexID, "UK"))
+
+			err := h.RegenerateRecoveryCodes(ctx)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+
+			if tt.expectedErr.Detail != "" {
+				assertProblem(t, response, tt.expectedErr)
+			} else {
+				var responseBody api.RecoveryCodesResponse
+				err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+				require.NoError(t, err)
+
+				assert.Len(t, responseBody.RecoveryCodes, 10)
+			}
+		})
+	}
+}
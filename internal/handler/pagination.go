@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/pagination"
+	"github.com/danielMensah/user-management/internal/query"
+	"github.com/danielMensah/user-management/internal/repository"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	headerLink       = "Link"
+	headerTotalCount = "X-Total-Count"
+
+	queryCursor = "cursor"
+	queryPage   = "page"
+)
+
+// usersQuery returns the query of the users listed by GetUsers. The country and email params are shorthands for
+// filters on these fields, combined with the filter param like any other filter.
+func usersQuery(params api.GetUsersParams) (repository.UserQuery, error) {
+	var expressions []string
+	if params.Filter != nil {
+		expressions = *params.Filter
+	}
+
+	filter, err := query.ParseFilter(expressions)
+	if err != nil {
+		return repository.UserQuery{}, err
+	}
+	if params.Country != nil {
+		filter = append(filter, query.Eq(query.FieldCountry, *params.Country))
+	}
+	if params.Email != nil {
+		filter = append(filter, query.Eq(query.FieldEmail, string(*params.Email)))
+	}
+
+	sort := query.DefaultSort
+	if params.Sort != nil {
+		if sort, err = query.ParseSort(*params.Sort); err != nil {
+			return repository.UserQuery{}, err
+		}
+	}
+
+	var names []string
+	if params.Fields != nil {
+		names = *params.Fields
+	}
+	fields, err := query.ParseFields(names)
+	if err != nil {
+		return repository.UserQuery{}, err
+	}
+
+	q := repository.UserQuery{Filter: filter, Sort: sort, Fields: fields, Limit: params.Limit}
+	if params.IncludeDeleted != nil {
+		q.IncludeDeleted = *params.IncludeDeleted
+	}
+	if params.Page != nil {
+		q.Skip = *params.Page
+	}
+
+	return q, nil
+}
+
+// decodeCursor returns the decoded cursor, or nil when there is no cursor. Cursors issued for another sort than the
+// given one are invalid.
+func (h *Handler) decodeCursor(encoded *string, sort query.Sort) (*pagination.Cursor, error) {
+	if encoded == nil {
+		return nil, nil
+	}
+
+	cursor, err := h.cursors.Decode(*encoded)
+	if err != nil {
+		return nil, err
+	}
+	if cursor.Sort != sort.String() {
+		return nil, pagination.ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+// linkPages sets the cursors of the pages around a page of users in the response, and links to them in the Link header
+func (h *Handler) linkPages(
+	ctx echo.Context,
+	response *api.GetUsersResponse,
+	page *repository.UserPage,
+	sort query.Sort,
+) error {
+	var err error
+	if response.Next, err = h.encodeCursor(page.Next); err != nil {
+		return err
+	}
+	if response.Prev, err = h.encodeCursor(page.Prev); err != nil {
+		return err
+	}
+
+	links, err := h.pageLinks(ctx.Request().URL, sort, response.Next, response.Prev)
+	if err != nil {
+		return err
+	}
+	ctx.Response().Header().Set(headerLink, links)
+
+	return nil
+}
+
+// pageNumber returns the number of the page starting from 1 when it is known. It is only known when skipping users,
+// since cursors can point anywhere.
+func pageNumber(q repository.UserQuery) *int64 {
+	if q.Cursor != nil {
+		return nil
+	}
+
+	return skipPageNumber(q.Skip, q.Limit)
+}
+
+// skipPageNumber returns the number of the page starting from 1 of a list skipping the given number of users
+func skipPageNumber(skip, limit int64) *int64 {
+	if limit <= 0 {
+		return nil
+	}
+
+	number := 1 + skip/limit
+
+	return &number
+}
+
+// encodeCursor returns the encoded cursor, or nil when there is no cursor
+func (h *Handler) encodeCursor(cursor *pagination.Cursor) (*string, error) {
+	if cursor == nil {
+		return nil, nil
+	}
+
+	encoded, err := h.cursors.Encode(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	return &encoded, nil
+}
+
+// pageLinks returns the RFC 8288 Link header of a page of the list requested at the given url, linking to the first
+// and last pages and to the next and previous pages when there are any. Links keep the other query params of the
+// request, such as filters, sort and limit.
+func (h *Handler) pageLinks(requestURL *url.URL, sort query.Sort, next, prev *string) (string, error) {
+	last, err := h.cursors.Encode(pagination.End(sort.String()))
+	if err != nil {
+		return "", err
+	}
+
+	links := []string{pageLink(requestURL, "first", queryCursor, ""), pageLink(requestURL, "last", queryCursor, last)}
+	if next != nil {
+		links = append(links, pageLink(requestURL, "next", queryCursor, *next))
+	}
+	if prev != nil {
+		links = append(links, pageLink(requestURL, "prev", queryCursor, *prev))
+	}
+
+	return strings.Join(links, ", "), nil
+}
+
+// skipLinks returns the RFC 8288 Link header of a page of the list requested at the given url that skips users
+// rather than using cursors, linking to the first page and to the next and previous pages when there are any
+func skipLinks(requestURL *url.URL, skip, limit int64, more bool) string {
+	links := []string{pageLink(requestURL, "first", queryPage, "")}
+	if more {
+		links = append(links, pageLink(requestURL, "next", queryPage, strconv.FormatInt(skip+limit, 10)))
+	}
+	if skip > 0 {
+		prev := skip - limit
+		if prev < 0 {
+			prev = 0
+		}
+		links = append(links, pageLink(requestURL, "prev", queryPage, strconv.FormatInt(prev, 10)))
+	}
+
+	return strings.Join(links, ", ")
+}
+
+// pageLink returns a link to the page the given query param points to, or to the first page when it has no value
+func pageLink(requestURL *url.URL, rel, param, value string) string {
+	values := requestURL.Query()
+	values.Del(queryPage)
+	values.Del(queryCursor)
+	if value != "" {
+		values.Set(param, value)
+	}
+
+	link := url.URL{Path: requestURL.Path, RawQuery: values.Encode()}
+
+	return fmt.Sprintf(`<%s>; rel="%s"`, link.String(), rel)
+}
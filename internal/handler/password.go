@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/auth"
+	"github.com/danielMensah/user-management/internal/mail"
+	"github.com/danielMensah/user-management/internal/repository"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	errForgotPassword    = "failed to request password reset"
+	errResetPassword     = "failed to reset password"
+	errInvalidResetToken = "invalid or expired password reset token"
+	errWeakPassword      = "password does not satisfy the password policy"
+	errCheckPassword     = "failed to check password against the password policy"
+
+	codePasswordPolicy = "password_policy"
+
+	passwordResetSubject = "Reset your password"
+)
+
+// ForgotPassword emails a password reset token to the user with the given email. It responds with 202 whether
+// or not the user exists so that callers cannot enumerate accounts.
+func (h *Handler) ForgotPassword(ctx echo.Context) error {
+	body := new(api.EmailRequest)
+	if err := ctx.Bind(body); err != nil {
+		logrus.WithError(err).Error(errParseBody)
+		return renderProblem(ctx, http.StatusBadRequest, errParseBody)
+	}
+
+	reqCtx := ctx.Request().Context()
+
+	creds, err := h.repo.GetCredentialsByEmail(reqCtx, string(body.Email))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return ctx.NoContent(http.StatusAccepted)
+		}
+
+		return renderServerError(ctx, err, errForgotPassword)
+	}
+
+	// failures past this point only happen for existing users, so they are logged rather than returned
+	if err = h.sendPasswordReset(reqCtx, creds); err != nil {
+		logrus.WithError(err).WithField("user_id", creds.ID).Error(errForgotPassword)
+	}
+
+	return ctx.NoContent(http.StatusAccepted)
+}
+
+// ResetPassword consumes a password reset token, sets the new password of its user and revokes their refresh tokens
+func (h *Handler) ResetPassword(ctx echo.Context) error {
+	body := new(api.ResetPasswordRequest)
+	if err := ctx.Bind(body); err != nil {
+		logrus.WithError(err).Error(errParseBody)
+		return renderProblem(ctx, http.StatusBadRequest, errParseBody)
+	}
+
+	reqCtx := ctx.Request().Context()
+
+	hash := auth.HashOpaqueToken(body.Token)
+
+	// the token is only used once the new password satisfies the policy, so that a rejected password can be retried
+	token, err := h.repo.GetOneTimeToken(reqCtx, hash, repository.TokenPurposePasswordReset)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return renderProblem(ctx, http.StatusBadRequest, errInvalidResetToken)
+		}
+
+		return renderServerError(ctx, err, errResetPassword)
+	}
+
+	creds, err := h.repo.GetCredentialsByID(reqCtx, token.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return renderProblem(ctx, http.StatusBadRequest, errInvalidResetToken)
+		}
+
+		return renderServerError(ctx, err, errResetPassword)
+	}
+
+	if rejected, err := h.enforcePasswordPolicy(ctx, body.Password, creds.Email, creds.Nickname); rejected {
+		return err
+	}
+
+	if token, err = h.repo.UseOneTimeToken(reqCtx, hash, repository.TokenPurposePasswordReset); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return renderProblem(ctx, http.StatusBadRequest, errInvalidResetToken)
+		}
+
+		return renderServerError(ctx, err, errResetPassword)
+	}
+
+	password, err := h.passwords.Hash(body.Password)
+	if err != nil {
+		return renderServerError(ctx, err, errEncryptPwd)
+	}
+
+	if err = h.repo.SetPassword(reqCtx, token.UserID, password); err != nil {
+		return renderServerError(ctx, err, errResetPassword)
+	}
+
+	if err = h.repo.RevokeUserRefreshTokens(reqCtx, token.UserID); err != nil {
+		return renderServerError(ctx, err, errResetPassword)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// enforcePasswordPolicy checks a new password against the password policy. When the password is rejected it renders
+// a 400 listing every violated rule, or a 500 when the policy could not be checked, and reports that it did so.
+func (h *Handler) enforcePasswordPolicy(ctx echo.Context, password string, personalInfo ...string) (bool, error) {
+	violations, err := h.policy.Check(password, personalInfo...)
+	if err != nil {
+		logrus.WithError(err).Error(errCheckPassword)
+		return true, renderProblem(ctx, http.StatusInternalServerError, errCheckPassword)
+	}
+
+	if len(violations) > 0 {
+		return true, writeProblem(ctx, passwordPolicyError(violations))
+	}
+
+	return false, nil
+}
+
+// passwordPolicyError returns a 400 problem listing the rules of the password policy a password violates
+func passwordPolicyError(violations []auth.PasswordViolation) api.Error {
+	problem := problemWithCode(http.StatusBadRequest, errWeakPassword, codePasswordPolicy)
+	problem.Violations = passwordViolations(violations)
+
+	return problem
+}
+
+// passwordViolations returns the rules of the password policy a password violates as they are rendered
+func passwordViolations(violations []auth.PasswordViolation) *[]api.PasswordViolation {
+	apiViolations := make([]api.PasswordViolation, 0, len(violations))
+	for _, v := range violations {
+		apiViolations = append(apiViolations, api.PasswordViolation{Rule: api.PasswordViolationRule(v.Rule), Message: v.Message})
+	}
+
+	return &apiViolations
+}
+
+// sendPasswordReset stores a new password reset token for the user and emails it to them
+func (h *Handler) sendPasswordReset(ctx context.Context, creds *repository.Credentials) error {
+	token, err := h.issueOneTimeToken(ctx, repository.TokenPurposePasswordReset, creds.ID, creds.Email, h.cfg.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	return h.mailer.Send(ctx, mail.Message{
+		To:      creds.Email,
+		Subject: passwordResetSubject,
+		Body:    passwordResetBody(h.cfg.PasswordResetURL, token, h.cfg.PasswordResetTTL),
+	})
+}
+
+// issueOneTimeToken stores a new one-time token for the given purpose and returns it
+func (h *Handler) issueOneTimeToken(ctx context.Context, purpose, userID, email string, ttl time.Duration) (string, error) {
+	token, err := auth.NewOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	err = h.repo.CreateOneTimeToken(ctx, &repository.OneTimeToken{
+		Hash:      auth.HashOpaqueToken(token),
+		Purpose:   purpose,
+		UserID:    userID,
+		Email:     email,
+		ExpiresAt: time.Now().UTC().Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// tokenInstructions tells users how to use an emailed token, linking to the given page when there is one
+func tokenInstructions(pageURL, token, action string) string {
+	if pageURL != "" {
+		return fmt.Sprintf("follow this link to %s: %s?%s", action, pageURL, url.Values{"token": {token}}.Encode())
+	}
+
+	return fmt.Sprintf("use the following token to %s: %s", action, token)
+}
+
+func passwordResetBody(resetURL, token string, ttl time.Duration) string {
+	return fmt.Sprintf(
+		"We received a request to reset your password, %s\n\nThe token expires in %s. If you did not request a reset, you can ignore this email.",
+		tokenInstructions(resetURL, token, "reset your password"), ttl,
+	)
+}
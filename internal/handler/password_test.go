@@ -0,0 +1,238 @@
+package handler
+
+import (
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/auth"
+	"github.com/danielMensah/user-management/internal/config"
+	"github.com/danielMensah/user-management/internal/mail"
+	mongoRepo "github.com/danielMensah/user-management/internal/repository/mongo"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestHandler_ForgotPassword(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	credentials := bson.D{
+		{"_id", hexID},
+		{"email", "jd@jd@mensah.com.com"},
+	}
+
+	tests := []struct {
+		name           string
+		body           string
+		mockResponses  []bson.D
+		expectedStatus int
+		expectedMails  int
+		expectedErr    api.Error
+	}{
+		{
+			name: "emails reset token",
+			body: `{"email":"jd@jd@mensah.com.com"}`,
+			mockResponses: []bson.D{
+				mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, credentials),
+				{{"ok", 1}},
+			},
+			expectedStatus: http.StatusAccepted,
+			expectedMails:  1,
+		},
+		{
+			name: "unknown email",
+			body: `{"email":"unknown@mensah.com"}`,
+			mockResponses: []bson.D{
+				mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch),
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name: "error storing reset token",
+			body: `{"email":"jd@jd@mensah.com.com"}`,
+			mockResponses: []bson.D{
+				mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, credentials),
+				{{"ok", 0}},
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "invalid request",
+			body:           `{"`,
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errParseBody,
+			},
+		},
+		{
+			name:           "error finding user",
+			body:           `{"email":"jd@jd@mensah.com.com"}`,
+			mockResponses:  []bson.D{{{"ok", 0}}},
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr: api.Error{
+				Detail: errForgotPassword,
+			},
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponses...)
+
+			dir := t.TempDir()
+			cfg := &config.Config{PasswordResetTTL: time.Hour}
+			h := New(mongoRepo.New(mt.DB), nil, nil, testPasswords, newPasswordPolicy(t), newCursorCodec(t), mail.NewFileSender(dir, "from@example.com"), cfg)
+
+			ctx, response := setUpRequest(echo.POST, "/auth/password/forgot", tt.body)
+
+			err := h.ForgotPassword(ctx)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+
+			mails, err := os.ReadDir(dir)
+			require.NoError(t, err)
+			assert.Len(t, mails, tt.expectedMails)
+
+			if tt.expectedErr.Detail != "" {
+				assertProblem(t, response, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestHandler_ResetPassword(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tokenDoc := bson.D{
+		{"hash", "hash"},
+		{"purpose", "password_reset"},
+		{"user_id", hexID},
+	}
+	token := mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, tokenDoc)
+	credentials := mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{
+		{"_id", hexID},
+		{"email", "john.doe@example.com"},
+		{"nickname", "jd"},
+	})
+	usedToken := bson.D{
+		{"ok", 1},
+		{"value", tokenDoc},
+	}
+	passwordSet := bson.D{{"ok", 1}, {"n", 1}, {"nModified", 1}}
+
+	tests := []struct {
+		name           string
+		body           string
+		mockResponses  []bson.D
+		expectedStatus int
+		expectedErr    api.Error
+	}{
+		{
+			name:           "resets password",
+			body:           `{"token":"token","password":"new-password"}`,
+			mockResponses:  []bson.D{token, credentials, usedToken, passwordSet, {{"ok", 1}, {"n", 1}, {"nModified", 1}}},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "invalid request",
+			body:           `{"`,
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errParseBody,
+			},
+		},
+		{
+			name:           "invalid, used or expired token",
+			body:           `{"token":"token","password":"new-password"}`,
+			mockResponses:  []bson.D{mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch)},
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errInvalidResetToken,
+			},
+		},
+		{
+			name:           "error finding token",
+			body:           `{"token":"token","password":"new-password"}`,
+			mockResponses:  []bson.D{{{"ok", 0}}},
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr: api.Error{
+				Detail: errResetPassword,
+			},
+		},
+		{
+			name:           "password violates the policy",
+			body:           `{"token":"token","password":"John.Doe-1234"}`,
+			mockResponses:  []bson.D{token, credentials},
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errWeakPassword,
+				Code:   pstring(codePasswordPolicy),
+				Violations: &[]api.PasswordViolation{
+					{Rule: auth.PasswordRulePersonalInfo, Message: "must not contain the email or nickname"},
+				},
+			},
+		},
+		{
+			name:           "token used concurrently",
+			body:           `{"token":"token","password":"new-password"}`,
+			mockResponses:  []bson.D{token, credentials, {{"ok", 1}, {"value", nil}}},
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errInvalidResetToken,
+			},
+		},
+		{
+			name:           "error updating password",
+			body:           `{"token":"token","password":"new-password"}`,
+			mockResponses:  []bson.D{token, credentials, usedToken, {{"ok", 0}}},
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr: api.Error{
+				Detail: errResetPassword,
+			},
+		},
+		{
+			name:           "error revoking refresh tokens",
+			body:           `{"token":"token","password":"new-password"}`,
+			mockResponses:  []bson.D{token, credentials, usedToken, passwordSet, {{"ok", 0}}},
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr: api.Error{
+				Detail: errResetPassword,
+			},
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponses...)
+
+			h := New(mongoRepo.New(mt.DB), nil, nil, testPasswords, newPasswordPolicy(t), newCursorCodec(t), nil, &config.Config{})
+
+			ctx, response := setUpRequest(echo.POST, "/auth/password/reset", tt.body)
+
+			err := h.ResetPassword(ctx)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+
+			if tt.expectedErr.Detail != "" {
+				assertProblem(t, response, tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestPasswordResetBody(t *testing.T) {
+	assert.Contains(t, passwordResetBody("", "abc", time.Hour), "token to reset your password: abc")
+	assert.Contains(t, passwordResetBody("https://example.com/reset", "a+b", time.Hour), "https://example.com/reset?token=a%2Bb")
+	assert.Contains(t, passwordResetBody("", "abc", time.Hour), "expires in 1h0m0s")
+}
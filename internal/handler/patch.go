@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"sync"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/patch"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	errUnsupportedPatch   = "unsupported patch media type"
+	errInvalidPatch       = "invalid patch"
+	errPatchTestFailed    = "patch test failed"
+	errInvalidPatchedUser = "patched user is invalid"
+	errLoadSchema         = "failed to load schema"
+
+	// schemaUserReplaceData is the schema of the api specification patched users must match
+	schemaUserReplaceData = "UserReplaceData"
+)
+
+// patchers apply the patches of each supported media type to a document
+var patchers = map[string]func(document interface{}, body []byte) (interface{}, error){
+	patch.MIMEMergePatch: patch.Merge,
+	patch.MIMEJSONPatch:  patch.Apply,
+}
+
+var (
+	swaggerOnce sync.Once
+	swagger     *openapi3.T
+	swaggerErr  error
+)
+
+func init() {
+	// the request validator only decodes the bodies of media types it knows about
+	for mediaType := range patchers {
+		openapi3filter.RegisterBodyDecoder(mediaType, decodePatch)
+	}
+}
+
+// PatchUser applies a JSON merge patch or a JSON patch to the editable fields of a user, then replaces them with the
+// patched ones when they are valid and the user was not changed in the meantime
+func (h *Handler) PatchUser(ctx echo.Context, id string, params api.PatchUserParams) error {
+	claims, permissions, httpErr := h.authorize(ctx)
+	if httpErr != nil {
+		return renderError(ctx, httpErr)
+	}
+
+	versions, httpErr := h.ifMatch(params.IfMatch)
+	if httpErr != nil {
+		return renderError(ctx, httpErr)
+	}
+
+	if !canUpdateUser(claims, permissions, id, false) {
+		return renderProblem(ctx, http.StatusForbidden, errForbidden)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(ctx.Request().Header.Get(echo.HeaderContentType))
+	apply, ok := patchers[mediaType]
+	if !ok {
+		return renderProblem(ctx, http.StatusUnsupportedMediaType, errUnsupportedPatch)
+	}
+
+	body, err := io.ReadAll(ctx.Request().Body)
+	if err != nil {
+		logrus.WithError(err).Error(errParseBody)
+		return renderProblem(ctx, http.StatusBadRequest, errParseBody)
+	}
+
+	user, err := h.repo.GetUser(ctx.Request().Context(), id, nil)
+	if err != nil {
+		return renderRepositoryError(ctx, err, errUpdateUser)
+	}
+
+	if !matchesVersion(versions, user.Version) {
+		return renderProblem(ctx, http.StatusPreconditionFailed, errPreconditionFailed)
+	}
+
+	data, err := patchUser(user, body, apply)
+	if err != nil {
+		return renderPatchError(ctx, err)
+	}
+
+	// the patch applied to the version read, which must not change before the patched user is stored
+	return h.replaceUser(ctx, claims, permissions, id, data, []int64{user.Version})
+}
+
+// patchUser applies a patch to the editable fields of a user, and returns the patched fields once they are checked
+// against the api specification
+func patchUser(
+	user *api.User,
+	body []byte,
+	apply func(document interface{}, body []byte) (interface{}, error),
+) (*api.UserReplaceData, error) {
+	document := map[string]interface{}{
+		"first_name": user.FirstName,
+		"last_name":  user.LastName,
+		"nickname":   user.Nickname,
+		"email":      string(user.Email),
+		"country":    user.Country,
+	}
+
+	patched, err := apply(document, body)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := apiSchema(schemaUserReplaceData)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = schema.VisitJSON(patched, openapi3.MultiErrors()); err != nil {
+		return nil, err
+	}
+
+	encoded, err := json.Marshal(patched)
+	if err != nil {
+		return nil, err
+	}
+
+	data := new(api.UserReplaceData)
+	if err = json.Unmarshal(encoded, data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// apiSchema returns the schema of the api specification with the given name, loading the specification the first time
+func apiSchema(name string) (*openapi3.Schema, error) {
+	swaggerOnce.Do(func() {
+		swagger, swaggerErr = api.GetSwagger()
+	})
+	if swaggerErr != nil {
+		return nil, fmt.Errorf("%s %s: %w", errLoadSchema, name, swaggerErr)
+	}
+
+	ref, ok := swagger.Components.Schemas[name]
+	if !ok || ref.Value == nil {
+		return nil, fmt.Errorf("%s %s: not defined", errLoadSchema, name)
+	}
+
+	return ref.Value, nil
+}
+
+// renderPatchError renders the error of a patch that could not be applied, or whose result is not a valid user.
+// Invalid fields of the patched user are listed in the errors of the problem.
+func renderPatchError(ctx echo.Context, err error) error {
+	var schemaErr *openapi3.SchemaError
+
+	switch {
+	case errors.Is(err, patch.ErrTestFailed):
+		logrus.WithError(err).Debug(errPatchTestFailed)
+		return renderProblem(ctx, http.StatusConflict, errPatchTestFailed)
+	case errors.Is(err, patch.ErrInvalidPatch):
+		logrus.WithError(err).Debug(errInvalidPatch)
+		return renderProblem(ctx, http.StatusBadRequest, errInvalidPatch)
+	case errors.As(err, &schemaErr):
+		problem := newProblem(http.StatusBadRequest, errInvalidPatchedUser)
+		problem.Errors = fieldErrors(err)
+		return writeProblem(ctx, problem)
+	default:
+		return renderServerError(ctx, err, errUpdateUser)
+	}
+}
+
+// decodePatch decodes the JSON body of a patch for the request validator
+func decodePatch(body io.Reader, _ http.Header, _ *openapi3.SchemaRef, _ openapi3filter.EncodingFn) (interface{}, error) {
+	var value interface{}
+	if err := json.NewDecoder(body).Decode(&value); err != nil {
+		return nil, &openapi3filter.ParseError{Kind: openapi3filter.KindInvalidFormat, Cause: err}
+	}
+
+	return value, nil
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/auth"
+	"github.com/danielMensah/user-management/internal/config"
+	"github.com/danielMensah/user-management/internal/patch"
+	"github.com/danielMensah/user-management/internal/permission"
+	mongoRepo "github.com/danielMensah/user-management/internal/repository/mongo"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestHandler_PatchUser(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	user := mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{
+		{"_id", hexID},
+		{"first_name", "john"},
+		{"last_name", "doe"},
+		{"nickname", "jd"},
+		{"email", "jd@mensah.com"},
+		{"country", "UK"},
+	})
+	credentials := mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{
+		{"_id", hexID},
+		{"email", "jd@mensah.com"},
+		{"nickname", "jd"},
+	})
+	updated := bson.D{{"ok", 1}, {"value", bson.D{{"_id", hexID}, {"nickname", "johnny"}}}}
+
+	tests := []struct {
+		name           string
+		contentType    string
+		body           string
+		params         api.PatchUserParams
+		mockResponses  []bson.D
+		caller         *auth.Claims
+		expectedStatus int
+		expectedSet    bson.D
+		expectedErr    api.Error
+	}{
+		{
+			name:           "merge patch clears a field",
+			contentType:    patch.MIMEMergePatch,
+			body:           `{"last_name":"","nickname":"johnny"}`,
+			mockResponses:  []bson.D{user, credentials, updated},
+			expectedStatus: http.StatusOK,
+			expectedSet:    bson.D{{"first_name", "john"}, {"last_name", ""}, {"nickname", "johnny"}},
+		},
+		{
+			name:        "json patch applies operations whose tests hold",
+			contentType: patch.MIMEJSONPatch + "; charset=utf-8",
+			body: `[{"op":"test","path":"/nickname","value":"jd"},` +
+				`{"op":"replace","path":"/nickname","value":"johnny"},{"op":"copy","from":"/country","path":"/last_name"}]`,
+			mockResponses:  []bson.D{user, credentials, updated},
+			expectedStatus: http.StatusOK,
+			expectedSet:    bson.D{{"first_name", "john"}, {"last_name", "UK"}, {"nickname", "johnny"}},
+		},
+		{
+			name:           "json patch whose test fails",
+			contentType:    patch.MIMEJSONPatch,
+			body:           `[{"op":"test","path":"/nickname","value":"johnny"},{"op":"remove","path":"/nickname"}]`,
+			mockResponses:  []bson.D{user},
+			expectedStatus: http.StatusConflict,
+			expectedErr:    api.Error{Detail: errPatchTestFailed},
+		},
+		{
+			name:           "json patch that cannot be applied",
+			contentType:    patch.MIMEJSONPatch,
+			body:           `[{"op":"replace","path":"/password/0","value":"secret"}]`,
+			mockResponses:  []bson.D{user},
+			expectedStatus: http.StatusBadRequest,
+			expectedErr:    api.Error{Detail: errInvalidPatch},
+		},
+		{
+			name:           "patched user misses a required field",
+			contentType:    patch.MIMEMergePatch,
+			body:           `{"email":null}`,
+			mockResponses:  []bson.D{user},
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errInvalidPatchedUser,
+				Errors: &[]api.FieldError{{Pointer: pstring("/email"), Reason: `property "email" is missing`}},
+			},
+		},
+		{
+			name:           "patched user has a field that cannot be edited",
+			contentType:    patch.MIMEJSONPatch,
+			body:           `[{"op":"add","path":"/roles","value":["admin"]}]`,
+			mockResponses:  []bson.D{user},
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errInvalidPatchedUser,
+				Errors: &[]api.FieldError{{Pointer: pstring(""), Reason: `property "roles" is unsupported`}},
+			},
+		},
+		{
+			name:           "user changed since the version of the client",
+			contentType:    patch.MIMEMergePatch,
+			body:           `{"nickname":"johnny"}`,
+			params:         api.PatchUserParams{IfMatch: pstring(`"2"`)},
+			mockResponses:  []bson.D{user},
+			expectedStatus: http.StatusPreconditionFailed,
+			expectedErr:    api.Error{Detail: errPreconditionFailed},
+		},
+		{
+			name:           "unsupported media type",
+			contentType:    echo.MIMEApplicationJSON,
+			body:           `{"nickname":"johnny"}`,
+			expectedStatus: http.StatusUnsupportedMediaType,
+			expectedErr:    api.Error{Detail: errUnsupportedPatch},
+		},
+		{
+			name:           "user cannot patch another user",
+			contentType:    patch.MIMEMergePatch,
+			body:           `{"nickname":"johnny"}`,
+			caller:         newClaims(primitive.NewObjectID().Hex(), "UK", permission.RoleUser),
+			expectedStatus: http.StatusForbidden,
+			expectedErr:    api.Error{Detail: errForbidden},
+		},
+		{
+			name:           "user not found",
+			contentType:    patch.MIMEMergePatch,
+			body:           `{"nickname":"johnny"}`,
+			mockResponses:  []bson.D{mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch)},
+			expectedStatus: http.StatusNotFound,
+			expectedErr:    api.Error{Detail: errUserNotFound},
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponses...)
+
+			s := New(mongoRepo.New(mt.DB), nil, nil, testPasswords, newPasswordPolicy(t), newCursorCodec(t), nil, &config.Config{})
+
+			ctx, response := setUpRequest(echo.PATCH, "/users/:hexID", tt.body)
+			ctx.Request().Header.Set(echo.HeaderContentType, tt.contentType)
+			authenticate(ctx, tt.caller)
+
+			err := s.PatchUser(ctx, hexID, tt.params)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+
+			if tt.expectedErr.Detail != "" {
+				assertProblem(t, response, tt.expectedErr)
+				return
+			}
+
+			var responseBody api.User
+			require.NoError(t, json.Unmarshal(response.Body.Bytes(), &responseBody))
+			assert.Equal(t, "johnny", responseBody.Nickname)
+
+			mt.GetStartedEvent()
+			mt.GetStartedEvent()
+			command := mt.GetStartedEvent().Command
+			versions, err := command.Lookup("query", "version", "$in").Array().Values()
+			require.NoError(t, err)
+			assert.Equal(t, int64(0), versions[0].Int64())
+
+			set := command.Lookup("update", "$set").Document()
+			for _, field := range tt.expectedSet {
+				assert.Equal(t, field.Value, set.Lookup(field.Key).StringValue(), field.Key)
+			}
+			assert.Nil(t, set.Lookup("email_verified").Value)
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/auth"
+	"github.com/danielMensah/user-management/internal/permission"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	errForbidden          = "not allowed to perform this operation"
+	errForbiddenCountry   = "not allowed to change the country of users"
+	errUnauthenticated    = "authentication required"
+	errResolvePermissions = "failed to resolve permissions"
+)
+
+// authorize returns the claims of the caller and the permissions granted by their roles
+func (h *Handler) authorize(ctx echo.Context) (*auth.Claims, permission.Set, *echo.HTTPError) {
+	claims, ok := auth.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, nil, echo.NewHTTPError(http.StatusUnauthorized, errUnauthenticated)
+	}
+
+	permissions, err := h.permissions.Permissions(ctx.Request().Context(), claims.Roles)
+	if err != nil {
+		return nil, nil, serverError(err, errResolvePermissions)
+	}
+
+	return claims, permissions, nil
+}
+
+// requirePermission returns an error unless the caller is granted the given permission
+func (h *Handler) requirePermission(ctx echo.Context, required permission.Permission) *echo.HTTPError {
+	_, permissions, httpErr := h.authorize(ctx)
+	if httpErr != nil {
+		return httpErr
+	}
+
+	if !permissions.Allows(required) {
+		return echo.NewHTTPError(http.StatusForbidden, errForbidden)
+	}
+
+	return nil
+}
+
+// requireSensitivePermission returns an error unless the caller is granted the given permission and, when
+// verified emails are required, has verified their email
+func (h *Handler) requireSensitivePermission(ctx echo.Context, required permission.Permission) *echo.HTTPError {
+	if httpErr := h.requirePermission(ctx, required); httpErr != nil {
+		return httpErr
+	}
+
+	claims, _ := auth.ClaimsFromContext(ctx)
+
+	return h.requireVerifiedEmail(claims)
+}
+
+// authorizeReplacement returns an error unless the caller can update the user with the given id, change their country
+// when the update does, and, when the update changes their email or password, change their credentials with a verified
+// email when verified emails are required
+func (h *Handler) authorizeReplacement(
+	claims *auth.Claims,
+	permissions permission.Set,
+	id string,
+	changesCredentials bool,
+	changesCountry bool,
+) *echo.HTTPError {
+	if !canUpdateUser(claims, permissions, id, changesCredentials) {
+		return echo.NewHTTPError(http.StatusForbidden, errForbidden)
+	}
+
+	if changesCountry && !canChangeCountry(permissions) {
+		return echo.NewHTTPError(http.StatusForbidden, errForbiddenCountry)
+	}
+
+	if changesCredentials {
+		return h.requireVerifiedEmail(claims)
+	}
+
+	return nil
+}
+
+// requireVerifiedEmail returns an error when verified emails are required and the caller has not verified theirs
+func (h *Handler) requireVerifiedEmail(claims *auth.Claims) *echo.HTTPError {
+	if h.cfg.RequireVerifiedEmail && !claims.EmailVerified {
+		return echo.NewHTTPError(http.StatusForbidden, errEmailNotVerified)
+	}
+
+	return nil
+}
+
+// canListUsers reports whether the caller can list the users of the given country, or of every country when none is
+// given, and returns the country the list is restricted to. Callers only allowed to read the users of their own
+// country have it become the country filter when none is given. Every other filter is combined with it, so that
+// filters cannot widen the list to other countries.
+func canListUsers(claims *auth.Claims, permissions permission.Set, country *string) (*string, bool) {
+	if permissions.Allows(permission.UsersRead) {
+		return country, true
+	}
+
+	if !permissions.Allows(permission.UsersReadCountry) || claims.Country == "" {
+		return country, false
+	}
+
+	if country == nil {
+		country = &claims.Country
+	}
+
+	return country, *country == claims.Country
+}
+
+// canListDeletedUsers reports whether the caller can list soft deleted users when asked to, which takes the
+// permission to restore them
+func canListDeletedUsers(permissions permission.Set, includeDeleted *bool) bool {
+	return includeDeleted == nil || !*includeDeleted || permissions.Allows(permission.UsersRestore)
+}
+
+// canReadUser reports whether the caller can read the given user
+func canReadUser(claims *auth.Claims, permissions permission.Set, user *api.User) bool {
+	if permissions.Allows(permission.UsersRead) {
+		return true
+	}
+
+	if claims.Subject == user.Id && permissions.Allows(permission.UsersReadSelf) {
+		return true
+	}
+
+	return claims.Country != "" && user.Country == claims.Country && permissions.Allows(permission.UsersReadCountry)
+}
+
+// mayReadUser reports whether the caller can read the user with the given id, or may depending on the country of the
+// user. It is checked before the user is looked up, so that callers who cannot read users do not learn which exist.
+func mayReadUser(claims *auth.Claims, permissions permission.Set, id string) bool {
+	if permissions.Allows(permission.UsersRead) {
+		return true
+	}
+
+	if claims.Subject == id && permissions.Allows(permission.UsersReadSelf) {
+		return true
+	}
+
+	return claims.Country != "" && permissions.Allows(permission.UsersReadCountry)
+}
+
+// canUpdateUser reports whether the caller can update the user with the given id, changing their email or password
+// when changesCredentials is true. Profile updates leave the email and password of the user untouched.
+func canUpdateUser(claims *auth.Claims, permissions permission.Set, id string, changesCredentials bool) bool {
+	if permissions.Allows(permission.UsersUpdate) {
+		return true
+	}
+
+	if claims.Subject == id && permissions.Allows(permission.UsersUpdateSelf) {
+		return true
+	}
+
+	return !changesCredentials && permissions.Allows(permission.UsersUpdateProfile)
+}
+
+// canChangeCountry reports whether the caller can change the country of users. The country of a user decides which
+// users they can read when they may read the users of their country, so users cannot change their own.
+func canChangeCountry(permissions permission.Set) bool {
+	return permissions.Allows(permission.UsersUpdateProfile)
+}
+
+// canDeleteUser reports whether the caller can delete the user with the given id
+func canDeleteUser(claims *auth.Claims, permissions permission.Set, id string) bool {
+	if permissions.Allows(permission.UsersDelete) {
+		return true
+	}
+
+	return claims.Subject == id && permissions.Allows(permission.UsersDeleteSelf)
+}
+
+func rolesToStrings(roles api.Roles) []string {
+	s := make([]string, 0, len(roles))
+	for _, r := range roles {
+		s = append(s, string(r))
+	}
+
+	return s
+}
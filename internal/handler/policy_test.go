@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/auth"
+	"github.com/danielMensah/user-management/internal/config"
+	"github.com/danielMensah/user-management/internal/permission"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanListUsers(t *testing.T) {
+	tests := []struct {
+		name            string
+		claims          *auth.Claims
+		permissions     permission.Set
+		country         *string
+		expected        bool
+		expectedCountry *string
+	}{
+		{
+			name:        "users:read can list every user",
+			claims:      newClaims(hexID, "UK"),
+			permissions: permission.Set{permission.UsersRead},
+			expected:    true,
+		},
+		{
+			name:            "users:read:country lists users of their country by default",
+			claims:          newClaims(hexID, "UK"),
+			permissions:     permission.Set{permission.UsersReadCountry},
+			expected:        true,
+			expectedCountry: pstring("UK"),
+		},
+		{
+			name:            "users:read:country can filter by their country",
+			claims:          newClaims(hexID, "UK"),
+			permissions:     permission.Set{permission.UsersReadCountry},
+			country:         pstring("UK"),
+			expected:        true,
+			expectedCountry: pstring("UK"),
+		},
+		{
+			name:            "users:read:country cannot filter by another country",
+			claims:          newClaims(hexID, "UK"),
+			permissions:     permission.Set{permission.UsersReadCountry},
+			country:         pstring("US"),
+			expectedCountry: pstring("US"),
+		},
+		{
+			name:        "users:read:country without country cannot list users",
+			claims:      newClaims(hexID, ""),
+			permissions: permission.Set{permission.UsersReadCountry},
+		},
+		{
+			name:        "cannot list users without permission",
+			claims:      newClaims(hexID, "UK"),
+			permissions: permission.Set{permission.UsersUpdateSelf},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			country, got := canListUsers(tt.claims, tt.permissions, tt.country)
+
+			assert.Equal(t, tt.expected, got)
+			assert.Equal(t, tt.expectedCountry, country)
+		})
+	}
+}
+
+func TestCanListDeletedUsers(t *testing.T) {
+	assert.True(t, canListDeletedUsers(permission.Set{permission.UsersReadCountry}, nil))
+	assert.True(t, canListDeletedUsers(permission.Set{permission.UsersReadCountry}, pbool(false)))
+	assert.False(t, canListDeletedUsers(permission.Set{permission.UsersRead}, pbool(true)))
+	assert.True(t, canListDeletedUsers(permission.Set{permission.UsersRead, permission.UsersRestore}, pbool(true)))
+}
+
+func TestCanUpdateUser(t *testing.T) {
+	tests := []struct {
+		name               string
+		claims             *auth.Claims
+		permissions        permission.Set
+		changesCredentials bool
+		expected           bool
+	}{
+		{
+			name:               "users:update can update every user",
+			claims:             newClaims("other", "UK"),
+			permissions:        permission.Set{permission.UsersUpdate},
+			changesCredentials: true,
+			expected:           true,
+		},
+		{
+			name:               "users:update:self can update themselves",
+			claims:             newClaims(hexID, "UK"),
+			permissions:        permission.Set{permission.UsersUpdateSelf},
+			changesCredentials: true,
+			expected:           true,
+		},
+		{
+			name:        "users:update:self cannot update another user",
+			claims:      newClaims("other", "UK"),
+			permissions: permission.Set{permission.UsersUpdateSelf},
+		},
+		{
+			name:        "users:update:profile can update the profile of another user",
+			claims:      newClaims("other", "UK"),
+			permissions: permission.Set{permission.UsersUpdateProfile},
+			expected:    true,
+		},
+		{
+			name:               "users:update:profile cannot update the email of another user",
+			claims:             newClaims("other", "UK"),
+			permissions:        permission.Set{permission.UsersUpdateProfile},
+			changesCredentials: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, canUpdateUser(tt.claims, tt.permissions, hexID, tt.changesCredentials))
+		})
+	}
+}
+
+func TestCanReadUser(t *testing.T) {
+	user := &api.User{Id: hexID, Country: "UK"}
+
+	assert.True(t, canReadUser(newClaims("other", "US"), permission.Set{permission.UsersRead}, user))
+	assert.True(t, canReadUser(newClaims(hexID, "US"), permission.Set{permission.UsersReadSelf}, user))
+	assert.True(t, canReadUser(newClaims("other", "UK"), permission.Set{permission.UsersReadCountry}, user))
+	assert.False(t, canReadUser(newClaims("other", "UK"), permission.Set{permission.UsersReadSelf}, user))
+	assert.False(t, canReadUser(newClaims("other", "US"), permission.Set{permission.UsersReadCountry}, user))
+	assert.False(t, canReadUser(newClaims("other", ""), permission.Set{permission.UsersReadCountry}, &api.User{Id: hexID}))
+}
+
+func TestCanDeleteUser(t *testing.T) {
+	assert.True(t, canDeleteUser(newClaims("other", "UK"), permission.Set{permission.UsersDelete}, hexID))
+	assert.True(t, canDeleteUser(newClaims(hexID, "UK"), permission.Set{permission.UsersDeleteSelf}, hexID))
+	assert.False(t, canDeleteUser(newClaims("other", "UK"), permission.Set{permission.UsersDeleteSelf}, hexID))
+	assert.False(t, canDeleteUser(newClaims("other", "UK"), permission.Set{permission.UsersUpdate}, hexID))
+}
+
+func TestHandler_requireVerifiedEmail(t *testing.T) {
+	verified := newClaims(hexID, "UK")
+	verified.EmailVerified = true
+
+	h := &Handler{cfg: &config.Config{RequireVerifiedEmail: true}}
+	assert.Nil(t, h.requireVerifiedEmail(verified))
+	assert.Equal(t, http.StatusForbidden, h.requireVerifiedEmail(newClaims(hexID, "UK")).Code)
+
+	h = &Handler{cfg: &config.Config{}}
+	assert.Nil(t, h.requireVerifiedEmail(newClaims(hexID, "UK")))
+}
+
+func TestHandler_authorizeReplacement(t *testing.T) {
+	verified := newClaims(hexID, "UK")
+	verified.EmailVerified = true
+	self := permission.Set{permission.UsersUpdateSelf}
+
+	h := &Handler{cfg: &config.Config{RequireVerifiedEmail: true}}
+	assert.Nil(t, h.authorizeReplacement(verified, self, hexID, true, false))
+	assert.Nil(t, h.authorizeReplacement(newClaims(hexID, "UK"), self, hexID, false, false))
+	assert.Equal(t, errEmailNotVerified, h.authorizeReplacement(newClaims(hexID, "UK"), self, hexID, true, false).Message)
+	assert.Equal(t, errForbidden, h.authorizeReplacement(verified, self, "other", false, false).Message)
+	assert.Equal(t, errForbiddenCountry, h.authorizeReplacement(verified, self, hexID, false, true).Message)
+
+	profile := permission.Set{permission.UsersUpdateProfile}
+	assert.Nil(t, h.authorizeReplacement(verified, profile, "other", false, true))
+	assert.Nil(t, h.authorizeReplacement(verified, permission.Set{permission.UsersUpdate}, "other", true, true))
+}
@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/query"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	errInternal = "internal server error"
+
+	// mimeProblemJSON is the media type of problem details defined by RFC 7807
+	mimeProblemJSON = "application/problem+json"
+	// problemTypeBlank is the problem type of problems fully described by their status code
+	problemTypeBlank = "about:blank"
+)
+
+// HTTPErrorHandler renders the errors echo handles itself, such as unknown routes, as problem details. Errors
+// other than *echo.HTTPError are rendered as server errors.
+func HTTPErrorHandler(err error, ctx echo.Context) {
+	if ctx.Response().Committed {
+		return
+	}
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		if httpErr.Internal != nil {
+			logrus.WithError(httpErr.Internal).Debug(httpErr.Message)
+		}
+	} else {
+		httpErr = serverError(err, errInternal)
+	}
+
+	if err = renderError(ctx, httpErr); err != nil {
+		logrus.WithError(err).Error("failed to render error")
+	}
+}
+
+// ValidationErrorHandler renders errors raised while validating requests against the api specification. The fields
+// of the request that do not match the specification are listed in the errors of the problem.
+func ValidationErrorHandler(ctx echo.Context, err *echo.HTTPError) error {
+	if me, ok := err.Internal.(openapi3.MultiError); ok {
+		err = multiValidationError(me)
+	}
+
+	// errors of the middlewares reading the request, such as a body over its limit, are rendered as they are
+	var httpErr *echo.HTTPError
+	if errors.As(err.Internal, &httpErr) {
+		return renderError(ctx, httpErr)
+	}
+
+	if err.Internal != nil {
+		logrus.WithError(err.Internal).Debug(err.Message)
+	}
+
+	problem := newProblem(err.Code, fmt.Sprint(err.Message))
+	problem.Errors = fieldErrors(err.Internal)
+
+	return writeProblem(ctx, problem)
+}
+
+// multiValidationError returns the error of a request the validator found several errors in, which the validator
+// middleware renders as a server error. Failed security requirements take precedence as they do when the validator
+// stops at the first error, other errors make a bad request detailed by the first of them.
+func multiValidationError(me openapi3.MultiError) *echo.HTTPError {
+	var securityErr *openapi3filter.SecurityRequirementsError
+	if errors.As(me, &securityErr) {
+		for _, err := range securityErr.Errors {
+			if httpErr, ok := err.(*echo.HTTPError); ok {
+				return httpErr
+			}
+		}
+		return &echo.HTTPError{Code: http.StatusForbidden, Message: securityErr.Error(), Internal: securityErr}
+	}
+
+	// errors of the validator detail the schemas and values they are about on the lines following their message
+	detail := strings.SplitN(me[0].Error(), "\n", 2)[0]
+
+	return &echo.HTTPError{Code: http.StatusBadRequest, Message: detail, Internal: me}
+}
+
+// renderError renders an echo error as problem details
+func renderError(ctx echo.Context, err *echo.HTTPError) error {
+	return renderProblem(ctx, err.Code, fmt.Sprint(err.Message))
+}
+
+// renderProblem renders problem details with the given status and detail
+func renderProblem(ctx echo.Context, status int, detail string) error {
+	return writeProblem(ctx, newProblem(status, detail))
+}
+
+// writeProblem renders problem details as application/problem+json, with the path of the request as their instance
+func writeProblem(ctx echo.Context, problem api.Error) error {
+	instance := ctx.Request().URL.Path
+	problem.Instance = &instance
+
+	ctx.Response().Header().Set(echo.HeaderContentType, mimeProblemJSON)
+
+	return ctx.JSON(problem.Status, problem)
+}
+
+// renderQueryError renders the error of query params that do not match the query language of lists, naming the
+// invalid param in the errors of the problem
+func renderQueryError(ctx echo.Context, err error) error {
+	problem := newProblem(http.StatusBadRequest, errInvalidQuery)
+	problem.Errors = fieldErrors(err)
+
+	return writeProblem(ctx, problem)
+}
+
+// newProblem returns the problem details of an error fully described by its status code
+func newProblem(status int, detail string) api.Error {
+	return api.Error{
+		Type:   problemTypeBlank,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// problemWithCode returns problem details carrying a code clients can rely on to tell them apart
+func problemWithCode(status int, detail, code string) api.Error {
+	problem := newProblem(status, detail)
+	problem.Code = &code
+
+	return problem
+}
+
+// fieldErrors returns the invalid fields of a request or document that does not match the api specification or the
+// query language of lists, either parameters or JSON pointers into the body. It returns nil when the error is not about
+// fields of the request.
+func fieldErrors(err error) *[]api.FieldError {
+	var queryErr *query.Error
+	if errors.As(err, &queryErr) {
+		return &[]api.FieldError{{Parameter: &queryErr.Param, Reason: queryErr.Reason}}
+	}
+
+	fields := invalidFields(err)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	return &fields
+}
+
+// invalidFields returns a field error for each error of the validator, the validator collecting the errors of every
+// invalid field in an openapi3.MultiError when it does not stop at the first one
+func invalidFields(err error) []api.FieldError {
+	if me, ok := err.(openapi3.MultiError); ok {
+		fields := make([]api.FieldError, 0, len(me))
+		for _, err := range me {
+			fields = append(fields, invalidFields(err)...)
+		}
+		return fields
+	}
+
+	var requestErr *openapi3filter.RequestError
+	if errors.As(err, &requestErr) {
+		return requestFields(requestErr)
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		pointer := jsonPointer(schemaErr.JSONPointer())
+		return []api.FieldError{{Pointer: &pointer, Reason: schemaErr.Reason}}
+	}
+
+	return nil
+}
+
+// requestFields returns the invalid fields of a request error: its parameter, or the fields of its body that do not
+// match their schema. A body that cannot be decoded is pointed at as a whole.
+func requestFields(requestErr *openapi3filter.RequestError) []api.FieldError {
+	switch {
+	case requestErr.Parameter != nil:
+		return []api.FieldError{{Parameter: &requestErr.Parameter.Name, Reason: validationReason(requestErr)}}
+	case requestErr.RequestBody != nil:
+		if fields := invalidFields(requestErr.Err); len(fields) > 0 {
+			return fields
+		}
+		pointer := ""
+		return []api.FieldError{{Pointer: &pointer, Reason: validationReason(requestErr)}}
+	default:
+		return nil
+	}
+}
+
+// validationReason explains why a field of a request does not match the api specification, leaving out the
+// internals of the validator such as the errors of strconv
+func validationReason(requestErr *openapi3filter.RequestError) string {
+	var schemaErr *openapi3.SchemaError
+	if errors.As(requestErr.Err, &schemaErr) {
+		return schemaErr.Reason
+	}
+
+	var parseErr *openapi3filter.ParseError
+	if errors.As(requestErr.Err, &parseErr) && parseErr.Reason != "" {
+		if parseErr.Value == nil {
+			return parseErr.Reason
+		}
+		return fmt.Sprintf("value %v: %s", parseErr.Value, parseErr.Reason)
+	}
+
+	if requestErr.Err != nil {
+		return requestErr.Err.Error()
+	}
+
+	return requestErr.Reason
+}
+
+// jsonPointer returns the JSON pointer of a path as defined by RFC 6901
+func jsonPointer(path []string) string {
+	var pointer strings.Builder
+	for _, token := range path {
+		token = strings.ReplaceAll(token, "~", "~0")
+		token = strings.ReplaceAll(token, "/", "~1")
+		pointer.WriteString("/" + token)
+	}
+
+	return pointer.String()
+}
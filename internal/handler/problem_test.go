@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/auth"
+	"github.com/danielMensah/user-management/internal/config"
+	"github.com/danielMensah/user-management/internal/importer"
+	"github.com/danielMensah/user-management/internal/patch"
+	"github.com/deepmap/oapi-codegen/pkg/middleware"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidationErrorHandler(t *testing.T) {
+	swagger, err := api.GetSwagger()
+	require.NoError(t, err)
+
+	tokens := newTokenManager(t)
+	accessToken, err := tokens.IssueAccessToken(auth.Identity{ID: hexID, Roles: []string{"admin"}})
+	require.NoError(t, err)
+
+	router := echo.New()
+	router.Use(ImportBodyLimit("/api/v1"), middleware.OapiRequestValidatorWithOptions(swagger, &middleware.Options{
+		ErrorHandler: ValidationErrorHandler,
+		Options: openapi3filter.Options{
+			AuthenticationFunc: auth.NewAuthenticationFunc(tokens),
+			MultiError:         true,
+		},
+	}))
+	api.RegisterHandlersWithBaseURL(router, New(nil, tokens, nil, testPasswords, newPasswordPolicy(t), newCursorCodec(t), nil, &config.Config{}), "/api/v1")
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		contentType    string
+		streamed       bool
+		authenticated  bool
+		expectedStatus int
+		expectedDetail string
+		expectedErrors *[]api.FieldError
+	}{
+		{
+			name:           "missing property",
+			method:         echo.POST,
+			path:           "/api/v1/users",
+			body:           `{"first_name":"john","last_name":"doe","nickname":"jd","password":"password","country":"UK"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedDetail: `request body has an error: doesn't match the schema: Error at "/email": property "email" is missing`,
+			expectedErrors: &[]api.FieldError{{Pointer: pstring("/email"), Reason: `property "email" is missing`}},
+		},
+		{
+			name:           "property of the wrong type",
+			method:         echo.POST,
+			path:           "/api/v1/users",
+			body:           `{"first_name":1,"last_name":"doe","nickname":"jd","email":"jd@example.com","password":"password","country":"UK"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedDetail: `request body has an error: doesn't match the schema: Error at "/first_name": Field must be set to string or not be present`,
+			expectedErrors: &[]api.FieldError{{Pointer: pstring("/first_name"), Reason: "Field must be set to string or not be present"}},
+		},
+		{
+			name:           "several invalid properties",
+			method:         echo.POST,
+			path:           "/api/v1/users",
+			body:           `{"first_name":1,"last_name":"doe","nickname":"jd","password":"password","country":"UK"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedDetail: `request body has an error: doesn't match the schema: Error at "/first_name": Field must be set to string or not be present`,
+			expectedErrors: &[]api.FieldError{
+				{Pointer: pstring("/first_name"), Reason: "Field must be set to string or not be present"},
+				{Pointer: pstring("/email"), Reason: `property "email" is missing`},
+			},
+		},
+		{
+			name:           "invalid query parameter",
+			method:         echo.GET,
+			path:           "/api/v1/users?page=1&limit=ten",
+			authenticated:  true,
+			expectedStatus: http.StatusBadRequest,
+			expectedDetail: `parameter "limit" in query has an error: value ten: an invalid integer: strconv.ParseFloat: parsing "ten": invalid syntax`,
+			expectedErrors: &[]api.FieldError{{Parameter: pstring("limit"), Reason: "value ten: an invalid integer"}},
+		},
+		{
+			name:           "json patch with an unknown operation",
+			method:         echo.PATCH,
+			path:           "/api/v1/users/" + hexID,
+			body:           `[{"op":"merge","path":"/nickname","value":"jd"}]`,
+			contentType:    patch.MIMEJSONPatch,
+			authenticated:  true,
+			expectedStatus: http.StatusBadRequest,
+			expectedDetail: `request body has an error: doesn't match the schema: Error at "/0/op": value is not one of the allowed values`,
+			expectedErrors: &[]api.FieldError{{Pointer: pstring("/0/op"), Reason: "value is not one of the allowed values"}},
+		},
+		{
+			name:           "streamed import file over the limit",
+			method:         echo.POST,
+			path:           "/api/v1/users/import",
+			body:           "first_name\n" + strings.Repeat("john\n", 1<<18),
+			contentType:    importer.MIMECSV,
+			streamed:       true,
+			authenticated:  true,
+			expectedStatus: http.StatusRequestEntityTooLarge,
+			expectedDetail: http.StatusText(http.StatusRequestEntityTooLarge),
+		},
+		{
+			name:           "missing bearer token",
+			method:         echo.GET,
+			path:           "/api/v1/users?page=1&limit=10",
+			expectedStatus: http.StatusUnauthorized,
+			expectedDetail: "missing bearer token",
+		},
+		{
+			name:           "missing bearer token of an invalid request",
+			method:         echo.GET,
+			path:           "/api/v1/users?page=1&limit=ten",
+			expectedStatus: http.StatusUnauthorized,
+			expectedDetail: "missing bearer token",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// the host of the request has to match a server of the specification
+			request := httptest.NewRequest(tt.method, "http://localhost:8000"+tt.path, strings.NewReader(tt.body))
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			if tt.contentType != "" {
+				request.Header.Set(echo.HeaderContentType, tt.contentType)
+			}
+			if tt.streamed {
+				// the body limit is only found out while reading bodies of unknown length
+				request.ContentLength = -1
+			}
+			if tt.authenticated {
+				request.Header.Set(echo.HeaderAuthorization, "Bearer "+accessToken)
+			}
+			response := httptest.NewRecorder()
+
+			router.ServeHTTP(response, request)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+			assertProblem(t, response, api.Error{Detail: tt.expectedDetail, Errors: tt.expectedErrors})
+		})
+	}
+}
+
+func TestHTTPErrorHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+		expectedDetail string
+	}{
+		{
+			name:           "http error",
+			err:            echo.ErrNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectedDetail: "Not Found",
+		},
+		{
+			name:           "unexpected error",
+			err:            errors.New("boom"),
+			expectedStatus: http.StatusInternalServerError,
+			expectedDetail: errInternal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, response := setUpRequest(echo.GET, "/unknown", "")
+
+			HTTPErrorHandler(tt.err, ctx)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+			assertProblem(t, response, api.Error{Detail: tt.expectedDetail})
+		})
+	}
+}
+
+func TestJSONPointer(t *testing.T) {
+	assert.Equal(t, "", jsonPointer(nil))
+	assert.Equal(t, "/roles/0", jsonPointer([]string{"roles", "0"}))
+	assert.Equal(t, "/a~1b/c~0d", jsonPointer([]string{"a/b", "c~d"}))
+}
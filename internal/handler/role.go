@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/permission"
+	"github.com/danielMensah/user-management/internal/repository"
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	errGetRoles     = "failed to get roles"
+	errGetRole      = "failed to get role"
+	errCreateRole   = "failed to create role"
+	errUpdateRole   = "failed to update role"
+	errDeleteRole   = "failed to delete role"
+	errRoleNotFound = "role not found"
+	errRoleExists   = "role already exists"
+	errBuiltInRole  = "built-in roles cannot be changed"
+)
+
+// GetRoles returns the built-in and custom roles
+func (h *Handler) GetRoles(ctx echo.Context) error {
+	if httpErr := h.requirePermission(ctx, permission.RolesRead); httpErr != nil {
+		return renderError(ctx, httpErr)
+	}
+
+	custom, err := h.repo.GetRoles(ctx.Request().Context())
+	if err != nil {
+		return renderServerError(ctx, err, errGetRoles)
+	}
+
+	return ctx.JSON(http.StatusOK, api.GetRolesResponse{Roles: append(permission.BuiltInRoles(), custom...)})
+}
+
+// GetRole returns a built-in or custom role
+func (h *Handler) GetRole(ctx echo.Context, name string) error {
+	if httpErr := h.requirePermission(ctx, permission.RolesRead); httpErr != nil {
+		return renderError(ctx, httpErr)
+	}
+
+	for _, role := range permission.BuiltInRoles() {
+		if role.Name == name {
+			return ctx.JSON(http.StatusOK, role)
+		}
+	}
+
+	roles, err := h.repo.GetRolesByName(ctx.Request().Context(), []string{name})
+	if err != nil {
+		return renderServerError(ctx, err, errGetRole)
+	}
+	if len(roles) == 0 {
+		return renderProblem(ctx, http.StatusNotFound, errRoleNotFound)
+	}
+
+	return ctx.JSON(http.StatusOK, roles[0])
+}
+
+// CreateRole creates a custom role
+func (h *Handler) CreateRole(ctx echo.Context) error {
+	if httpErr := h.requireSensitivePermission(ctx, permission.RolesManage); httpErr != nil {
+		return renderError(ctx, httpErr)
+	}
+
+	body := new(api.RoleCreateData)
+	if err := ctx.Bind(body); err != nil {
+		logrus.WithError(err).Error(errParseBody)
+		return renderProblem(ctx, http.StatusBadRequest, errParseBody)
+	}
+
+	if permission.IsBuiltIn(body.Name) {
+		return renderProblem(ctx, http.StatusConflict, errRoleExists)
+	}
+
+	role, err := h.repo.CreateRole(ctx.Request().Context(), body)
+	if err != nil {
+		if errors.Is(err, repository.ErrConflict) {
+			return renderProblem(ctx, http.StatusConflict, errRoleExists)
+		}
+
+		return renderServerError(ctx, err, errCreateRole)
+	}
+
+	return ctx.JSON(http.StatusCreated, role)
+}
+
+// UpdateRole replaces the description and permissions of a custom role
+func (h *Handler) UpdateRole(ctx echo.Context, name string) error {
+	if httpErr := h.requireSensitivePermission(ctx, permission.RolesManage); httpErr != nil {
+		return renderError(ctx, httpErr)
+	}
+
+	if permission.IsBuiltIn(name) {
+		return renderProblem(ctx, http.StatusBadRequest, errBuiltInRole)
+	}
+
+	body := new(api.RoleUpdateData)
+	if err := ctx.Bind(body); err != nil {
+		logrus.WithError(err).Error(errParseBody)
+		return renderProblem(ctx, http.StatusBadRequest, errParseBody)
+	}
+
+	role, err := h.repo.UpdateRole(ctx.Request().Context(), name, body)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return renderProblem(ctx, http.StatusNotFound, errRoleNotFound)
+		}
+
+		return renderServerError(ctx, err, errUpdateRole)
+	}
+
+	return ctx.JSON(http.StatusOK, role)
+}
+
+// DeleteRole deletes a custom role
+func (h *Handler) DeleteRole(ctx echo.Context, name string) error {
+	if httpErr := h.requireSensitivePermission(ctx, permission.RolesManage); httpErr != nil {
+		return renderError(ctx, httpErr)
+	}
+
+	if permission.IsBuiltIn(name) {
+		return renderProblem(ctx, http.StatusBadRequest, errBuiltInRole)
+	}
+
+	if err := h.repo.DeleteRole(ctx.Request().Context(), name); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return renderProblem(ctx, http.StatusNotFound, errRoleNotFound)
+		}
+
+		return renderServerError(ctx, err, errDeleteRole)
+	}
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// unknownRoles returns the given roles that are neither built-in nor custom roles
+func (h *Handler) unknownRoles(ctx context.Context, roles api.Roles) ([]string, error) {
+	custom := make([]string, 0, len(roles))
+	for _, role := range roles {
+		if !permission.IsBuiltIn(role) {
+			custom = append(custom, role)
+		}
+	}
+
+	if len(custom) == 0 {
+		return nil, nil
+	}
+
+	definitions, err := h.repo.GetRolesByName(ctx, custom)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(definitions))
+	for _, definition := range definitions {
+		known[definition.Name] = true
+	}
+
+	unknown := make([]string, 0)
+	for _, role := range custom {
+		if !known[role] {
+			unknown = append(unknown, role)
+		}
+	}
+
+	return unknown, nil
+}
@@ -0,0 +1,446 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/auth"
+	"github.com/danielMensah/user-management/internal/config"
+	"github.com/danielMensah/user-management/internal/permission"
+	mongoRepo "github.com/danielMensah/user-management/internal/repository/mongo"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestHandler_GetRoles(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	supportAgent := api.RoleDefinition{Name: "support-agent", Permissions: []api.Permission{"users:read"}}
+
+	tests := []struct {
+		name             string
+		mockResponses    []bson.D
+		caller           *auth.Claims
+		expectedStatus   int
+		expectedResponse api.GetRolesResponse
+		expectedErr      api.Error
+	}{
+		{
+			name: "returns built-in and custom roles",
+			mockResponses: []bson.D{
+				mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{
+					{"_id", "support-agent"},
+					{"permissions", bson.A{"users:read"}},
+				}),
+			},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: api.GetRolesResponse{Roles: append(permission.BuiltInRoles(), supportAgent)},
+		},
+		{
+			name:           "custom role granting roles:read",
+			caller:         newClaims(hexID, "UK", "auditor"),
+			expectedStatus: http.StatusOK,
+			mockResponses: []bson.D{
+				mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{
+					{"_id", "auditor"},
+					{"permissions", bson.A{"roles:read"}},
+				}),
+				mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch),
+			},
+			expectedResponse: api.GetRolesResponse{Roles: permission.BuiltInRoles()},
+		},
+		{
+			name:           "user cannot read roles",
+			caller:         newClaims(hexID, "UK", permission.RoleUser),
+			expectedStatus: http.StatusForbidden,
+			expectedErr: api.Error{
+				Detail: errForbidden,
+			},
+		},
+		{
+			name:           "error resolving permissions",
+			caller:         newClaims(hexID, "UK", "auditor"),
+			mockResponses:  []bson.D{{{"ok", 0}}},
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr: api.Error{
+				Detail: errResolvePermissions,
+			},
+		},
+		{
+			name:           "error getting roles",
+			mockResponses:  []bson.D{{{"ok", 0}}},
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr: api.Error{
+				Detail: errGetRoles,
+			},
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponses...)
+
+			h := New(mongoRepo.New(mt.DB), nil, nil, testPasswords, newPasswordPolicy(t), newCursorCodec(t), nil, &config.Config{})
+
+			ctx, response := setUpRequest(echo.GET, "/roles", "")
+			authenticate(ctx, tt.caller)
+
+			err := h.GetRoles(ctx)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+
+			if tt.expectedErr.Detail != "" {
+				assertProblem(t, response, tt.expectedErr)
+			} else {
+				var responseBody api.GetRolesResponse
+				err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+				require.NoError(t, err)
+
+				assert.Equal(t, tt.expectedResponse, responseBody)
+			}
+		})
+	}
+}
+
+func TestHandler_GetRole(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tests := []struct {
+		name             string
+		roleName         string
+		mockResponse     bson.D
+		expectedStatus   int
+		expectedResponse api.RoleDefinition
+		expectedErr      api.Error
+	}{
+		{
+			name:             "returns built-in role",
+			roleName:         permission.RoleUser,
+			expectedStatus:   http.StatusOK,
+			expectedResponse: permission.BuiltInRoles()[2],
+		},
+		{
+			name:     "returns custom role",
+			roleName: "support-agent",
+			mockResponse: mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{
+				{"_id", "support-agent"},
+				{"permissions", bson.A{"users:read"}},
+			}),
+			expectedStatus:   http.StatusOK,
+			expectedResponse: api.RoleDefinition{Name: "support-agent", Permissions: []api.Permission{"users:read"}},
+		},
+		{
+			name:           "role not found",
+			roleName:       "support-agent",
+			mockResponse:   mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch),
+			expectedStatus: http.StatusNotFound,
+			expectedErr: api.Error{
+				Detail: errRoleNotFound,
+			},
+		},
+		{
+			name:           "error getting role",
+			roleName:       "support-agent",
+			mockResponse:   bson.D{{"ok", 0}},
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr: api.Error{
+				Detail: errGetRole,
+			},
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			h := New(mongoRepo.New(mt.DB), nil, nil, testPasswords, newPasswordPolicy(t), newCursorCodec(t), nil, &config.Config{})
+
+			ctx, response := setUpRequest(echo.GET, "/roles/:name", "")
+			authenticate(ctx, nil)
+
+			err := h.GetRole(ctx, tt.roleName)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+
+			if tt.expectedErr.Detail != "" {
+				assertProblem(t, response, tt.expectedErr)
+			} else {
+				var responseBody api.RoleDefinition
+				err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+				require.NoError(t, err)
+
+				assert.Equal(t, tt.expectedResponse, responseBody)
+			}
+		})
+	}
+}
+
+func TestHandler_CreateRole(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tests := []struct {
+		name           string
+		body           string
+		mockResponse   bson.D
+		caller         *auth.Claims
+		expectedStatus int
+		expectedErr    api.Error
+	}{
+		{
+			name:           "creates role",
+			body:           `{"name":"support-agent","permissions":["users:read","users:update:profile"]}`,
+			mockResponse:   bson.D{{"ok", 1}},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "manager cannot create roles",
+			body:           `{"name":"support-agent","permissions":["users:read"]}`,
+			caller:         newClaims(hexID, "UK", permission.RoleManager),
+			expectedStatus: http.StatusForbidden,
+			expectedErr: api.Error{
+				Detail: errForbidden,
+			},
+		},
+		{
+			name:           "invalid request",
+			body:           `{"`,
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errParseBody,
+			},
+		},
+		{
+			name:           "built-in role name",
+			body:           `{"name":"admin","permissions":["users:read"]}`,
+			expectedStatus: http.StatusConflict,
+			expectedErr: api.Error{
+				Detail: errRoleExists,
+			},
+		},
+		{
+			name: "duplicate role name",
+			body: `{"name":"support-agent","permissions":["users:read"]}`,
+			mockResponse: mtest.CreateWriteErrorsResponse(mtest.WriteError{
+				Index:   0,
+				Code:    11000,
+				Message: "duplicate key error",
+			}),
+			expectedStatus: http.StatusConflict,
+			expectedErr: api.Error{
+				Detail: errRoleExists,
+			},
+		},
+		{
+			name:           "error creating role",
+			body:           `{"name":"support-agent","permissions":["users:read"]}`,
+			mockResponse:   bson.D{{"ok", 0}},
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr: api.Error{
+				Detail: errCreateRole,
+			},
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			h := New(mongoRepo.New(mt.DB), nil, nil, testPasswords, newPasswordPolicy(t), newCursorCodec(t), nil, &config.Config{})
+
+			ctx, response := setUpRequest(echo.POST, "/roles", tt.body)
+			authenticate(ctx, tt.caller)
+
+			err := h.CreateRole(ctx)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+
+			if tt.expectedErr.Detail != "" {
+				assertProblem(t, response, tt.expectedErr)
+			} else {
+				var responseBody api.RoleDefinition
+				err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+				require.NoError(t, err)
+
+				assert.Equal(t, "support-agent", responseBody.Name)
+				assert.Equal(t, []api.Permission{"users:read", "users:update:profile"}, responseBody.Permissions)
+				assert.False(t, responseBody.BuiltIn)
+			}
+		})
+	}
+}
+
+func TestHandler_UpdateRole(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tests := []struct {
+		name             string
+		roleName         string
+		body             string
+		mockResponse     bson.D
+		expectedStatus   int
+		expectedResponse api.RoleDefinition
+		expectedErr      api.Error
+	}{
+		{
+			name:     "updates role",
+			roleName: "support-agent",
+			body:     `{"permissions":["users:read"]}`,
+			mockResponse: bson.D{
+				{"ok", 1},
+				{"value", bson.D{
+					{"_id", "support-agent"},
+					{"permissions", bson.A{"users:read"}},
+				}},
+			},
+			expectedStatus:   http.StatusOK,
+			expectedResponse: api.RoleDefinition{Name: "support-agent", Permissions: []api.Permission{"users:read"}},
+		},
+		{
+			name:           "built-in role",
+			roleName:       permission.RoleAdmin,
+			body:           `{"permissions":["users:read"]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errBuiltInRole,
+			},
+		},
+		{
+			name:           "invalid request",
+			roleName:       "support-agent",
+			body:           `{"`,
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errParseBody,
+			},
+		},
+		{
+			name:           "role not found",
+			roleName:       "support-agent",
+			body:           `{"permissions":["users:read"]}`,
+			mockResponse:   bson.D{{"ok", 1}, {"value", nil}},
+			expectedStatus: http.StatusNotFound,
+			expectedErr: api.Error{
+				Detail: errRoleNotFound,
+			},
+		},
+		{
+			name:           "error updating role",
+			roleName:       "support-agent",
+			body:           `{"permissions":["users:read"]}`,
+			mockResponse:   bson.D{{"ok", 0}},
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr: api.Error{
+				Detail: errUpdateRole,
+			},
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			h := New(mongoRepo.New(mt.DB), nil, nil, testPasswords, newPasswordPolicy(t), newCursorCodec(t), nil, &config.Config{})
+
+			ctx, response := setUpRequest(echo.PUT, "/roles/:name", tt.body)
+			authenticate(ctx, nil)
+
+			err := h.UpdateRole(ctx, tt.roleName)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+
+			if tt.expectedErr.Detail != "" {
+				assertProblem(t, response, tt.expectedErr)
+			} else {
+				var responseBody api.RoleDefinition
+				err = json.Unmarshal(response.Body.Bytes(), &responseBody)
+				require.NoError(t, err)
+
+				assert.Equal(t, tt.expectedResponse, responseBody)
+			}
+		})
+	}
+}
+
+func TestHandler_DeleteRole(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tests := []struct {
+		name           string
+		roleName       string
+		mockResponse   bson.D
+		expectedStatus int
+		expectedErr    api.Error
+	}{
+		{
+			name:           "deletes role",
+			roleName:       "support-agent",
+			mockResponse:   bson.D{{"ok", 1}, {"acknowledged", true}, {"n", 1}},
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "built-in role",
+			roleName:       permission.RoleUser,
+			expectedStatus: http.StatusBadRequest,
+			expectedErr: api.Error{
+				Detail: errBuiltInRole,
+			},
+		},
+		{
+			name:           "role not found",
+			roleName:       "support-agent",
+			mockResponse:   bson.D{{"ok", 1}, {"acknowledged", true}, {"n", 0}},
+			expectedStatus: http.StatusNotFound,
+			expectedErr: api.Error{
+				Detail: errRoleNotFound,
+			},
+		},
+		{
+			name:           "error deleting role",
+			roleName:       "support-agent",
+			mockResponse:   bson.D{{"ok", 0}},
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr: api.Error{
+				Detail: errDeleteRole,
+			},
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			h := New(mongoRepo.New(mt.DB), nil, nil, testPasswords, newPasswordPolicy(t), newCursorCodec(t), nil, &config.Config{})
+
+			ctx, response := setUpRequest(echo.DELETE, "/roles/:name", "")
+			authenticate(ctx, nil)
+
+			err := h.DeleteRole(ctx, tt.roleName)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+
+			if tt.expectedErr.Detail != "" {
+				assertProblem(t, response, tt.expectedErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/query"
+	"github.com/danielMensah/user-management/internal/repository"
+	"github.com/labstack/echo/v4"
+)
+
+const errSearchUsers = "failed to search users"
+
+// SearchUsers returns a page of the users matching a search, the most relevant first. Searches of words too short to
+// be ranked are sorted by nickname.
+func (h *Handler) SearchUsers(ctx echo.Context, params api.SearchUsersParams) error {
+	claims, permissions, httpErr := h.authorize(ctx)
+	if httpErr != nil {
+		return renderError(ctx, httpErr)
+	}
+
+	country, allowed := canListUsers(claims, permissions, params.Country)
+	if !allowed {
+		return renderProblem(ctx, http.StatusForbidden, errForbidden)
+	}
+
+	search := repository.UserSearch{Text: strings.TrimSpace(params.Q), Limit: params.Limit}
+	if country != nil {
+		search.Filter = query.Filter{query.Eq(query.FieldCountry, *country)}
+	}
+	if params.Page != nil {
+		search.Skip = *params.Page
+	}
+
+	reqCtx := ctx.Request().Context()
+
+	page, err := h.repo.SearchUsers(reqCtx, search)
+	if err != nil {
+		return renderServerError(ctx, err, errSearchUsers)
+	}
+
+	response := api.GetUsersResponse{
+		Users:   &page.Users,
+		Page:    skipPageNumber(search.Skip, search.Limit),
+		Limit:   search.Limit,
+		HasMore: page.More,
+	}
+
+	if params.Total == nil || *params.Total {
+		total, err := h.repo.CountSearchedUsers(reqCtx, search)
+		if err != nil {
+			return renderServerError(ctx, err, errSearchUsers)
+		}
+
+		response.Total = &total
+		ctx.Response().Header().Set(headerTotalCount, strconv.FormatInt(total, 10))
+	}
+
+	links := skipLinks(ctx.Request().URL, search.Skip, search.Limit, page.More)
+	ctx.Response().Header().Set(headerLink, links)
+
+	return ctx.JSON(http.StatusOK, response)
+}
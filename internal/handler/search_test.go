@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/auth"
+	"github.com/danielMensah/user-management/internal/config"
+	"github.com/danielMensah/user-management/internal/permission"
+	mongoRepo "github.com/danielMensah/user-management/internal/repository/mongo"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestHandler_SearchUsers(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	user := bson.D{
+		{"_id", hexID},
+		{"first_name", "john"},
+		{"last_name", "doe"},
+		{"nickname", "jd"},
+		{"country", "UK"},
+		{"score", 1.5},
+	}
+	apiUser := api.User{Id: hexID, FirstName: "john", LastName: "doe", Nickname: "jd", Country: "UK"}
+
+	skip := int64(1)
+
+	tests := []struct {
+		name             string
+		target           string
+		params           api.SearchUsersParams
+		mockResponses    []bson.D
+		mockError        bool
+		total            int64
+		expectedStatus   int
+		expectedResponse api.GetUsersResponse
+		expectedTotal    string
+		expectedLink     string
+		expectedText     string
+		expectedCountry  string
+		expectedErr      api.Error
+		caller           *auth.Claims
+	}{
+		{
+			name:           "searches users",
+			target:         "/users/search?q=jon+do&limit=1&page=1",
+			params:         api.SearchUsersParams{Q: " jon do ", Limit: 1, Page: &skip},
+			mockResponses:  []bson.D{user, user},
+			total:          3,
+			expectedStatus: http.StatusOK,
+			expectedResponse: api.GetUsersResponse{
+				Users:   &[]api.User{apiUser},
+				Total:   pint64(3),
+				Page:    pint64(2),
+				Limit:   1,
+				HasMore: true,
+			},
+			expectedTotal: "3",
+			expectedText:  "jon j500 do d000",
+			expectedLink: `</users/search?limit=1&q=jon+do>; rel="first", ` +
+				`</users/search?limit=1&page=2&q=jon+do>; rel="next", ` +
+				`</users/search?limit=1&page=0&q=jon+do>; rel="prev"`,
+		},
+		{
+			name:           "manager searches users of their country without counting them",
+			target:         "/users/search?q=jd&limit=10&total=false",
+			params:         api.SearchUsersParams{Q: "jd", Limit: 10, Total: pbool(false)},
+			caller:         newClaims(hexID, "UK", permission.RoleManager),
+			mockResponses:  []bson.D{user},
+			expectedStatus: http.StatusOK,
+			expectedResponse: api.GetUsersResponse{
+				Users: &[]api.User{apiUser},
+				Page:  pint64(1),
+				Limit: 10,
+			},
+			expectedLink:    `</users/search?limit=10&q=jd&total=false>; rel="first"`,
+			expectedCountry: "UK",
+		},
+		{
+			name:           "manager cannot search users of another country",
+			target:         "/users/search?q=jd&limit=10&country=US",
+			params:         api.SearchUsersParams{Q: "jd", Limit: 10, Country: pstring("US")},
+			caller:         newClaims(hexID, "UK", permission.RoleManager),
+			expectedStatus: http.StatusForbidden,
+			expectedErr: api.Error{
+				Detail: errForbidden,
+			},
+		},
+		{
+			name:           "user cannot search users",
+			target:         "/users/search?q=jd&limit=10",
+			params:         api.SearchUsersParams{Q: "jd", Limit: 10},
+			caller:         newClaims(hexID, "UK", permission.RoleUser),
+			expectedStatus: http.StatusForbidden,
+			expectedErr: api.Error{
+				Detail: errForbidden,
+			},
+		},
+		{
+			name:           "error searching users",
+			target:         "/users/search?q=jd&limit=10",
+			params:         api.SearchUsersParams{Q: "jd", Limit: 10},
+			mockError:      true,
+			expectedStatus: http.StatusInternalServerError,
+			expectedErr: api.Error{
+				Detail: errSearchUsers,
+			},
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			if tt.mockError {
+				mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+					Code:    0,
+					Message: "error",
+					Name:    "foo.bar.error",
+				}))
+			} else {
+				r := make([]bson.D, 0)
+				for i, b := range tt.mockResponses {
+					id := mtest.FirstBatch
+					if i > 0 {
+						id = mtest.NextBatch
+					}
+
+					r = append(r, mtest.CreateCursorResponse(1, "foo.bar", id, b))
+				}
+
+				r = append(r, mtest.CreateCursorResponse(0, "foo.bar", mtest.NextBatch))
+				r = append(r, mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{{"n", tt.total}}))
+				mt.AddMockResponses(r...)
+			}
+
+			repo := mongoRepo.New(mt.DB)
+			h := New(repo, nil, nil, testPasswords, newPasswordPolicy(t), newCursorCodec(t), nil, &config.Config{})
+
+			ctx, response := setUpRequest(echo.GET, tt.target, "")
+			authenticate(ctx, tt.caller)
+
+			err := h.SearchUsers(ctx, tt.params)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+
+			if tt.expectedErr.Detail != "" {
+				assertProblem(t, response, tt.expectedErr)
+				return
+			}
+
+			var responseBody api.GetUsersResponse
+			require.NoError(t, json.Unmarshal(response.Body.Bytes(), &responseBody))
+
+			assert.Equal(t, tt.expectedResponse, responseBody)
+			assert.Equal(t, tt.expectedTotal, response.Header().Get(headerTotalCount))
+			assert.Equal(t, tt.expectedLink, response.Header().Get(headerLink))
+
+			filter := mt.GetStartedEvent().Command.Lookup("filter").Document()
+			if tt.expectedText != "" {
+				assert.Equal(t, tt.expectedText, filter.Lookup("$text", "$search").StringValue())
+			}
+			if tt.expectedCountry != "" {
+				conditions, err := filter.Lookup("$and").Array().Values()
+				require.NoError(t, err)
+				assert.Equal(t, tt.expectedCountry, conditions[0].Document().Lookup("country").StringValue())
+			}
+		})
+	}
+}
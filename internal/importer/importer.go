@@ -0,0 +1,138 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Media types of the files users are imported from
+const (
+	MIMECSV    = "text/csv"
+	MIMENDJSON = "application/x-ndjson"
+)
+
+// MaxRows is the most rows a single file can import. Passwords are hashed one row after another within the request,
+// and 100 argon2id hashes with the default parameters take about 20 seconds.
+const MaxRows = 100
+
+const (
+	// maxLineBytes is the longest line of a NDJSON file
+	maxLineBytes = 1 << 20
+	// byteOrderMark starts the CSV files of some spreadsheet applications
+	byteOrderMark = "\ufeff"
+)
+
+var (
+	// ErrUnsupportedMediaType is returned when reading a file of another media type than the supported ones
+	ErrUnsupportedMediaType = errors.New("unsupported import media type")
+	// ErrInvalidFile is returned when a file cannot be read at all, such as a CSV file without a header
+	ErrInvalidFile = errors.New("invalid import file")
+	// ErrTooManyRows is returned when a file has more than MaxRows rows
+	ErrTooManyRows = errors.New("too many rows to import")
+	// ErrInvalidRow is the error of rows that cannot be decoded, while the other rows of the file still can
+	ErrInvalidRow = errors.New("invalid row")
+)
+
+// Row represents a row of an import file, numbered from 1 in the order of the file, with the fields it sets or the
+// error it could not be decoded with
+type Row struct {
+	Number int
+	Fields map[string]interface{}
+	Err    error
+}
+
+// Read reads the rows of an import file of the given media type. CSV files start with a header naming the field of
+// each column, and NDJSON files have a JSON object per line. Empty CSV cells and blank NDJSON lines are skipped.
+func Read(mediaType string, r io.Reader) ([]Row, error) {
+	switch mediaType {
+	case MIMECSV:
+		return readCSV(r)
+	case MIMENDJSON:
+		return readNDJSON(r)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedMediaType, mediaType)
+	}
+}
+
+func readCSV(r io.Reader) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: no header: %s", ErrInvalidFile, err)
+	}
+	for i, name := range header {
+		header[i] = strings.TrimSpace(strings.TrimPrefix(name, byteOrderMark))
+	}
+
+	rows := make([]Row, 0)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if len(rows) == MaxRows {
+			return nil, fmt.Errorf("%w: more than %d", ErrTooManyRows, MaxRows)
+		}
+
+		var parseErr *csv.ParseError
+		if err != nil && !errors.As(err, &parseErr) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidFile, err)
+		}
+
+		row := Row{Number: len(rows) + 1}
+		if err != nil {
+			row.Err = fmt.Errorf("%w: %s", ErrInvalidRow, err)
+		} else {
+			row.Fields = csvFields(header, record)
+		}
+		rows = append(rows, row)
+	}
+}
+
+// csvFields returns the fields of a CSV record named by the header, leaving out empty cells
+func csvFields(header, record []string) map[string]interface{} {
+	fields := make(map[string]interface{}, len(header))
+	for i, value := range record {
+		if value = strings.TrimSpace(value); value != "" {
+			fields[header[i]] = value
+		}
+	}
+
+	return fields
+}
+
+func readNDJSON(r io.Reader) ([]Row, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineBytes)
+
+	rows := make([]Row, 0)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if len(rows) == MaxRows {
+			return nil, fmt.Errorf("%w: more than %d", ErrTooManyRows, MaxRows)
+		}
+
+		row := Row{Number: len(rows) + 1}
+		if err := json.Unmarshal(line, &row.Fields); err != nil || row.Fields == nil {
+			row.Err = fmt.Errorf("%w: not a JSON object", ErrInvalidRow)
+		}
+		rows = append(rows, row)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFile, err)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,100 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name         string
+		mediaType    string
+		file         string
+		expected     []Row
+		expectedErrs []error
+		expectedErr  error
+	}{
+		{
+			name:      "csv rows named by the header",
+			mediaType: MIMECSV,
+			file: "\ufefffirst_name, email ,country\n" +
+				"John,jd@mensah.com,UK\n" +
+				"\n" +
+				"\"Doe, Jane\",,US\n",
+			expected: []Row{
+				{Number: 1, Fields: map[string]interface{}{"first_name": "John", "email": "jd@mensah.com", "country": "UK"}},
+				{Number: 2, Fields: map[string]interface{}{"first_name": "Doe, Jane", "country": "US"}},
+			},
+		},
+		{
+			name:         "csv rows that cannot be decoded",
+			mediaType:    MIMECSV,
+			file:         "first_name,email\nJohn\nJane,jane@mensah.com\n",
+			expected:     []Row{{Number: 1}, {Number: 2, Fields: map[string]interface{}{"first_name": "Jane", "email": "jane@mensah.com"}}},
+			expectedErrs: []error{ErrInvalidRow, nil},
+		},
+		{
+			name:        "csv without header",
+			mediaType:   MIMECSV,
+			file:        "",
+			expectedErr: ErrInvalidFile,
+		},
+		{
+			name:      "ndjson objects",
+			mediaType: MIMENDJSON,
+			file:      "{\"first_name\":\"John\"}\n\n  \n{\"first_name\":\"Jane\",\"country\":\"US\"}",
+			expected: []Row{
+				{Number: 1, Fields: map[string]interface{}{"first_name": "John"}},
+				{Number: 2, Fields: map[string]interface{}{"first_name": "Jane", "country": "US"}},
+			},
+		},
+		{
+			name:         "ndjson lines that are not objects",
+			mediaType:    MIMENDJSON,
+			file:         "[\"John\"]\n{\"first_name\":\nnull\n",
+			expected:     []Row{{Number: 1}, {Number: 2}, {Number: 3}},
+			expectedErrs: []error{ErrInvalidRow, ErrInvalidRow, ErrInvalidRow},
+		},
+		{
+			name:        "unsupported media type",
+			mediaType:   "application/json",
+			file:        "[]",
+			expectedErr: ErrUnsupportedMediaType,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := Read(tt.mediaType, strings.NewReader(tt.file))
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, rows, len(tt.expected))
+			for i, row := range rows {
+				assert.Equal(t, tt.expected[i].Number, row.Number)
+				if tt.expectedErrs != nil && tt.expectedErrs[i] != nil {
+					assert.ErrorIs(t, row.Err, tt.expectedErrs[i])
+					continue
+				}
+				assert.NoError(t, row.Err)
+				assert.Equal(t, tt.expected[i].Fields, row.Fields)
+			}
+		})
+	}
+}
+
+func TestRead_TooManyRows(t *testing.T) {
+	file := "first_name\n" + strings.Repeat("John\n", MaxRows+1)
+
+	_, err := Read(MIMECSV, strings.NewReader(file))
+	assert.ErrorIs(t, err, ErrTooManyRows)
+
+	rows, err := Read(MIMECSV, strings.NewReader("first_name\n"+strings.Repeat("John\n", MaxRows)))
+	require.NoError(t, err)
+	assert.Len(t, rows, MaxRows)
+}
@@ -0,0 +1,42 @@
+package mail
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	errCreateMailDir = "failed to create mail directory"
+	errWriteMail     = "failed to write mail"
+)
+
+// FileSender writes emails to a directory instead of sending them, which is useful for local development and tests
+type FileSender struct {
+	dir  string
+	from string
+	now  func() time.Time
+}
+
+// NewFileSender creates a new file sender
+func NewFileSender(dir, from string) *FileSender {
+	return &FileSender{dir: dir, from: from, now: time.Now}
+}
+
+// Send writes the message to a new .eml file in the directory of the sender. Files are named after a hash of the
+// recipient, which can hold any character.
+func (s *FileSender) Send(_ context.Context, msg Message) error {
+	if err := os.MkdirAll(s.dir, 0o750); err != nil {
+		return fmt.Errorf("%s '%s': %w", errCreateMailDir, s.dir, err)
+	}
+
+	name := fmt.Sprintf("%d-%x.eml", s.now().UnixNano(), sha256.Sum256([]byte(msg.To)))
+	if err := os.WriteFile(filepath.Join(s.dir, name), format(s.from, msg), 0o600); err != nil {
+		return fmt.Errorf("%s to '%s': %w", errWriteMail, msg.To, err)
+	}
+
+	return nil
+}
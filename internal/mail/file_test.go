@@ -0,0 +1,57 @@
+package mail
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileSender_Send(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+
+	s := NewFileSender(dir, "from@example.com")
+	s.now = func() time.Time { return time.Unix(0, 1) }
+
+	err := s.Send(context.Background(), Message{To: "jd@example.com", Subject: "Hello", Body: "Hi there"})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("1-%x.eml", sha256.Sum256([]byte("jd@example.com")))))
+	require.NoError(t, err)
+
+	assert.Contains(t, string(content), "From: from@example.com\r\n")
+	assert.Contains(t, string(content), "To: jd@example.com\r\n")
+	assert.Contains(t, string(content), "Subject: Hello\r\n")
+	assert.Contains(t, string(content), "\r\n\r\nHi there\r\n")
+}
+
+func TestFileSender_Send_PathTraversal(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "mail")
+
+	err := NewFileSender(dir, "from@example.com").Send(context.Background(), Message{To: "../../jd@example.com"})
+	require.NoError(t, err)
+
+	files, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	outside, err := os.ReadDir(root)
+	require.NoError(t, err)
+	assert.Len(t, outside, 1)
+}
+
+func TestFileSender_Send_Error(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(file, nil, 0o600))
+
+	err := NewFileSender(file, "from@example.com").Send(context.Background(), Message{To: "jd@example.com"})
+
+	assert.Contains(t, err.Error(), errCreateMailDir)
+}
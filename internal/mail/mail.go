@@ -0,0 +1,47 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/danielMensah/user-management/internal/config"
+)
+
+const (
+	driverSMTP = "smtp"
+	driverFile = "file"
+
+	errUnsupportedDriver = "unsupported mail driver"
+)
+
+// Message represents a plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender represents a service able to send emails
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New creates the mail sender selected by the service configuration
+func New(cfg *config.Config) (Sender, error) {
+	switch cfg.MailDriver {
+	case driverSMTP:
+		return NewSMTPSender(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom), nil
+	case driverFile:
+		return NewFileSender(cfg.MailDir, cfg.MailFrom), nil
+	default:
+		return nil, fmt.Errorf("%s: %s", errUnsupportedDriver, cfg.MailDriver)
+	}
+}
+
+// format renders a message as an RFC 5322 email
+func format(from string, msg Message) []byte {
+	return []byte(fmt.Sprintf(
+		"From: %s\r\nTo: %s\r\nSubject: %s\r\nMIME-Version: 1.0\r\nContent-Type: text/plain; charset=\"utf-8\"\r\n\r\n%s\r\n",
+		from, msg.To, msg.Subject, msg.Body,
+	))
+}
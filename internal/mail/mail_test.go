@@ -0,0 +1,49 @@
+package mail
+
+import (
+	"testing"
+
+	"github.com/danielMensah/user-management/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         *config.Config
+		expected    Sender
+		expectedErr string
+	}{
+		{
+			name:     "creates smtp sender",
+			cfg:      &config.Config{MailDriver: "smtp", SMTPHost: "localhost", SMTPPort: "25", MailFrom: "from@example.com"},
+			expected: &SMTPSender{addr: "localhost:25", from: "from@example.com"},
+		},
+		{
+			name: "creates file sender",
+			cfg:  &config.Config{MailDriver: "file", MailDir: "mail", MailFrom: "from@example.com"},
+		},
+		{
+			name:        "errors on unsupported driver",
+			cfg:         &config.Config{MailDriver: "carrier-pigeon"},
+			expectedErr: errUnsupportedDriver,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.cfg)
+
+			switch {
+			case tt.expectedErr != "":
+				assert.Nil(t, got)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+			case tt.expected != nil:
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, got)
+			default:
+				assert.NoError(t, err)
+				assert.IsType(t, &FileSender{}, got)
+			}
+		})
+	}
+}
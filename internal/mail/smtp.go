@@ -0,0 +1,36 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+)
+
+const errSendMail = "failed to send mail"
+
+// SMTPSender sends emails through an SMTP server
+type SMTPSender struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewSMTPSender creates a new SMTP sender. Authentication is skipped when no username is given.
+func NewSMTPSender(host, port, username, password, from string) *SMTPSender {
+	s := &SMTPSender{addr: net.JoinHostPort(host, port), from: from}
+	if username != "" {
+		s.auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return s
+}
+
+// Send sends the message through the SMTP server
+func (s *SMTPSender) Send(_ context.Context, msg Message) error {
+	if err := smtp.SendMail(s.addr, s.auth, s.from, []string{msg.To}, format(s.from, msg)); err != nil {
+		return fmt.Errorf("%s to '%s': %w", errSendMail, msg.To, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/danielMensah/user-management/internal/config"
+)
+
+const (
+	errCursorSecret = "cursor secret must not be empty"
+	errEncodeCursor = "failed to encode cursor"
+)
+
+// ErrInvalidCursor is returned when a cursor is malformed or was not signed by the service
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor represents a position in a sorted list of users, identified by the values of the sort fields of a user
+// written as strings. A cursor points after that user to fetch the next page, or before them to fetch the previous
+// page. It is only valid for the sort it was issued for.
+type Cursor struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v,omitempty"`
+	Before bool     `json:"b,omitempty"`
+}
+
+// End returns a cursor pointing before the end of the list sorted by the given sort, to fetch its last page
+func End(sort string) *Cursor {
+	return &Cursor{Sort: sort, Before: true}
+}
+
+// IsEnd reports whether the cursor points before the end of the list rather than before or after a user
+func (c *Cursor) IsEnd() bool {
+	return len(c.Values) == 0
+}
+
+// CursorCodec encodes cursors as opaque strings signed with HMAC-SHA256, so that clients cannot forge positions
+type CursorCodec struct {
+	secret []byte
+}
+
+// NewCursorCodec creates a new cursor codec from the service configuration
+func NewCursorCodec(cfg *config.Config) (*CursorCodec, error) {
+	if cfg.CursorSecret == "" {
+		return nil, errors.New(errCursorSecret)
+	}
+
+	return &CursorCodec{secret: []byte(cfg.CursorSecret)}, nil
+}
+
+// Encode returns the signed, URL safe representation of a cursor
+func (c *CursorCodec) Encode(cursor *Cursor) (string, error) {
+	payload, err := json.Marshal(cursor)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", errEncodeCursor, err)
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(c.sign(encoded)), nil
+}
+
+// Decode verifies the signature of an encoded cursor and returns it
+func (c *CursorCodec) Decode(encoded string) (*Cursor, error) {
+	payload, signature, found := strings.Cut(encoded, ".")
+	if !found {
+		return nil, ErrInvalidCursor
+	}
+
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, c.sign(payload)) {
+		return nil, ErrInvalidCursor
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	cursor := &Cursor{}
+	if err = json.Unmarshal(data, cursor); err != nil || (cursor.IsEnd() && !cursor.Before) {
+		return nil, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+func (c *CursorCodec) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(payload))
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,88 @@
+package pagination
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/danielMensah/user-management/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCursorCodec(t *testing.T) {
+	_, err := NewCursorCodec(&config.Config{})
+	assert.EqualError(t, err, errCursorSecret)
+
+	_, err = NewCursorCodec(&config.Config{CursorSecret: "secret"})
+	assert.NoError(t, err)
+}
+
+func TestCursorCodec_Decode(t *testing.T) {
+	codec, err := NewCursorCodec(&config.Config{CursorSecret: "secret"})
+	require.NoError(t, err)
+
+	cursor := &Cursor{
+		Sort:   "-created_at,-_id",
+		Values: []string{"2022-09-01T10:00:00Z", "630f5b8a9d1c4b2e8f7a6b5c"},
+		Before: true,
+	}
+	encoded, err := codec.Encode(cursor)
+	require.NoError(t, err)
+
+	otherCodec, err := NewCursorCodec(&config.Config{CursorSecret: "other"})
+	require.NoError(t, err)
+	forged, err := otherCodec.Encode(&Cursor{Sort: cursor.Sort, Values: cursor.Values})
+	require.NoError(t, err)
+
+	end, err := codec.Encode(End(cursor.Sort))
+	require.NoError(t, err)
+
+	payload, signature, _ := strings.Cut(encoded, ".")
+	tamperedPayload, _, _ := strings.Cut(forged, ".")
+
+	tests := []struct {
+		name           string
+		encoded        string
+		expectedCursor *Cursor
+		expectedErr    error
+	}{
+		{
+			name:           "decodes cursor",
+			encoded:        encoded,
+			expectedCursor: cursor,
+		},
+		{
+			name:           "decodes end cursor",
+			encoded:        end,
+			expectedCursor: End(cursor.Sort),
+		},
+		{
+			name:        "signed with another secret",
+			encoded:     forged,
+			expectedErr: ErrInvalidCursor,
+		},
+		{
+			name:        "tampered payload",
+			encoded:     tamperedPayload + "." + signature,
+			expectedErr: ErrInvalidCursor,
+		},
+		{
+			name:        "missing signature",
+			encoded:     payload,
+			expectedErr: ErrInvalidCursor,
+		},
+		{
+			name:        "malformed cursor",
+			encoded:     "not-a-cursor",
+			expectedErr: ErrInvalidCursor,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := codec.Decode(tt.encoded)
+
+			assert.ErrorIs(t, err, tt.expectedErr)
+			assert.Equal(t, tt.expectedCursor, decoded)
+		})
+	}
+}
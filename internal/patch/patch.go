@@ -0,0 +1,357 @@
+package patch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Media types of patches
+const (
+	// MIMEMergePatch is the media type of JSON merge patches defined by RFC 7396
+	MIMEMergePatch = "application/merge-patch+json"
+	// MIMEJSONPatch is the media type of JSON patches defined by RFC 6902
+	MIMEJSONPatch = "application/json-patch+json"
+)
+
+// Operations of JSON patches
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+	OpMove    = "move"
+	OpCopy    = "copy"
+	OpTest    = "test"
+)
+
+var (
+	// ErrInvalidPatch is returned when a patch is malformed or cannot be applied to the document
+	ErrInvalidPatch = errors.New("invalid patch")
+	// ErrTestFailed is returned when a test operation of a JSON patch does not hold
+	ErrTestFailed = errors.New("patch test failed")
+)
+
+// Operation represents an operation of a JSON patch. Value is nil when the operation has no value, and the JSON null
+// literal when its value is null.
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Merge applies a JSON merge patch to a document decoded from JSON, and returns the patched document. The document
+// is left untouched.
+func Merge(document interface{}, patch []byte) (interface{}, error) {
+	var decoded interface{}
+	if err := json.Unmarshal(patch, &decoded); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPatch, err)
+	}
+
+	return merge(deepCopy(document), decoded), nil
+}
+
+// merge applies a merge patch to a target as described by RFC 7396: objects are merged member by member, null
+// removes a member, and any other value replaces the target
+func merge(target, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]interface{})
+	if !ok {
+		targetObject = map[string]interface{}{}
+	}
+
+	for name, value := range patchObject {
+		if value == nil {
+			delete(targetObject, name)
+			continue
+		}
+		targetObject[name] = merge(targetObject[name], value)
+	}
+
+	return targetObject
+}
+
+// Apply applies the operations of a JSON patch in order to a document decoded from JSON, and returns the patched
+// document. The patch is atomic: the document is left untouched when an operation fails.
+func Apply(document interface{}, patch []byte) (interface{}, error) {
+	var operations []Operation
+	if err := json.Unmarshal(patch, &operations); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPatch, err)
+	}
+
+	patched := deepCopy(document)
+	for i, operation := range operations {
+		var err error
+		if patched, err = operation.apply(patched); err != nil {
+			return nil, fmt.Errorf("operation %d: %w", i, err)
+		}
+	}
+
+	return patched, nil
+}
+
+func (o Operation) apply(document interface{}) (interface{}, error) {
+	path, err := parsePointer(o.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch o.Op {
+	case OpAdd, OpReplace, OpTest:
+		value, err := o.value()
+		if err != nil {
+			return nil, err
+		}
+		return o.applyValue(document, path, value)
+	case OpRemove:
+		document, _, err = remove(document, path)
+		return document, err
+	case OpMove, OpCopy:
+		return o.applyFrom(document, path)
+	default:
+		return nil, fmt.Errorf("%w: unknown operation %q", ErrInvalidPatch, o.Op)
+	}
+}
+
+// applyValue applies the operations taking a value
+func (o Operation) applyValue(document interface{}, path []string, value interface{}) (interface{}, error) {
+	switch o.Op {
+	case OpAdd:
+		return add(document, path, value)
+	case OpReplace:
+		// replacing the whole document replaces it with the value, as the root cannot be removed
+		if len(path) == 0 {
+			return value, nil
+		}
+		document, _, err := remove(document, path)
+		if err != nil {
+			return nil, err
+		}
+		return add(document, path, value)
+	default:
+		actual, err := get(document, path)
+		if err != nil {
+			return nil, err
+		}
+		if !reflect.DeepEqual(actual, value) {
+			return nil, fmt.Errorf("%w: %s", ErrTestFailed, o.Path)
+		}
+		return document, nil
+	}
+}
+
+// applyFrom applies the operations taking the value at another location
+func (o Operation) applyFrom(document interface{}, path []string) (interface{}, error) {
+	from, err := parsePointer(o.From)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Op == OpCopy {
+		value, err := get(document, from)
+		if err != nil {
+			return nil, err
+		}
+		return add(document, path, deepCopy(value))
+	}
+
+	if o.From != o.Path && strings.HasPrefix(o.Path, o.From+"/") {
+		return nil, fmt.Errorf("%w: cannot move %s into one of its children", ErrInvalidPatch, o.From)
+	}
+
+	document, value, err := remove(document, from)
+	if err != nil {
+		return nil, err
+	}
+
+	return add(document, path, value)
+}
+
+func (o Operation) value() (interface{}, error) {
+	if o.Value == nil {
+		return nil, fmt.Errorf("%w: %s operation without a value", ErrInvalidPatch, o.Op)
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(o.Value, &value); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPatch, err)
+	}
+
+	return value, nil
+}
+
+// parsePointer returns the reference tokens of an RFC 6901 JSON pointer
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w: path %q is not a JSON pointer", ErrInvalidPatch, pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+// get returns the value at the path
+func get(node interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch container := node.(type) {
+		case map[string]interface{}:
+			value, ok := container[token]
+			if !ok {
+				return nil, missing(token)
+			}
+			node = value
+		case []interface{}:
+			index, err := arrayIndex(token, len(container)-1)
+			if err != nil {
+				return nil, err
+			}
+			node = container[index]
+		default:
+			return nil, missing(token)
+		}
+	}
+
+	return node, nil
+}
+
+// add adds a value at the path and returns the updated node. Adding to an object replaces the member, while adding
+// to an array inserts the value, or appends it when the last token is "-".
+func add(node interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	token := path[0]
+	switch container := node.(type) {
+	case map[string]interface{}:
+		if len(path) == 1 {
+			container[token] = value
+			return container, nil
+		}
+		child, ok := container[token]
+		if !ok {
+			return nil, missing(token)
+		}
+		updated, err := add(child, path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		container[token] = updated
+		return container, nil
+	case []interface{}:
+		if len(path) == 1 {
+			index := len(container)
+			if token != "-" {
+				var err error
+				if index, err = arrayIndex(token, len(container)); err != nil {
+					return nil, err
+				}
+			}
+			container = append(container, nil)
+			copy(container[index+1:], container[index:])
+			container[index] = value
+			return container, nil
+		}
+		index, err := arrayIndex(token, len(container)-1)
+		if err != nil {
+			return nil, err
+		}
+		if container[index], err = add(container[index], path[1:], value); err != nil {
+			return nil, err
+		}
+		return container, nil
+	default:
+		return nil, missing(token)
+	}
+}
+
+// remove removes the value at the path, and returns the updated node along with the removed value
+func remove(node interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("%w: cannot remove the whole document", ErrInvalidPatch)
+	}
+
+	token := path[0]
+	switch container := node.(type) {
+	case map[string]interface{}:
+		child, ok := container[token]
+		if !ok {
+			return nil, nil, missing(token)
+		}
+		if len(path) == 1 {
+			delete(container, token)
+			return container, child, nil
+		}
+		updated, removed, err := remove(child, path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		container[token] = updated
+		return container, removed, nil
+	case []interface{}:
+		index, err := arrayIndex(token, len(container)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(path) == 1 {
+			removed := container[index]
+			return append(container[:index], container[index+1:]...), removed, nil
+		}
+		updated, removed, err := remove(container[index], path[1:])
+		if err != nil {
+			return nil, nil, err
+		}
+		container[index] = updated
+		return container, removed, nil
+	default:
+		return nil, nil, missing(token)
+	}
+}
+
+// arrayIndex returns the array index a token refers to, which must not be greater than max
+func arrayIndex(token string, max int) (int, error) {
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 || index > max || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("%w: invalid array index %q", ErrInvalidPatch, token)
+	}
+
+	return index, nil
+}
+
+func missing(token string) error {
+	return fmt.Errorf("%w: %q does not exist", ErrInvalidPatch, token)
+}
+
+// deepCopy copies a document decoded from JSON, so that patches never modify the original
+func deepCopy(node interface{}) interface{} {
+	switch value := node.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(value))
+		for name, child := range value {
+			copied[name] = deepCopy(child)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(value))
+		for i, child := range value {
+			copied[i] = deepCopy(child)
+		}
+		return copied
+	default:
+		return value
+	}
+}
@@ -0,0 +1,150 @@
+package patch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const user = `{"first_name":"john","nickname":"jd","roles":["user","manager"],"address":{"city":"London"}}`
+
+func decode(t *testing.T, document string) interface{} {
+	var decoded interface{}
+	require.NoError(t, json.Unmarshal([]byte(document), &decoded))
+	return decoded
+}
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name     string
+		patch    string
+		expected string
+	}{
+		{
+			name:     "replaces and removes members",
+			patch:    `{"first_name":"jane","nickname":null,"country":"UK"}`,
+			expected: `{"first_name":"jane","country":"UK","roles":["user","manager"],"address":{"city":"London"}}`,
+		},
+		{
+			name:     "merges nested objects",
+			patch:    `{"address":{"city":null,"zip":"E1"}}`,
+			expected: `{"first_name":"john","nickname":"jd","roles":["user","manager"],"address":{"zip":"E1"}}`,
+		},
+		{
+			name:     "replaces arrays",
+			patch:    `{"roles":["admin"]}`,
+			expected: `{"first_name":"john","nickname":"jd","roles":["admin"],"address":{"city":"London"}}`,
+		},
+		{
+			name:     "replaces the document with anything but an object",
+			patch:    `["jd"]`,
+			expected: `["jd"]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			document := decode(t, user)
+
+			got, err := Merge(document, []byte(tt.patch))
+			require.NoError(t, err)
+
+			assert.Equal(t, decode(t, tt.expected), got)
+			assert.Equal(t, decode(t, user), document)
+		})
+	}
+
+	_, err := Merge(decode(t, user), []byte(`{"first_name":`))
+	assert.ErrorIs(t, err, ErrInvalidPatch)
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name        string
+		patch       string
+		expected    string
+		expectedErr error
+	}{
+		{
+			name: "applies operations in order",
+			patch: `[
+				{"op":"test","path":"/nickname","value":"jd"},
+				{"op":"replace","path":"/first_name","value":""},
+				{"op":"add","path":"/roles/1","value":"admin"},
+				{"op":"add","path":"/roles/-","value":"owner"},
+				{"op":"remove","path":"/roles/0"},
+				{"op":"copy","from":"/address/city","path":"/city"},
+				{"op":"move","from":"/nickname","path":"/address/nickname"}
+			]`,
+			expected: `{"first_name":"","city":"London","roles":["admin","manager","owner"],` +
+				`"address":{"city":"London","nickname":"jd"}}`,
+		},
+		{
+			name:     "unescapes pointers",
+			patch:    `[{"op":"add","path":"/a~1b~0c","value":null}]`,
+			expected: `{"first_name":"john","nickname":"jd","roles":["user","manager"],"address":{"city":"London"},"a/b~c":null}`,
+		},
+		{
+			name:     "replaces the whole document",
+			patch:    `[{"op":"replace","path":"","value":{"nickname":"jdoe"}}]`,
+			expected: `{"nickname":"jdoe"}`,
+		},
+		{
+			name:        "failed test",
+			patch:       `[{"op":"replace","path":"/nickname","value":"j"},{"op":"test","path":"/nickname","value":"jd"}]`,
+			expectedErr: ErrTestFailed,
+		},
+		{
+			name:        "replaces a missing member",
+			patch:       `[{"op":"replace","path":"/country","value":"UK"}]`,
+			expectedErr: ErrInvalidPatch,
+		},
+		{
+			name:        "adds beyond the end of an array",
+			patch:       `[{"op":"add","path":"/roles/3","value":"admin"}]`,
+			expectedErr: ErrInvalidPatch,
+		},
+		{
+			name:        "moves a member into its children",
+			patch:       `[{"op":"move","from":"/address","path":"/address/home"}]`,
+			expectedErr: ErrInvalidPatch,
+		},
+		{
+			name:        "operation without a value",
+			patch:       `[{"op":"add","path":"/country"}]`,
+			expectedErr: ErrInvalidPatch,
+		},
+		{
+			name:        "unknown operation",
+			patch:       `[{"op":"merge","path":"/country","value":"UK"}]`,
+			expectedErr: ErrInvalidPatch,
+		},
+		{
+			name:        "path is not a pointer",
+			patch:       `[{"op":"remove","path":"nickname"}]`,
+			expectedErr: ErrInvalidPatch,
+		},
+		{
+			name:        "malformed patch",
+			patch:       `{"op":"remove","path":"/nickname"}`,
+			expectedErr: ErrInvalidPatch,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			document := decode(t, user)
+
+			got, err := Apply(document, []byte(tt.patch))
+			assert.Equal(t, decode(t, user), document)
+
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, decode(t, tt.expected), got)
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package permission
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/danielMensah/user-management/internal/api"
+)
+
+// Permission represents a named permission made of colon separated segments, such as "users:update:self".
+// A permission grants every narrower permission, so "users:update" grants "users:update:self", and a "*"
+// segment matches any segment.
+type Permission string
+
+// Permissions required by the operations of the service
+const (
+	UsersRead          Permission = "users:read"
+	UsersReadCountry   Permission = "users:read:country"
+	UsersReadSelf      Permission = "users:read:self"
+	UsersUpdate        Permission = "users:update"
+	UsersUpdateProfile Permission = "users:update:profile"
+	UsersUpdateSelf    Permission = "users:update:self"
+	UsersDelete        Permission = "users:delete"
+	UsersDeleteSelf    Permission = "users:delete:self"
+	UsersRolesAssign   Permission = "users:roles:assign"
+	UsersUnlock        Permission = "users:unlock"
+	UsersRestore       Permission = "users:restore"
+	UsersImport        Permission = "users:import"
+	RolesRead          Permission = "roles:read"
+	RolesManage        Permission = "roles:manage"
+
+	wildcard  = "*"
+	separator = ":"
+)
+
+// Built-in roles, which cannot be changed or deleted
+const (
+	RoleAdmin   = "admin"
+	RoleManager = "manager"
+	RoleUser    = "user"
+)
+
+const errResolveRoles = "failed to resolve roles"
+
+// builtInRoles holds the permissions of the built-in roles
+var builtInRoles = map[string][]Permission{
+	RoleAdmin:   {wildcard},
+	RoleManager: {UsersReadCountry, UsersReadSelf, UsersUpdateSelf, UsersDeleteSelf},
+	RoleUser:    {UsersReadSelf, UsersUpdateSelf, UsersDeleteSelf},
+}
+
+// IsBuiltIn reports whether the given role is a built-in role
+func IsBuiltIn(role string) bool {
+	_, ok := builtInRoles[role]
+	return ok
+}
+
+// BuiltInRoles returns the definitions of the built-in roles
+func BuiltInRoles() []api.RoleDefinition {
+	roles := make([]api.RoleDefinition, 0, len(builtInRoles))
+	for _, name := range []string{RoleAdmin, RoleManager, RoleUser} {
+		roles = append(roles, builtInRole(name))
+	}
+
+	return roles
+}
+
+func builtInRole(name string) api.RoleDefinition {
+	permissions := make([]api.Permission, 0, len(builtInRoles[name]))
+	for _, p := range builtInRoles[name] {
+		permissions = append(permissions, string(p))
+	}
+
+	return api.RoleDefinition{Name: name, Permissions: permissions, BuiltIn: true}
+}
+
+// Grants reports whether the granted permission grants the required permission
+func (p Permission) Grants(required Permission) bool {
+	granted := strings.Split(string(p), separator)
+	wanted := strings.Split(string(required), separator)
+
+	if len(granted) > len(wanted) && granted[len(granted)-1] != wildcard {
+		return false
+	}
+
+	for i, segment := range granted {
+		if segment == wildcard && i == len(granted)-1 {
+			return true
+		}
+		if i >= len(wanted) || (segment != wildcard && segment != wanted[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Set represents the permissions granted to a caller
+type Set []Permission
+
+// Allows reports whether any permission of the set grants the required permission
+func (s Set) Allows(required Permission) bool {
+	for _, p := range s {
+		if p.Grants(required) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RoleStore represents the store of the custom roles
+type RoleStore interface {
+	GetRolesByName(ctx context.Context, names []string) ([]api.RoleDefinition, error)
+}
+
+// Evaluator resolves the permissions granted by built-in and custom roles
+type Evaluator struct {
+	store RoleStore
+}
+
+// NewEvaluator creates a new permission evaluator
+func NewEvaluator(store RoleStore) *Evaluator {
+	return &Evaluator{store}
+}
+
+// Permissions returns the permissions granted by the given roles. Unknown roles grant nothing.
+func (e *Evaluator) Permissions(ctx context.Context, roles []string) (Set, error) {
+	set := Set{}
+	custom := make([]string, 0, len(roles))
+
+	for _, role := range roles {
+		if permissions, ok := builtInRoles[role]; ok {
+			set = append(set, permissions...)
+		} else {
+			custom = append(custom, role)
+		}
+	}
+
+	if len(custom) == 0 {
+		return set, nil
+	}
+
+	definitions, err := e.store.GetRolesByName(ctx, custom)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errResolveRoles, err)
+	}
+
+	for _, definition := range definitions {
+		for _, p := range definition.Permissions {
+			set = append(set, Permission(p))
+		}
+	}
+
+	return set, nil
+}
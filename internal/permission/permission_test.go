@@ -0,0 +1,97 @@
+package permission
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/stretchr/testify/assert"
+)
+
+type roleStore struct {
+	roles []api.RoleDefinition
+	err   error
+}
+
+func (s *roleStore) GetRolesByName(_ context.Context, _ []string) ([]api.RoleDefinition, error) {
+	return s.roles, s.err
+}
+
+func TestPermission_Grants(t *testing.T) {
+	tests := []struct {
+		granted  Permission
+		required Permission
+		expected bool
+	}{
+		{granted: UsersRead, required: UsersRead, expected: true},
+		{granted: UsersRead, required: UsersReadCountry, expected: true},
+		{granted: UsersReadCountry, required: UsersRead},
+		{granted: UsersUpdate, required: UsersUpdateProfile, expected: true},
+		{granted: UsersUpdateSelf, required: UsersUpdateProfile},
+		{granted: UsersRead, required: UsersDelete},
+		{granted: "users:*", required: UsersDeleteSelf, expected: true},
+		{granted: "*", required: RolesManage, expected: true},
+		{granted: "*:read", required: RolesRead, expected: true},
+		{granted: "*:read", required: RolesManage},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.granted)+" "+string(tt.required), func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.granted.Grants(tt.required))
+		})
+	}
+}
+
+func TestEvaluator_Permissions(t *testing.T) {
+	tests := []struct {
+		name        string
+		roles       []string
+		store       *roleStore
+		expected    Set
+		expectedErr string
+	}{
+		{
+			name:     "resolves built-in roles",
+			roles:    []string{RoleUser},
+			store:    &roleStore{err: errors.New("unused")},
+			expected: Set{UsersReadSelf, UsersUpdateSelf, UsersDeleteSelf},
+		},
+		{
+			name:  "resolves custom roles",
+			roles: []string{RoleUser, "support-agent"},
+			store: &roleStore{roles: []api.RoleDefinition{
+				{Name: "support-agent", Permissions: []api.Permission{"users:read"}},
+			}},
+			expected: Set{UsersReadSelf, UsersUpdateSelf, UsersDeleteSelf, UsersRead},
+		},
+		{
+			name:        "errors when custom roles cannot be resolved",
+			roles:       []string{"support-agent"},
+			store:       &roleStore{err: errors.New("boom")},
+			expectedErr: errResolveRoles,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewEvaluator(tt.store).Permissions(context.Background(), tt.roles)
+
+			if tt.expectedErr != "" {
+				assert.Nil(t, got)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestBuiltInRoles(t *testing.T) {
+	roles := BuiltInRoles()
+
+	assert.Len(t, roles, 3)
+	assert.True(t, IsBuiltIn(RoleAdmin))
+	assert.False(t, IsBuiltIn("support-agent"))
+	assert.Equal(t, []api.Permission{"*"}, roles[0].Permissions)
+	assert.True(t, roles[0].BuiltIn)
+}
@@ -0,0 +1,67 @@
+package purge
+
+import (
+	"context"
+	"time"
+
+	"github.com/danielMensah/user-management/internal/config"
+	"github.com/sirupsen/logrus"
+)
+
+const errPurgeUsers = "failed to purge deleted users"
+
+// Repository represents the repository deleted users are purged from
+type Repository interface {
+	PurgeDeletedUsers(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Purger deletes for good the users soft deleted for longer than the retention of deleted users
+type Purger struct {
+	repo      Repository
+	retention time.Duration
+	interval  time.Duration
+	now       func() time.Time
+}
+
+// New creates a purger of the users deleted for longer than the configured retention
+func New(repo Repository, cfg *config.Config) *Purger {
+	return &Purger{
+		repo:      repo,
+		retention: cfg.DeletedUserRetention,
+		interval:  cfg.PurgeInterval,
+		now:       time.Now,
+	}
+}
+
+// Run purges deleted users right away, then every interval until the context is done. Failed purges are logged and
+// retried at the next interval.
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := p.Purge(ctx); err != nil && ctx.Err() == nil {
+			logrus.WithError(err).Error(errPurgeUsers)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge deletes for good the users deleted for longer than the retention, and returns how many were purged
+func (p *Purger) Purge(ctx context.Context) (int64, error) {
+	purged, err := p.repo.PurgeDeletedUsers(ctx, p.now().UTC().Add(-p.retention))
+	if err != nil {
+		return 0, err
+	}
+
+	if purged > 0 {
+		logrus.WithField("purged", purged).Info("purged deleted users")
+	}
+
+	return purged, nil
+}
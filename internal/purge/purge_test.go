@@ -0,0 +1,93 @@
+package purge
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/danielMensah/user-management/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepository struct {
+	purged  int64
+	err     error
+	befores chan time.Time
+}
+
+func (r *fakeRepository) PurgeDeletedUsers(_ context.Context, before time.Time) (int64, error) {
+	r.befores <- before
+	return r.purged, r.err
+}
+
+func TestPurger_Purge(t *testing.T) {
+	now := time.Date(2022, 8, 24, 14, 15, 22, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		purged         int64
+		err            error
+		expectedPurged int64
+		expectedErr    error
+	}{
+		{
+			name:           "purges the users deleted before the retention",
+			purged:         2,
+			expectedPurged: 2,
+		},
+		{
+			name:        "error purging users",
+			err:         errors.New("boom"),
+			expectedErr: errors.New("boom"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeRepository{purged: tt.purged, err: tt.err, befores: make(chan time.Time, 1)}
+
+			p := New(repo, &config.Config{DeletedUserRetention: 720 * time.Hour, PurgeInterval: time.Hour})
+			p.now = func() time.Time { return now }
+
+			purged, err := p.Purge(context.Background())
+			assert.Equal(t, tt.expectedErr, err)
+			assert.Equal(t, tt.expectedPurged, purged)
+			assert.Equal(t, now.Add(-720*time.Hour), <-repo.befores)
+		})
+	}
+}
+
+func TestPurger_Run(t *testing.T) {
+	repo := &fakeRepository{befores: make(chan time.Time)}
+	p := New(repo, &config.Config{DeletedUserRetention: time.Hour, PurgeInterval: time.Millisecond})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.Run(ctx)
+		close(done)
+	}()
+
+	// purges right away, then again every interval
+	for i := 0; i < 2; i++ {
+		select {
+		case <-repo.befores:
+		case <-time.After(time.Second):
+			require.Fail(t, "deleted users were not purged")
+		}
+	}
+
+	cancel()
+	go func() {
+		for range repo.befores {
+		}
+	}()
+
+	select {
+	case <-done:
+		close(repo.befores)
+	case <-time.After(time.Second):
+		require.Fail(t, "purger did not stop")
+	}
+}
@@ -0,0 +1,299 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// ParamFilter is the query param filters are given in
+	ParamFilter = "filter"
+	// ParamSort is the query param the sort is given in
+	ParamSort = "sort"
+	// ParamFields is the query param the fields to return are given in
+	ParamFields = "fields"
+
+	maxInValues = 100
+)
+
+// Type represents the type of the values of a field
+type Type int
+
+// Types of the fields of users
+const (
+	TypeString Type = iota
+	TypeID
+	TypeBool
+	TypeTime
+)
+
+// Operator represents how a filter compares a field to its values
+type Operator string
+
+// Operators of filters
+const (
+	OperatorEq     Operator = "eq"
+	OperatorPrefix Operator = "prefix"
+	OperatorIn     Operator = "in"
+	OperatorGt     Operator = "gt"
+	OperatorGte    Operator = "gte"
+	OperatorLt     Operator = "lt"
+	OperatorLte    Operator = "lte"
+)
+
+// operators lists the operators each type of field can be filtered with
+var operators = map[Type][]Operator{
+	TypeString: {OperatorEq, OperatorPrefix, OperatorIn},
+	TypeID:     {OperatorEq, OperatorIn},
+	TypeBool:   {OperatorEq},
+	TypeTime:   {OperatorEq, OperatorGt, OperatorGte, OperatorLt, OperatorLte},
+}
+
+var idPattern = regexp.MustCompile(`^[0-9a-f]{24}$`)
+
+// Field represents a field of users that can be filtered on, and sorted by when it is sortable. Its name is both the
+// json and the bson name of the field.
+type Field struct {
+	Name     string
+	Type     Type
+	Sortable bool
+}
+
+// Fields of users. Only fields every user has are sortable, so that pages can be cut at any user.
+var (
+	FieldID              = Field{Name: "_id", Type: TypeID, Sortable: true}
+	FieldFirstName       = Field{Name: "first_name", Type: TypeString, Sortable: true}
+	FieldLastName        = Field{Name: "last_name", Type: TypeString, Sortable: true}
+	FieldNickname        = Field{Name: "nickname", Type: TypeString, Sortable: true}
+	FieldEmail           = Field{Name: "email", Type: TypeString, Sortable: true}
+	FieldEmailVerified   = Field{Name: "email_verified", Type: TypeBool}
+	FieldEmailVerifiedAt = Field{Name: "email_verified_at", Type: TypeTime}
+	FieldCountry         = Field{Name: "country", Type: TypeString, Sortable: true}
+	FieldRoles           = Field{Name: "roles", Type: TypeString}
+	FieldCreatedAt       = Field{Name: "created_at", Type: TypeTime, Sortable: true}
+	FieldUpdatedAt       = Field{Name: "updated_at", Type: TypeTime, Sortable: true}
+	FieldDeletedAt       = Field{Name: "deleted_at", Type: TypeTime}
+)
+
+// allFields are the fields of users in the order they are listed
+var allFields = []Field{
+	FieldID, FieldFirstName, FieldLastName, FieldNickname, FieldEmail, FieldEmailVerified, FieldEmailVerifiedAt,
+	FieldCountry, FieldRoles, FieldCreatedAt, FieldUpdatedAt, FieldDeletedAt,
+}
+
+var userFields = map[string]Field{}
+
+func init() {
+	for _, field := range allFields {
+		userFields[field.Name] = field
+	}
+}
+
+// AllFields returns every field of users, in the order they are listed
+func AllFields() []Field {
+	return append([]Field(nil), allFields...)
+}
+
+// Error is returned when a query param is not valid, naming the param and why it is not valid
+type Error struct {
+	Param  string
+	Reason string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("invalid %s: %s", e.Param, e.Reason)
+}
+
+func invalid(param, format string, args ...interface{}) *Error {
+	return &Error{Param: param, Reason: fmt.Sprintf(format, args...)}
+}
+
+// Condition represents a filter on a field of users. Values are typed after the field: strings, bools or times, and
+// ids as strings.
+type Condition struct {
+	Field    Field
+	Operator Operator
+	Values   []interface{}
+}
+
+// Filter represents the conditions users have to meet, all of them
+type Filter []Condition
+
+// Eq returns a condition matching the users whose field equals the value
+func Eq(field Field, value interface{}) Condition {
+	return Condition{Field: field, Operator: OperatorEq, Values: []interface{}{value}}
+}
+
+// ParseFilter parses filter expressions written as field:operator:value, such as nickname:prefix:jd,
+// country:in:UK,US or created_at:gte:2022-01-01T00:00:00Z. Values of in are comma separated.
+func ParseFilter(expressions []string) (Filter, error) {
+	filter := make(Filter, 0, len(expressions))
+	for _, expression := range expressions {
+		parts := strings.SplitN(expression, ":", 3)
+		if len(parts) != 3 {
+			return nil, invalid(ParamFilter, "%q is not written as field:operator:value", expression)
+		}
+
+		field, ok := userFields[parts[0]]
+		if !ok {
+			return nil, invalid(ParamFilter, "unknown field %q", parts[0])
+		}
+
+		operator := Operator(parts[1])
+		if !field.allows(operator) {
+			return nil, invalid(ParamFilter, "field %q cannot be filtered with %q", field.Name, parts[1])
+		}
+
+		rawValues := []string{parts[2]}
+		if operator == OperatorIn {
+			rawValues = strings.Split(parts[2], ",")
+			if len(rawValues) > maxInValues {
+				return nil, invalid(ParamFilter, "in accepts at most %d values", maxInValues)
+			}
+		}
+
+		values := make([]interface{}, 0, len(rawValues))
+		for _, raw := range rawValues {
+			value, err := field.Parse(raw)
+			if err != nil {
+				return nil, invalid(ParamFilter, "%s", err)
+			}
+			values = append(values, value)
+		}
+
+		filter = append(filter, Condition{Field: field, Operator: operator, Values: values})
+	}
+
+	return filter, nil
+}
+
+func (f Field) allows(operator Operator) bool {
+	for _, allowed := range operators[f.Type] {
+		if operator == allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Parse returns the value of the field written as a string
+func (f Field) Parse(raw string) (interface{}, error) {
+	switch f.Type {
+	case TypeID:
+		if !idPattern.MatchString(raw) {
+			return nil, fmt.Errorf("value of %q is not a valid id: %q", f.Name, raw)
+		}
+		return raw, nil
+	case TypeBool:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("value of %q is not a boolean: %q", f.Name, raw)
+		}
+		return value, nil
+	case TypeTime:
+		value, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			return nil, fmt.Errorf("value of %q is not an RFC 3339 date-time: %q", f.Name, raw)
+		}
+		return value.UTC(), nil
+	default:
+		return raw, nil
+	}
+}
+
+// Format returns a value of the field written as a string, the way Parse reads it
+func (f Field) Format(value interface{}) string {
+	if t, ok := value.(time.Time); ok {
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+
+	return fmt.Sprint(value)
+}
+
+// ParseFields returns the fields of users with the given names, in the order they are given and without duplicates.
+// No fields means every field.
+func ParseFields(names []string) ([]Field, error) {
+	fields := make([]Field, 0, len(names))
+	seen := map[string]bool{}
+	for _, name := range names {
+		field, ok := userFields[name]
+		if !ok {
+			return nil, invalid(ParamFields, "unknown field %q", name)
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+// SortField represents a field users are sorted by and its direction
+type SortField struct {
+	Field Field
+	Desc  bool
+}
+
+// Sort represents the fields users are sorted by, in order of precedence. Sorts always end with the id of users so
+// that users are in the same order every time.
+type Sort []SortField
+
+// DefaultSort sorts users from the newest to the oldest
+var DefaultSort = Sort{{Field: FieldCreatedAt, Desc: true}, {Field: FieldID, Desc: true}}
+
+// ParseSort parses a comma separated list of fields, each prefixed with - to sort by it in descending order, such as
+// -created_at,nickname. An empty sort is the default sort.
+func ParseSort(raw string) (Sort, error) {
+	if raw == "" {
+		return DefaultSort, nil
+	}
+
+	names := strings.Split(raw, ",")
+	sort := make(Sort, 0, len(names)+1)
+	seen := map[string]bool{}
+	for _, name := range names {
+		desc := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+
+		field, ok := userFields[name]
+		if !ok {
+			return nil, invalid(ParamSort, "unknown field %q", name)
+		}
+		if !field.Sortable {
+			return nil, invalid(ParamSort, "field %q is not sortable", name)
+		}
+		if seen[name] {
+			return nil, invalid(ParamSort, "field %q is sorted by more than once", name)
+		}
+		seen[name] = true
+
+		sort = append(sort, SortField{Field: field, Desc: desc})
+	}
+
+	if !seen[FieldID.Name] {
+		sort = append(sort, SortField{Field: FieldID, Desc: sort[len(sort)-1].Desc})
+	}
+
+	return sort, nil
+}
+
+// String returns the sort written the way ParseSort reads it
+func (s Sort) String() string {
+	names := make([]string, 0, len(s))
+	for _, field := range s {
+		name := field.Field.Name
+		if field.Desc {
+			name = "-" + name
+		}
+		names = append(names, name)
+	}
+
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,170 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFilter(t *testing.T) {
+	since := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		expressions []string
+		expected    Filter
+		expectedErr *Error
+	}{
+		{
+			name:     "no filter",
+			expected: Filter{},
+		},
+		{
+			name: "parses typed conditions",
+			expressions: []string{
+				"nickname:prefix:jd",
+				"country:in:UK,US",
+				"email_verified:eq:true",
+				"created_at:gte:2022-01-01T00:00:00Z",
+				"_id:eq:630f5b8a9d1c4b2e8f7a6b5c",
+			},
+			expected: Filter{
+				{Field: FieldNickname, Operator: OperatorPrefix, Values: []interface{}{"jd"}},
+				{Field: FieldCountry, Operator: OperatorIn, Values: []interface{}{"UK", "US"}},
+				{Field: FieldEmailVerified, Operator: OperatorEq, Values: []interface{}{true}},
+				{Field: FieldCreatedAt, Operator: OperatorGte, Values: []interface{}{since}},
+				Eq(FieldID, "630f5b8a9d1c4b2e8f7a6b5c"),
+			},
+		},
+		{
+			name:        "values keep their colons",
+			expressions: []string{"first_name:eq:a:b"},
+			expected:    Filter{Eq(FieldFirstName, "a:b")},
+		},
+		{
+			name:        "operators are not taken from values",
+			expressions: []string{"email:eq:$ne"},
+			expected:    Filter{Eq(FieldEmail, "$ne")},
+		},
+		{
+			name:        "malformed expression",
+			expressions: []string{"country=UK"},
+			expectedErr: &Error{Param: ParamFilter, Reason: `"country=UK" is not written as field:operator:value`},
+		},
+		{
+			name:        "unknown field",
+			expressions: []string{"password:eq:secret"},
+			expectedErr: &Error{Param: ParamFilter, Reason: `unknown field "password"`},
+		},
+		{
+			name:        "unknown operator",
+			expressions: []string{"country:$ne:UK"},
+			expectedErr: &Error{Param: ParamFilter, Reason: `field "country" cannot be filtered with "$ne"`},
+		},
+		{
+			name:        "operator of another type",
+			expressions: []string{"created_at:prefix:2022"},
+			expectedErr: &Error{Param: ParamFilter, Reason: `field "created_at" cannot be filtered with "prefix"`},
+		},
+		{
+			name:        "invalid date",
+			expressions: []string{"updated_at:lt:yesterday"},
+			expectedErr: &Error{Param: ParamFilter, Reason: `value of "updated_at" is not an RFC 3339 date-time: "yesterday"`},
+		},
+		{
+			name:        "invalid id",
+			expressions: []string{"_id:in:630f5b8a9d1c4b2e8f7a6b5c,1"},
+			expectedErr: &Error{Param: ParamFilter, Reason: `value of "_id" is not a valid id: "1"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := ParseFilter(tt.expressions)
+
+			if tt.expectedErr != nil {
+				assert.Equal(t, tt.expectedErr, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, filter)
+		})
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         string
+		expected    string
+		expectedErr *Error
+	}{
+		{
+			name:     "default sort",
+			expected: "-created_at,-_id",
+		},
+		{
+			name:     "breaks ties by id in the direction of the last field",
+			raw:      "-updated_at,nickname",
+			expected: "-updated_at,nickname,_id",
+		},
+		{
+			name:     "keeps the id where it is sorted by",
+			raw:      "-_id,country",
+			expected: "-_id,country",
+		},
+		{
+			name:        "unknown field",
+			raw:         "password",
+			expectedErr: &Error{Param: ParamSort, Reason: `unknown field "password"`},
+		},
+		{
+			name:        "unsortable field",
+			raw:         "email_verified_at",
+			expectedErr: &Error{Param: ParamSort, Reason: `field "email_verified_at" is not sortable`},
+		},
+		{
+			name:        "field sorted by twice",
+			raw:         "nickname,-nickname",
+			expectedErr: &Error{Param: ParamSort, Reason: `field "nickname" is sorted by more than once`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sort, err := ParseSort(tt.raw)
+
+			if tt.expectedErr != nil {
+				assert.Equal(t, tt.expectedErr, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, sort.String())
+		})
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	fields, err := ParseFields([]string{"_id", "nickname", "country", "nickname"})
+	assert.NoError(t, err)
+	assert.Equal(t, []Field{FieldID, FieldNickname, FieldCountry}, fields)
+
+	fields, err = ParseFields(nil)
+	assert.NoError(t, err)
+	assert.Empty(t, fields)
+
+	_, err = ParseFields([]string{"_id", "password"})
+	assert.Equal(t, &Error{Param: ParamFields, Reason: `unknown field "password"`}, err)
+}
+
+func TestField_Format(t *testing.T) {
+	at := time.Date(2022, 9, 1, 10, 0, 0, 5, time.FixedZone("CET", 3600))
+
+	formatted := FieldCreatedAt.Format(at)
+	assert.Equal(t, "2022-09-01T09:00:00.000000005Z", formatted)
+
+	parsed, err := FieldCreatedAt.Parse(formatted)
+	assert.NoError(t, err)
+	assert.True(t, at.Equal(parsed.(time.Time)))
+}
@@ -0,0 +1,80 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// insertBatchSize is the most users inserted by a single InsertMany
+const insertBatchSize = 500
+
+// CreateUsers creates users in batches and returns the outcome of each user, in the order they were given. Users that
+// cannot be inserted, such as the ones conflicting with an existing user, do not stop the others from being created.
+// When a batch fails as a whole, its users and the ones after it are not created and have the error of the batch.
+// Users keep their creation date when they have one.
+func (c *Client) CreateUsers(ctx context.Context, users []*api.UserCreateData) ([]repository.CreatedUser, error) {
+	created := make([]repository.CreatedUser, len(users))
+
+	for start := 0; start < len(users); start += insertBatchSize {
+		end := start + insertBatchSize
+		if end > len(users) {
+			end = len(users)
+		}
+
+		if err := c.insertBatch(ctx, users[start:end], created[start:end]); err != nil {
+			for i := start; i < len(users); i++ {
+				created[i] = repository.CreatedUser{Err: err}
+			}
+			break
+		}
+	}
+
+	return created, nil
+}
+
+// insertBatch inserts a batch of users, setting the outcome of each one. It only returns an error when the batch
+// failed as a whole.
+func (c *Client) insertBatch(ctx context.Context, users []*api.UserCreateData, created []repository.CreatedUser) error {
+	now := time.Now().UTC()
+
+	documents := make([]interface{}, len(users))
+	for i, user := range users {
+		if user.CreatedAt == nil {
+			user.CreatedAt = &now
+		}
+		user.UpdatedAt = &now
+
+		oid := primitive.NewObjectID()
+		created[i] = repository.CreatedUser{ID: oid.Hex()}
+		documents[i] = createdUser(oid, user)
+	}
+
+	// unordered inserts go on past the users that fail
+	opts := options.InsertMany().SetOrdered(false)
+
+	_, err := c.db.Collection(collectionUsers).InsertMany(ctx, documents, opts)
+	if err == nil {
+		return nil
+	}
+
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil {
+		return fmt.Errorf("%s: %w", errInsertFailed, repositoryError(err))
+	}
+
+	for _, writeErr := range bulkErr.WriteErrors {
+		created[writeErr.Index] = repository.CreatedUser{
+			Err: fmt.Errorf("%s: %w", errInsertFailed, repositoryError(writeErr.WriteError)),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestClient_CreateUsers(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	createdAt := time.Date(2019, 3, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		users         int
+		mockResponses []bson.D
+		expectedErrs  map[int]error
+	}{
+		{
+			name:          "creates every user",
+			users:         2,
+			mockResponses: []bson.D{{{"ok", 1}, {"n", 2}}},
+		},
+		{
+			name:  "users conflicting with existing ones",
+			users: 3,
+			mockResponses: []bson.D{mtest.CreateWriteErrorsResponse(mtest.WriteError{
+				Index:   1,
+				Code:    11000,
+				Message: `E11000 duplicate key error collection: test.users index: email_deleted_at_unique dup key`,
+			})},
+			expectedErrs: map[int]error{1: &repository.ConflictError{Field: "email"}},
+		},
+		{
+			name:          "batch failing as a whole",
+			users:         2,
+			mockResponses: []bson.D{{{"ok", 0}}},
+			expectedErrs:  map[int]error{0: nil, 1: nil},
+		},
+		{
+			name:          "users after a batch failing as a whole",
+			users:         insertBatchSize + 1,
+			mockResponses: []bson.D{{{"ok", 1}, {"n", insertBatchSize}}, {{"ok", 0}}},
+			expectedErrs:  map[int]error{insertBatchSize: nil},
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponses...)
+
+			users := make([]*api.UserCreateData, tt.users)
+			for i := range users {
+				users[i] = &api.UserCreateData{Email: "jd@mensah.com", Password: "hash"}
+			}
+			users[0].CreatedAt = &createdAt
+
+			c := &Client{db: mt.DB}
+
+			created, err := c.CreateUsers(context.Background(), users)
+			require.NoError(t, err)
+			require.Len(t, created, tt.users)
+
+			for i, user := range created {
+				expectedErr, failed := tt.expectedErrs[i]
+				switch conflict, ok := expectedErr.(*repository.ConflictError); {
+				case ok:
+					var got *repository.ConflictError
+					require.ErrorAs(t, user.Err, &got)
+					assert.Equal(t, conflict.Field, got.Field)
+				case failed:
+					require.Error(t, user.Err)
+					assert.Contains(t, user.Err.Error(), errInsertFailed)
+				default:
+					assert.NoError(t, user.Err)
+					assert.Len(t, user.ID, 24)
+				}
+			}
+
+			command := mt.GetStartedEvent().Command
+			assert.False(t, command.Lookup("ordered").Boolean())
+
+			documents, err := command.Lookup("documents").Array().Values()
+			require.NoError(t, err)
+			if created[0].Err == nil {
+				assert.Equal(t, created[0].ID, documents[0].Document().Lookup("_id").ObjectID().Hex())
+			}
+			assert.Equal(t, createdAt, documents[0].Document().Lookup("created_at").Time().UTC())
+			assert.Equal(t, repository.InitialVersion, documents[0].Document().Lookup("version").Int64())
+		})
+	}
+}
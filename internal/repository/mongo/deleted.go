@@ -0,0 +1,69 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	errRestoreFailed = "failed to restore user in mongo"
+	errPurgeFailed   = "failed to purge deleted users from mongo"
+)
+
+// userFilter returns the filter of the user with the given id, which does not match the user once soft deleted
+func userFilter(pid primitive.ObjectID) bson.M {
+	return excludeDeleted(bson.M{"_id": pid})
+}
+
+// excludeDeleted leaves soft deleted users out of a filter of users. Users that are not deleted have no deletion date.
+func excludeDeleted(filter bson.M) bson.M {
+	filter["deleted_at"] = nil
+
+	return filter
+}
+
+// RestoreUser restores a soft deleted user and returns the restored user. A user whose email or nickname was taken by
+// another user since they were deleted conflicts with that user.
+func (c *Client) RestoreUser(ctx context.Context, id string) (*api.User, error) {
+	opts := options.FindOneAndUpdate()
+	opts.SetReturnDocument(options.After)
+
+	pid, err := objectID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"_id": pid, "deleted_at": bson.M{"$ne": nil}}
+	update := bson.M{
+		"$unset": bson.M{"deleted_at": ""},
+		"$set":   bson.M{"updated_at": time.Now().UTC()},
+		"$inc":   nextVersion,
+	}
+
+	result := c.db.Collection(collectionUsers).FindOneAndUpdate(ctx, filter, update, opts)
+
+	user := &api.User{}
+	if err = result.Decode(user); err != nil {
+		return nil, fmt.Errorf("%s with id '%s': %w", errRestoreFailed, id, repositoryError(err))
+	}
+
+	return user, nil
+}
+
+// PurgeDeletedUsers deletes for good the users soft deleted before the given time, and returns how many were purged
+func (c *Client) PurgeDeletedUsers(ctx context.Context, before time.Time) (int64, error) {
+	filter := bson.M{"deleted_at": bson.M{"$lt": before}}
+
+	result, err := c.db.Collection(collectionUsers).DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", errPurgeFailed, repositoryError(err))
+	}
+
+	return result.DeletedCount, nil
+}
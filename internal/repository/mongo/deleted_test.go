@@ -0,0 +1,130 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/danielMensah/user-management/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestClient_RestoreUser(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tests := []struct {
+		name         string
+		id           string
+		mockResponse bson.D
+		expectedErr  error
+	}{
+		{
+			name:         "restores deleted user",
+			id:           hexID1,
+			mockResponse: bson.D{{"ok", 1}, {"value", bson.D{{"_id", hexID1}, {"email", "jd@mensah.com"}, {"version", 3}}}},
+		},
+		{
+			name:         "user is not deleted",
+			id:           hexID1,
+			mockResponse: bson.D{{"ok", 1}, {"value", nil}},
+			expectedErr:  repository.ErrNotFound,
+		},
+		{
+			name: "email taken since the user was deleted",
+			id:   hexID1,
+			mockResponse: mtest.CreateCommandErrorResponse(mtest.CommandError{
+				Code:    11000,
+				Message: `E11000 duplicate key error collection: test.users index: email_deleted_at_unique dup key`,
+			}),
+			expectedErr: &repository.ConflictError{Field: "email"},
+		},
+		{
+			name:        "invalid id",
+			id:          nonHexID,
+			expectedErr: repository.ErrInvalidID,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			c := &Client{db: mt.DB}
+
+			user, err := c.RestoreUser(context.Background(), tt.id)
+
+			if conflict, ok := tt.expectedErr.(*repository.ConflictError); ok {
+				var got *repository.ConflictError
+				require.ErrorAs(t, err, &got)
+				assert.Equal(t, conflict.Field, got.Field)
+				return
+			}
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, hexID1, user.Id)
+			assert.Equal(t, int64(3), user.Version)
+
+			command := mt.GetStartedEvent().Command
+			assert.Equal(t, bson.TypeNull, command.Lookup("query", "deleted_at", "$ne").Type)
+			assert.Equal(t, "", command.Lookup("update", "$unset", "deleted_at").StringValue())
+			assert.Equal(t, int32(1), command.Lookup("update", "$inc", "version").Int32())
+		})
+	}
+}
+
+func TestClient_PurgeDeletedUsers(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	before := time.Date(2022, 8, 24, 14, 15, 22, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		mockResponse bson.D
+		expected     int64
+		expectedErr  string
+	}{
+		{
+			name:         "purges users deleted before the given time",
+			mockResponse: bson.D{{"ok", 1}, {"n", 3}},
+			expected:     3,
+		},
+		{
+			name:         "error purging users",
+			mockResponse: bson.D{{"ok", 0}},
+			expectedErr:  errPurgeFailed,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			c := &Client{db: mt.DB}
+
+			purged, err := c.PurgeDeletedUsers(context.Background(), before)
+
+			if tt.expectedErr != "" {
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, purged)
+
+			deletes, err := mt.GetStartedEvent().Command.Lookup("deletes").Array().Values()
+			require.NoError(t, err)
+			assert.Equal(t, before, deletes[0].Document().Lookup("q", "deleted_at", "$lt").Time().UTC())
+		})
+	}
+}
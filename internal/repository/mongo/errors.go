@@ -0,0 +1,43 @@
+package mongo
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/danielMensah/user-management/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+)
+
+// repositoryError translates an error of the mongo driver into the matching error of the repository package,
+// keeping the driver error in its message
+func repositoryError(err error) error {
+	var selectionErr topology.ServerSelectionError
+
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return repository.ErrNotFound
+	case mongo.IsDuplicateKeyError(err):
+		if field := duplicateKeyField(err); field != "" {
+			return fmt.Errorf("%w: %s", &repository.ConflictError{Field: field}, err)
+		}
+
+		return fmt.Errorf("%w: %s", repository.ErrConflict, err)
+	case mongo.IsNetworkError(err), mongo.IsTimeout(err), errors.Is(err, mongo.ErrClientDisconnected),
+		errors.As(err, &selectionErr):
+		return fmt.Errorf("%w: %s", repository.ErrUnavailable, err)
+	}
+
+	return err
+}
+
+// objectID converts the hex id of a document into an object id
+func objectID(id string) (primitive.ObjectID, error) {
+	oid, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, fmt.Errorf("%s '%s': %w", errConvertToObjectID, id, repository.ErrInvalidID)
+	}
+
+	return oid, nil
+}
@@ -0,0 +1,67 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/danielMensah/user-management/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestRepositoryError(t *testing.T) {
+	driverErr := errors.New("driver error")
+
+	tests := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{
+			name:     "no documents",
+			err:      mongo.ErrNoDocuments,
+			expected: repository.ErrNotFound,
+		},
+		{
+			name:     "duplicate key",
+			err:      mongo.WriteException{WriteErrors: mongo.WriteErrors{{Code: 11000, Message: "duplicate key"}}},
+			expected: repository.ErrConflict,
+		},
+		{
+			name:     "network error",
+			err:      mongo.CommandError{Message: "connection reset", Labels: []string{"NetworkError"}},
+			expected: repository.ErrUnavailable,
+		},
+		{
+			name:     "timeout",
+			err:      context.DeadlineExceeded,
+			expected: repository.ErrUnavailable,
+		},
+		{
+			name:     "client disconnected",
+			err:      mongo.ErrClientDisconnected,
+			expected: repository.ErrUnavailable,
+		},
+		{
+			name:     "other error",
+			err:      driverErr,
+			expected: driverErr,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.ErrorIs(t, repositoryError(tt.err), tt.expected)
+		})
+	}
+}
+
+func TestObjectID(t *testing.T) {
+	oid, err := objectID(hexID1)
+	assert.NoError(t, err)
+	assert.Equal(t, hexID1, oid.Hex())
+
+	_, err = objectID(nonHexID)
+	assert.ErrorIs(t, err, repository.ErrInvalidID)
+	assert.Contains(t, err.Error(), errConvertToObjectID)
+}
@@ -0,0 +1,75 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/query"
+	"github.com/danielMensah/user-management/internal/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// exportBatchSize is the number of users read from mongo at a time while exporting them
+	exportBatchSize = 1000
+
+	errExportFailed = "failed to export users from mongo"
+)
+
+// secretFields are the fields of stored users that are never exported
+var secretFields = bson.M{"password": 0, "mfa": 0, "lockout": 0}
+
+// ExportUsers reads every user matching the filter of a query in the order of its sort, with only the fields of the
+// query when it has fields, and calls each with them one at a time. Users are read in batches from a cursor rather
+// than all at once. The limit, skip and cursor of the query are ignored. Exporting stops at the first error each
+// returns, which is returned as it is.
+func (c *Client) ExportUsers(ctx context.Context, q repository.UserQuery, each func(user *api.User) error) error {
+	filter, collation, err := usersFilter(q.Filter)
+	if err != nil {
+		return err
+	}
+	if !q.IncludeDeleted {
+		excludeDeleted(filter)
+	}
+
+	opts := options.Find()
+	opts.SetSort(sortDocument(q.Sort, false))
+	opts.SetCollation(collation)
+	opts.SetBatchSize(exportBatchSize)
+	opts.SetProjection(exportProjection(q.Fields))
+
+	cursor, err := c.db.Collection(collectionUsers).Find(ctx, filter, opts)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errRetrieveFailed, repositoryError(err))
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		user := &api.User{}
+		if err = cursor.Decode(user); err != nil {
+			return fmt.Errorf("%s: %w", errExportFailed, err)
+		}
+
+		if err = each(user); err != nil {
+			return err
+		}
+	}
+
+	if err = cursor.Err(); err != nil {
+		return fmt.Errorf("%s: %w", errExportFailed, repositoryError(err))
+	}
+
+	return nil
+}
+
+// exportProjection returns the projection of the given fields, or of every field but the secret ones when none are
+// given
+func exportProjection(fields []query.Field) bson.M {
+	if projected := projection(fields, nil); projected != nil {
+		return projected
+	}
+
+	return secretFields
+}
@@ -0,0 +1,112 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/query"
+	"github.com/danielMensah/user-management/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestClient_ExportUsers(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	john := bson.D{{"_id", hexID1}, {"nickname", "jd"}, {"password", "hash"}}
+	jane := bson.D{{"_id", hexID2}, {"nickname", "jane"}}
+	batches := []bson.D{
+		mtest.CreateCursorResponse(1, "foo.bar", mtest.FirstBatch, john),
+		mtest.CreateCursorResponse(0, "foo.bar", mtest.NextBatch, jane),
+	}
+	errStop := errors.New("stop")
+
+	tests := []struct {
+		name               string
+		query              repository.UserQuery
+		mockResponses      []bson.D
+		eachErr            error
+		expectedIDs        []string
+		expectedProjection bson.M
+		expectedErr        error
+	}{
+		{
+			name:               "exports every user across batches without secrets",
+			query:              repository.UserQuery{Sort: query.DefaultSort},
+			mockResponses:      batches,
+			expectedIDs:        []string{hexID1, hexID2},
+			expectedProjection: bson.M{"password": int32(0), "mfa": int32(0), "lockout": int32(0)},
+		},
+		{
+			name:               "exports the given fields",
+			query:              repository.UserQuery{Sort: query.DefaultSort, Fields: []query.Field{query.FieldNickname}},
+			mockResponses:      batches,
+			expectedIDs:        []string{hexID1, hexID2},
+			expectedProjection: bson.M{"nickname": int32(1)},
+		},
+		{
+			name:          "stops at the first error of each",
+			query:         repository.UserQuery{Sort: query.DefaultSort},
+			mockResponses: batches,
+			eachErr:       errStop,
+			expectedIDs:   []string{hexID1},
+			expectedErr:   errStop,
+		},
+		{
+			name:          "error finding users",
+			query:         repository.UserQuery{Sort: query.DefaultSort},
+			mockResponses: []bson.D{{{"ok", 0}}},
+			expectedErr:   errors.New(errRetrieveFailed),
+		},
+		{
+			name: "invalid id in the filter",
+			query: repository.UserQuery{
+				Filter: query.Filter{query.Eq(query.FieldID, nonHexID)},
+				Sort:   query.DefaultSort,
+			},
+			expectedErr: repository.ErrInvalidID,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponses...)
+
+			c := &Client{db: mt.DB}
+
+			var ids []string
+			err := c.ExportUsers(context.Background(), tt.query, func(user *api.User) error {
+				ids = append(ids, user.Id)
+				return tt.eachErr
+			})
+
+			assert.Equal(t, tt.expectedIDs, ids)
+
+			switch {
+			case tt.expectedErr == nil:
+				require.NoError(t, err)
+			case errors.Is(tt.expectedErr, repository.ErrInvalidID), errors.Is(tt.expectedErr, errStop):
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			default:
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedErr.Error())
+				return
+			}
+
+			command := mt.GetStartedEvent().Command
+			assert.Equal(t, int32(exportBatchSize), command.Lookup("batchSize").Int32())
+			assert.Equal(t, bson.TypeNull, command.Lookup("filter", "deleted_at").Type)
+
+			var projection bson.M
+			require.NoError(t, command.Lookup("projection").Unmarshal(&projection))
+			assert.Equal(t, tt.expectedProjection, projection)
+		})
+	}
+}
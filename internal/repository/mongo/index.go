@@ -0,0 +1,200 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	indexUserEmail    = "email_deleted_at_unique"
+	indexUserNickname = "nickname_deleted_at_unique"
+	indexUserCreated  = "created_at_id"
+	indexUserSearch   = "search_text"
+	indexUserTerms    = "search_terms"
+
+	indexRefreshTokenHash    = "hash_unique"
+	indexRefreshTokenFamily  = "family_id"
+	indexRefreshTokenUser    = "user_id"
+	indexRefreshTokenExpires = "expires_at_ttl"
+
+	indexOneTimeTokenHash    = "hash_purpose"
+	indexOneTimeTokenExpires = "expires_at_ttl"
+
+	// codeIndexNotFound is the code of the error mongo returns when dropping an index that does not exist
+	codeIndexNotFound = 27
+
+	errCreateIndexesFailed = "failed to create indexes in mongo"
+	errDropIndexFailed     = "failed to drop index in mongo"
+)
+
+// caseInsensitive compares strings ignoring case, so that emails differing only by case are the same email
+var caseInsensitive = &options.Collation{Locale: "en", Strength: 2}
+
+// uniqueIndexFields maps the unique indexes of users to the field they index
+var uniqueIndexFields = map[string]string{
+	indexUserEmail:    "email",
+	indexUserNickname: "nickname",
+}
+
+// legacyIndexes are the unique indexes of users from before they were soft deleted, which kept the emails and
+// nicknames of deleted users from being used again
+var legacyIndexes = []string{"email_unique", "nickname_unique"}
+
+// legacySearchIndex is the text index users were searched with before they had search terms. A collection has at most
+// one text index, so it is dropped before the one replacing it is created.
+const legacySearchIndex = "user_search"
+
+// searchWeights ranks the users matching a search by their nickname first, then by their names, then by their email,
+// and last by their search terms, which only match partial or misspelled words
+var searchWeights = bson.D{
+	{Key: "nickname", Value: 10},
+	{Key: "first_name", Value: 5},
+	{Key: "last_name", Value: 5},
+	{Key: "email", Value: 2},
+	{Key: fieldSearchTerms, Value: 1},
+}
+
+// EnsureIndexes creates the unique, case-insensitive indexes of the emails and, when asked to, the nicknames of
+// users, the index users are paged through in, the indexes users are searched with and the indexes of refresh and
+// one-time tokens. Creating an index that already exists is a no-op. The legacy unique indexes are dropped once the
+// ones replacing them exist, and users stored without search terms are given theirs.
+func (c *Client) EnsureIndexes(ctx context.Context, uniqueNicknames bool) error {
+	if err := c.dropIndexes(ctx, legacySearchIndex); err != nil {
+		return err
+	}
+
+	indexes := []mongo.IndexModel{
+		uniqueUserIndex(indexUserEmail),
+		{
+			Keys:    bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}},
+			Options: options.Index().SetName(indexUserCreated),
+		},
+		searchIndex(),
+		{
+			Keys:    bson.D{{Key: fieldSearchTerms, Value: 1}},
+			Options: options.Index().SetName(indexUserTerms),
+		},
+	}
+	if uniqueNicknames {
+		indexes = append(indexes, uniqueUserIndex(indexUserNickname))
+	}
+
+	if _, err := c.db.Collection(collectionUsers).Indexes().CreateMany(ctx, indexes); err != nil {
+		return fmt.Errorf("%s: %w", errCreateIndexesFailed, repositoryError(err))
+	}
+
+	if err := c.dropIndexes(ctx, legacyIndexes...); err != nil {
+		return err
+	}
+
+	if err := c.backfillSearchTerms(ctx); err != nil {
+		return err
+	}
+
+	if _, err := c.db.Collection(collectionRefreshTokens).Indexes().CreateMany(ctx, refreshTokenIndexes()); err != nil {
+		return fmt.Errorf("%s: %w", errCreateIndexesFailed, repositoryError(err))
+	}
+
+	if _, err := c.db.Collection(collectionOneTimeTokens).Indexes().CreateMany(ctx, oneTimeTokenIndexes()); err != nil {
+		return fmt.Errorf("%s: %w", errCreateIndexesFailed, repositoryError(err))
+	}
+
+	return nil
+}
+
+// refreshTokenIndexes returns the indexes refresh tokens are looked up by, and the one deleting them once they expire
+func refreshTokenIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "hash", Value: 1}},
+			Options: options.Index().SetName(indexRefreshTokenHash).SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "family_id", Value: 1}},
+			Options: options.Index().SetName(indexRefreshTokenFamily),
+		},
+		{
+			Keys:    bson.D{{Key: "user_id", Value: 1}},
+			Options: options.Index().SetName(indexRefreshTokenUser),
+		},
+		expiryIndex(indexRefreshTokenExpires),
+	}
+}
+
+// oneTimeTokenIndexes returns the index one-time tokens are looked up by, and the one deleting them once they expire
+func oneTimeTokenIndexes() []mongo.IndexModel {
+	return []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "hash", Value: 1}, {Key: "purpose", Value: 1}},
+			Options: options.Index().SetName(indexOneTimeTokenHash),
+		},
+		expiryIndex(indexOneTimeTokenExpires),
+	}
+}
+
+// expiryIndex returns the index deleting tokens once their expiry date has passed
+func expiryIndex(name string) mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys:    bson.D{{Key: "expires_at", Value: 1}},
+		Options: options.Index().SetName(name).SetExpireAfterSeconds(0),
+	}
+}
+
+// uniqueUserIndex returns the index keeping a field unique among the users that are not soft deleted. Users that are
+// not deleted have no deletion date, indexed as null, while each deleted user has their own deletion date.
+func uniqueUserIndex(name string) mongo.IndexModel {
+	return mongo.IndexModel{
+		Keys:    bson.D{{Key: uniqueIndexFields[name], Value: 1}, {Key: "deleted_at", Value: 1}},
+		Options: options.Index().SetName(name).SetUnique(true).SetCollation(caseInsensitive),
+	}
+}
+
+// dropIndexes drops indexes of users, ignoring the ones that do not exist
+func (c *Client) dropIndexes(ctx context.Context, names ...string) error {
+	for _, name := range names {
+		_, err := c.db.Collection(collectionUsers).Indexes().DropOne(ctx, name)
+		if err != nil && !isIndexNotFound(err) {
+			return fmt.Errorf("%s %s: %w", errDropIndexFailed, name, repositoryError(err))
+		}
+	}
+
+	return nil
+}
+
+// searchIndex returns the text index of the fields and search terms users are searched by. Names are not words of any
+// language, so they are neither stemmed nor dropped as stop words.
+func searchIndex() mongo.IndexModel {
+	keys := make(bson.D, 0, len(searchWeights))
+	for _, weight := range searchWeights {
+		keys = append(keys, bson.E{Key: weight.Key, Value: "text"})
+	}
+
+	return mongo.IndexModel{
+		Keys:    keys,
+		Options: options.Index().SetName(indexUserSearch).SetWeights(searchWeights).SetDefaultLanguage("none"),
+	}
+}
+
+// isIndexNotFound reports whether an error was returned for dropping an index that does not exist
+func isIndexNotFound(err error) bool {
+	var cmdErr mongo.CommandError
+
+	return errors.As(err, &cmdErr) && cmdErr.Code == codeIndexNotFound
+}
+
+// duplicateKeyField returns the field of the unique index a duplicate key error was raised by, if it is known
+func duplicateKeyField(err error) string {
+	for name, field := range uniqueIndexFields {
+		if strings.Contains(err.Error(), "index: "+name+" ") {
+			return field
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,263 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestClient_EnsureIndexes(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	success := mtest.CreateSuccessResponse()
+	indexNotFound := mtest.CreateCommandErrorResponse(mtest.CommandError{
+		Code:    codeIndexNotFound,
+		Name:    "IndexNotFound",
+		Message: "index not found with name [email_unique]",
+	})
+	noUsers := mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch)
+
+	tests := []struct {
+		name            string
+		uniqueNicknames bool
+		mockResponses   []bson.D
+		expectedIndexes []string
+		expectedErr     string
+	}{
+		{
+			name:            "creates unique email, paging and search indexes",
+			mockResponses:   []bson.D{indexNotFound, success, indexNotFound, success, noUsers, success, success},
+			expectedIndexes: []string{indexUserEmail, indexUserCreated, indexUserSearch, indexUserTerms},
+		},
+		{
+			name:            "creates unique email and nickname indexes",
+			uniqueNicknames: true,
+			mockResponses:   []bson.D{success, success, indexNotFound, indexNotFound, noUsers, success, success},
+			expectedIndexes: []string{indexUserEmail, indexUserCreated, indexUserSearch, indexUserTerms, indexUserNickname},
+		},
+		{
+			name:          "error dropping the legacy search index",
+			mockResponses: []bson.D{{{"ok", 0}}},
+			expectedErr:   errDropIndexFailed,
+		},
+		{
+			name:          "error creating indexes",
+			mockResponses: []bson.D{success, {{"ok", 0}}},
+			expectedErr:   errCreateIndexesFailed,
+		},
+		{
+			name:          "error dropping legacy indexes",
+			mockResponses: []bson.D{success, success, {{"ok", 0}}},
+			expectedErr:   errDropIndexFailed,
+		},
+		{
+			name:          "error storing search terms",
+			mockResponses: []bson.D{success, success, success, success, {{"ok", 0}}},
+			expectedErr:   errBackfillFailed,
+		},
+		{
+			name:          "error creating refresh token indexes",
+			mockResponses: []bson.D{success, success, success, success, noUsers, {{"ok", 0}}},
+			expectedErr:   errCreateIndexesFailed,
+		},
+		{
+			name:          "error creating one-time token indexes",
+			mockResponses: []bson.D{success, success, success, success, noUsers, success, {{"ok", 0}}},
+			expectedErr:   errCreateIndexesFailed,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponses...)
+
+			c := &Client{
+				db: mt.DB,
+			}
+
+			err := c.EnsureIndexes(context.Background(), tt.uniqueNicknames)
+
+			if tt.expectedErr != "" {
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+
+			assert.Equal(t, legacySearchIndex, mt.GetStartedEvent().Command.Lookup("index").StringValue())
+
+			indexes, err := mt.GetStartedEvent().Command.Lookup("indexes").Array().Values()
+			require.NoError(t, err)
+			require.Len(t, indexes, len(tt.expectedIndexes))
+
+			for i, name := range tt.expectedIndexes {
+				index := indexes[i].Document()
+				assert.Equal(t, name, index.Lookup("name").StringValue())
+
+				switch name {
+				case indexUserCreated:
+					assert.Equal(t, int32(-1), index.Lookup("key", "created_at").Int32())
+					assert.Equal(t, int32(-1), index.Lookup("key", "_id").Int32())
+					continue
+				case indexUserSearch:
+					assertSearchIndex(t, index)
+					continue
+				case indexUserTerms:
+					assert.Equal(t, int32(1), index.Lookup("key", fieldSearchTerms).Int32())
+					continue
+				}
+
+				assert.True(t, index.Lookup("unique").Boolean())
+				assert.Equal(t, int32(1), index.Lookup("key", uniqueIndexFields[name]).Int32())
+				assert.Equal(t, int32(1), index.Lookup("key", "deleted_at").Int32())
+				assert.Equal(t, "en", index.Lookup("collation", "locale").StringValue())
+				assert.Equal(t, int32(2), index.Lookup("collation", "strength").Int32())
+			}
+
+			for _, legacy := range legacyIndexes {
+				assert.Equal(t, legacy, mt.GetStartedEvent().Command.Lookup("index").StringValue())
+			}
+
+			backfill := mt.GetStartedEvent().Command
+			assert.Equal(t, collectionUsers, backfill.Lookup("find").StringValue())
+			assert.False(t, backfill.Lookup("filter", fieldSearchTerms, "$exists").Boolean())
+
+			command := mt.GetStartedEvent().Command
+			assert.Equal(t, collectionRefreshTokens, command.Lookup("createIndexes").StringValue())
+			assertTokenIndexes(t, command, map[string]string{
+				indexRefreshTokenHash:    "hash",
+				indexRefreshTokenFamily:  "family_id",
+				indexRefreshTokenUser:    "user_id",
+				indexRefreshTokenExpires: "expires_at",
+			}, indexRefreshTokenHash)
+
+			command = mt.GetStartedEvent().Command
+			assert.Equal(t, collectionOneTimeTokens, command.Lookup("createIndexes").StringValue())
+			assertTokenIndexes(t, command, map[string]string{
+				indexOneTimeTokenHash:    "hash",
+				indexOneTimeTokenExpires: "expires_at",
+			}, "")
+			assert.Equal(t, int32(1), command.Lookup("indexes", "0", "key", "purpose").Int32())
+		})
+	}
+}
+
+// assertSearchIndex asserts that an index is the text index of the weighted fields and search terms of users, without
+// stemming nor stop words
+func assertSearchIndex(t *testing.T, index bson.Raw) {
+	for _, weight := range searchWeights {
+		assert.Equal(t, "text", index.Lookup("key", weight.Key).StringValue())
+		assert.Equal(t, int32(weight.Value.(int)), index.Lookup("weights", weight.Key).Int32())
+	}
+	assert.Equal(t, "none", index.Lookup("default_language").StringValue())
+}
+
+// assertTokenIndexes asserts that a createIndexes command creates exactly the given indexes on the given fields, only
+// the given unique one being unique and the expiry one deleting tokens once they expire
+func assertTokenIndexes(t *testing.T, command bson.Raw, expected map[string]string, uniqueIndex string) {
+	indexes, err := command.Lookup("indexes").Array().Values()
+	require.NoError(t, err)
+	require.Len(t, indexes, len(expected))
+
+	for _, value := range indexes {
+		index := value.Document()
+		name := index.Lookup("name").StringValue()
+
+		field, ok := expected[name]
+		require.True(t, ok, "unexpected index %s", name)
+		assert.Equal(t, int32(1), index.Lookup("key", field).Int32())
+
+		unique, isUnique := index.Lookup("unique").BooleanOK()
+		assert.Equal(t, name == uniqueIndex, isUnique && unique)
+
+		expireAfter, expires := index.Lookup("expireAfterSeconds").Int32OK()
+		assert.Equal(t, field == "expires_at", expires)
+		assert.Equal(t, int32(0), expireAfter)
+	}
+}
+
+func TestClient_DuplicateKey(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("insert conflicting on email", func(mt *mtest.T) {
+		defer teardown(mt)
+
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Code:    11000,
+			Message: `E11000 duplicate key error collection: test.users index: email_deleted_at_unique dup key: { email: "jd@mensah.com" }`,
+		}))
+
+		c := &Client{db: mt.DB}
+
+		_, err := c.CreateUser(context.Background(), &api.UserCreateData{Email: "JD@mensah.com"})
+
+		var conflict *repository.ConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, "email", conflict.Field)
+		assert.ErrorIs(t, err, repository.ErrConflict)
+	})
+
+	mt.Run("update conflicting on nickname", func(mt *mtest.T) {
+		defer teardown(mt)
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+			Code:    11000,
+			Message: `E11000 duplicate key error collection: test.users index: nickname_deleted_at_unique dup key: { nickname: "jd" }`,
+		}))
+
+		c := &Client{db: mt.DB}
+
+		_, err := c.ReplaceUser(context.Background(), hexID1, &repository.UserReplacement{Nickname: "JD"}, nil)
+
+		var conflict *repository.ConflictError
+		require.ErrorAs(t, err, &conflict)
+		assert.Equal(t, "nickname", conflict.Field)
+	})
+
+	mt.Run("conflict on an unknown index", func(mt *mtest.T) {
+		defer teardown(mt)
+
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{
+			Code:    11000,
+			Message: `E11000 duplicate key error collection: test.users index: _id_ dup key: { _id: 1 }`,
+		}))
+
+		c := &Client{db: mt.DB}
+
+		_, err := c.CreateUser(context.Background(), &api.UserCreateData{Email: "jd@mensah.com"})
+
+		var conflict *repository.ConflictError
+		assert.False(t, errors.As(err, &conflict))
+		assert.ErrorIs(t, err, repository.ErrConflict)
+	})
+}
+
+func TestClient_GetCredentialsByEmail_CaseInsensitive(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("looks up emails ignoring case", func(mt *mtest.T) {
+		defer teardown(mt)
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{{"_id", hexID1}}))
+
+		c := &Client{db: mt.DB}
+
+		_, err := c.GetCredentialsByEmail(context.Background(), "JD@mensah.com")
+		require.NoError(t, err)
+
+		command := mt.GetStartedEvent().Command
+		assert.Equal(t, "en", command.Lookup("collation", "locale").StringValue())
+		assert.Equal(t, int32(2), command.Lookup("collation", "strength").Int32())
+	})
+}
@@ -0,0 +1,84 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/danielMensah/user-management/internal/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	errRecordFailedLoginFailed = "failed to record failed login in mongo"
+	errLockUserFailed          = "failed to lock user in mongo"
+	errUnlockUserFailed        = "failed to unlock user in mongo"
+)
+
+// RecordFailedLogin atomically counts a failed login of a user and returns the number of failed logins
+// since the given time. Failures older than that no longer count.
+func (c *Client) RecordFailedLogin(ctx context.Context, userID string, since time.Time) (int, error) {
+	pid, err := objectID(userID)
+	if err != nil {
+		return 0, err
+	}
+
+	opts := options.FindOneAndUpdate()
+	opts.SetReturnDocument(options.After)
+	opts.SetProjection(bson.M{"lockout": 1})
+
+	// a pipeline update lets the counter restart within the same atomic operation when the last failure is stale
+	update := bson.A{
+		bson.M{"$set": bson.M{
+			"lockout.failed_attempts": bson.M{"$cond": bson.A{
+				bson.M{"$lt": bson.A{"$lockout.last_failed_at", since}},
+				1,
+				bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$lockout.failed_attempts", 0}}, 1}},
+			}},
+			"lockout.last_failed_at": time.Now().UTC(),
+		}},
+	}
+
+	result := c.db.Collection(collectionUsers).FindOneAndUpdate(ctx, userFilter(pid), update, opts)
+
+	creds := &repository.Credentials{}
+	if err = result.Decode(creds); err != nil {
+		return 0, fmt.Errorf("%s with id '%s': %w", errRecordFailedLoginFailed, userID, repositoryError(err))
+	}
+
+	return creds.Lockout.FailedAttempts, nil
+}
+
+// LockUser locks a user out until the given time and restarts the count of their failed logins
+func (c *Client) LockUser(ctx context.Context, userID string, until time.Time) error {
+	update := bson.M{
+		"$set": bson.M{"lockout.locked_until": until, "lockout.failed_attempts": 0},
+		"$inc": bson.M{"lockout.lockouts": 1},
+	}
+
+	return c.updateLockout(ctx, userID, update, errLockUserFailed)
+}
+
+// UnlockUser lifts the lockout of a user and forgets their failed logins
+func (c *Client) UnlockUser(ctx context.Context, userID string) error {
+	return c.updateLockout(ctx, userID, bson.M{"$unset": bson.M{"lockout": ""}}, errUnlockUserFailed)
+}
+
+func (c *Client) updateLockout(ctx context.Context, userID string, update bson.M, errMsg string) error {
+	pid, err := objectID(userID)
+	if err != nil {
+		return err
+	}
+
+	result, err := c.db.Collection(collectionUsers).UpdateOne(ctx, userFilter(pid), update)
+	if err != nil {
+		return fmt.Errorf("%s with id '%s': %w", errMsg, userID, repositoryError(err))
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("%s with id '%s': %w", errMsg, userID, repository.ErrNotFound)
+	}
+
+	return nil
+}
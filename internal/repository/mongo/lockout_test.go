@@ -0,0 +1,181 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/danielMensah/user-management/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestClient_RecordFailedLogin(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tests := []struct {
+		name         string
+		id           string
+		mockResponse bson.D
+		expected     int
+		expectedErr  string
+	}{
+		{
+			name: "can record failed login",
+			id:   hexID1,
+			mockResponse: bson.D{
+				{"ok", 1},
+				{"value", bson.D{{"_id", hexID1}, {"lockout", bson.D{{"failed_attempts", 2}}}}},
+			},
+			expected: 2,
+		},
+		{
+			name:         "user not found",
+			id:           hexID1,
+			mockResponse: bson.D{{"ok", 1}, {"value", nil}},
+			expectedErr:  repository.ErrNotFound.Error(),
+		},
+		{
+			name:         "cannot record failed login",
+			id:           hexID1,
+			mockResponse: bson.D{{"ok", 0}},
+			expectedErr:  errRecordFailedLoginFailed,
+		},
+		{
+			name:        "invalid id",
+			id:          nonHexID,
+			expectedErr: errConvertToObjectID,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			c := &Client{
+				db: mt.DB,
+			}
+
+			got, err := c.RecordFailedLogin(context.Background(), tt.id, time.Now().Add(-time.Minute))
+
+			if tt.expectedErr != "" {
+				assert.Contains(t, err.Error(), tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expected, got)
+
+			if tt.id == hexID1 {
+				query := mt.GetStartedEvent().Command.Lookup("query")
+				assert.Equal(t, bson.TypeNull, query.Document().Lookup("deleted_at").Type)
+			}
+		})
+	}
+}
+
+func TestClient_LockUser(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tests := []struct {
+		name         string
+		mockResponse bson.D
+		expectedErr  string
+	}{
+		{
+			name:         "can lock user",
+			mockResponse: bson.D{{"ok", 1}, {"n", 1}, {"nModified", 1}},
+		},
+		{
+			name:         "user not found",
+			mockResponse: bson.D{{"ok", 1}, {"n", 0}, {"nModified", 0}},
+			expectedErr:  repository.ErrNotFound.Error(),
+		},
+		{
+			name:         "cannot lock user",
+			mockResponse: bson.D{{"ok", 0}},
+			expectedErr:  errLockUserFailed,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			c := &Client{
+				db: mt.DB,
+			}
+
+			err := c.LockUser(context.Background(), hexID1, time.Now().Add(time.Minute))
+
+			if tt.expectedErr != "" {
+				assert.Contains(t, err.Error(), tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestClient_UnlockUser(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tests := []struct {
+		name         string
+		id           string
+		mockResponse bson.D
+		expectedErr  string
+	}{
+		{
+			name:         "can unlock user",
+			id:           hexID1,
+			mockResponse: bson.D{{"ok", 1}, {"n", 1}, {"nModified", 1}},
+		},
+		{
+			name:         "user not found or deleted",
+			id:           hexID1,
+			mockResponse: bson.D{{"ok", 1}, {"n", 0}, {"nModified", 0}},
+			expectedErr:  repository.ErrNotFound.Error(),
+		},
+		{
+			name:         "cannot unlock user",
+			id:           hexID1,
+			mockResponse: bson.D{{"ok", 0}},
+			expectedErr:  errUnlockUserFailed,
+		},
+		{
+			name:        "invalid id",
+			id:          nonHexID,
+			expectedErr: errConvertToObjectID,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			c := &Client{
+				db: mt.DB,
+			}
+
+			err := c.UnlockUser(context.Background(), tt.id)
+
+			if tt.expectedErr != "" {
+				assert.Contains(t, err.Error(), tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			if tt.id == hexID1 {
+				filter := mt.GetStartedEvent().Command.Lookup("updates", "0", "q")
+				assert.Equal(t, bson.TypeNull, filter.Document().Lookup("deleted_at").Type)
+			}
+		})
+	}
+}
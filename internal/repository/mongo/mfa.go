@@ -0,0 +1,78 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/danielMensah/user-management/internal/repository"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const errUpdateMFAFailed = "failed to update user mfa in mongo"
+
+// SetPendingMFASecret stores a secret awaiting confirmation, replacing any previous pending secret
+func (c *Client) SetPendingMFASecret(ctx context.Context, userID, secret string) error {
+	return c.updateMFA(ctx, bson.M{}, userID, bson.M{"$set": bson.M{"mfa.pending_secret": secret}})
+}
+
+// EnableMFA enables mfa with the given pending secret and hashed recovery codes, recording the time step of the
+// code that confirmed it. The secret must still be pending so that confirming an outdated enrollment fails.
+func (c *Client) EnableMFA(ctx context.Context, userID, secret string, step int64, recoveryCodes []string) error {
+	update := bson.M{
+		"$set": bson.M{
+			"mfa.enabled":        true,
+			"mfa.secret":         secret,
+			"mfa.recovery_codes": recoveryCodes,
+			"mfa.last_used_step": step,
+		},
+		"$unset": bson.M{"mfa.pending_secret": ""},
+	}
+
+	return c.updateMFA(ctx, bson.M{"mfa.pending_secret": secret}, userID, update)
+}
+
+// SetMFARecoveryCodes replaces the hashed recovery codes of a user with mfa enabled
+func (c *Client) SetMFARecoveryCodes(ctx context.Context, userID string, recoveryCodes []string) error {
+	update := bson.M{"$set": bson.M{"mfa.recovery_codes": recoveryCodes}}
+
+	return c.updateMFA(ctx, bson.M{"mfa.enabled": true}, userID, update)
+}
+
+// UseMFARecoveryCode atomically removes a hashed recovery code, reporting codes that are unknown or
+// were already used as not found
+func (c *Client) UseMFARecoveryCode(ctx context.Context, userID, recoveryCode string) error {
+	update := bson.M{"$pull": bson.M{"mfa.recovery_codes": recoveryCode}}
+
+	return c.updateMFA(ctx, bson.M{"mfa.recovery_codes": recoveryCode}, userID, update)
+}
+
+// UseMFAStep atomically records the time step of a validated code, reporting steps that are not newer
+// than the last used one as not found so that codes cannot be replayed
+func (c *Client) UseMFAStep(ctx context.Context, userID string, step int64) error {
+	filter := bson.M{"$or": bson.A{
+		bson.M{"mfa.last_used_step": bson.M{"$exists": false}},
+		bson.M{"mfa.last_used_step": bson.M{"$lt": step}},
+	}}
+	update := bson.M{"$set": bson.M{"mfa.last_used_step": step}}
+
+	return c.updateMFA(ctx, filter, userID, update)
+}
+
+func (c *Client) updateMFA(ctx context.Context, filter bson.M, userID string, update bson.M) error {
+	pid, err := objectID(userID)
+	if err != nil {
+		return err
+	}
+	filter["_id"] = pid
+
+	result, err := c.db.Collection(collectionUsers).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("%s with id '%s': %w", errUpdateMFAFailed, userID, repositoryError(err))
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("%s with id '%s': %w", errUpdateMFAFailed, userID, repository.ErrNotFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danielMensah/user-management/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestClient_MFA(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	ctx := context.Background()
+	updated := bson.D{{"ok", 1}, {"n", 1}, {"nModified", 1}}
+	notMatched := bson.D{{"ok", 1}, {"n", 0}, {"nModified", 0}}
+
+	tests := []struct {
+		name         string
+		call         func(c *Client) error
+		mockResponse bson.D
+		expectedErr  string
+	}{
+		{
+			name:         "can set pending secret",
+			call:         func(c *Client) error { return c.SetPendingMFASecret(ctx, hexID1, "secret") },
+			mockResponse: updated,
+		},
+		{
+			name:         "pending secret of unknown user",
+			call:         func(c *Client) error { return c.SetPendingMFASecret(ctx, hexID1, "secret") },
+			mockResponse: notMatched,
+			expectedErr:  repository.ErrNotFound.Error(),
+		},
+		{
+			name:         "can enable mfa",
+			call:         func(c *Client) error { return c.EnableMFA(ctx, hexID1, "secret", 1, []string{"hash"}) },
+			mockResponse: updated,
+		},
+		{
+			name:         "secret no longer pending",
+			call:         func(c *Client) error { return c.EnableMFA(ctx, hexID1, "secret", 1, []string{"hash"}) },
+			mockResponse: notMatched,
+			expectedErr:  repository.ErrNotFound.Error(),
+		},
+		{
+			name:         "can set recovery codes",
+			call:         func(c *Client) error { return c.SetMFARecoveryCodes(ctx, hexID1, []string{"hash"}) },
+			mockResponse: updated,
+		},
+		{
+			name:         "can use recovery code",
+			call:         func(c *Client) error { return c.UseMFARecoveryCode(ctx, hexID1, "hash") },
+			mockResponse: updated,
+		},
+		{
+			name:         "recovery code already used",
+			call:         func(c *Client) error { return c.UseMFARecoveryCode(ctx, hexID1, "hash") },
+			mockResponse: notMatched,
+			expectedErr:  repository.ErrNotFound.Error(),
+		},
+		{
+			name:         "can use step",
+			call:         func(c *Client) error { return c.UseMFAStep(ctx, hexID1, 2) },
+			mockResponse: updated,
+		},
+		{
+			name:         "step already used",
+			call:         func(c *Client) error { return c.UseMFAStep(ctx, hexID1, 2) },
+			mockResponse: notMatched,
+			expectedErr:  repository.ErrNotFound.Error(),
+		},
+		{
+			name:         "cannot update mfa",
+			call:         func(c *Client) error { return c.UseMFAStep(ctx, hexID1, 2) },
+			mockResponse: bson.D{{"ok", 0}},
+			expectedErr:  errUpdateMFAFailed,
+		},
+		{
+			name:        "invalid id",
+			call:        func(c *Client) error { return c.UseMFAStep(ctx, nonHexID, 2) },
+			expectedErr: errConvertToObjectID,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			c := &Client{
+				db: mt.DB,
+			}
+
+			err := tt.call(c)
+
+			if tt.expectedErr != "" {
+				assert.Contains(t, err.Error(), tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestClient_EnableMFA_RequiresPendingSecret(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("filters on the pending secret", func(mt *mtest.T) {
+		defer teardown(mt)
+
+		mt.AddMockResponses(bson.D{{"ok", 1}, {"n", 1}, {"nModified", 1}})
+
+		c := &Client{
+			db: mt.DB,
+		}
+
+		err := c.EnableMFA(context.Background(), hexID1, "secret", 1, []string{"hash"})
+		assert.NoError(t, err)
+
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(t, "secret", update.Lookup("q", "mfa.pending_secret").StringValue())
+		assert.True(t, update.Lookup("u", "$set", "mfa.enabled").Boolean())
+	})
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/query"
 	"github.com/danielMensah/user-management/internal/repository"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -14,55 +15,239 @@ import (
 )
 
 const (
-	collectionUsers = "users"
+	collectionUsers         = "users"
+	collectionRefreshTokens = "refresh_tokens"
+	collectionRoles         = "roles"
+	collectionOneTimeTokens = "one_time_tokens"
 
 	errRetrieveFailed          = "failed to retrieve data from mongo"
 	errCursorAllFailed         = "failed to use cursor to retrieve all data from mongo"
+	errCountFailed             = "failed to count users in mongo"
+	errFindFailed              = "failed to find user in mongo"
 	errInsertFailed            = "failed to insert data into mongo"
 	errConvertInsertedObjectID = "failed to convert inserted id to object id"
 	errConvertToObjectID       = "failed to convert id string to object id"
 	errUpdateFailed            = "failed to update user in mongo"
 	errDeleteFailed            = "failed to delete user from mongo"
+	errVerifyEmailFailed       = "failed to verify user email in mongo"
+	errRehashPasswordFailed    = "failed to rehash user password in mongo"
+	errSetPasswordFailed       = "failed to set user password in mongo"
 )
 
+// newUser represents a user as it is first stored
+type newUser struct {
+	ID                  primitive.ObjectID `bson:"_id,omitempty"`
+	*api.UserCreateData `bson:",inline"`
+	Roles               api.Roles `bson:"roles"`
+	EmailVerified       bool      `bson:"email_verified"`
+	Version             int64     `bson:"version"`
+	SearchTerms         []string  `bson:"search_terms"`
+}
+
+// userReplacement represents the fields set when replacing a user. Changing the email resets its verification.
+type userReplacement struct {
+	*repository.UserReplacement `bson:",inline"`
+	UpdatedAt                   time.Time `bson:"updated_at"`
+	EmailVerified               *bool     `bson:"email_verified,omitempty"`
+	SearchTerms                 []string  `bson:"search_terms"`
+}
+
+// createdUser returns a user as it is first stored
+func createdUser(id primitive.ObjectID, user *api.UserCreateData) newUser {
+	return newUser{
+		ID:             id,
+		UserCreateData: user,
+		Roles:          repository.DefaultRoles,
+		Version:        repository.InitialVersion,
+		SearchTerms:    searchTerms(user.Nickname, user.FirstName, user.LastName, string(user.Email)),
+	}
+}
+
 // Client represents a mongo client
 type Client struct {
 	db *mongo.Database
 }
 
 // New creates a new mongo repository client
-func New(db *mongo.Database) repository.UserRepository {
+func New(db *mongo.Database) repository.Repository {
 	return &Client{db}
 }
 
-// GetUsers returns a list of users
-func (c *Client) GetUsers(ctx context.Context, params api.GetUsersParams) (*[]api.User, error) {
-	opts := options.Find()
-	opts.SetLimit(params.Limit)
-	opts.SetSkip(params.Page)
-	opts.SetSort(bson.M{"createdAt": -1})
+// GetUsers returns a page of the users matching a query, in the order of its sort. Given a cursor, the page starts
+// right after or before the user it points to, otherwise it skips the number of users of the query. Sorts end with
+// the id of users, so that the order stays stable while users are inserted.
+func (c *Client) GetUsers(ctx context.Context, q repository.UserQuery) (*repository.UserPage, error) {
+	filter, collation, err := usersFilter(q.Filter)
+	if err != nil {
+		return nil, err
+	}
+	if !q.IncludeDeleted {
+		excludeDeleted(filter)
+	}
+
+	backwards := q.Cursor != nil && q.Cursor.Before
 
-	filter := bson.M{}
-	if params.Country != nil {
-		filter["country"] = *params.Country
+	// one more user than asked for tells whether there is another page
+	opts := options.Find()
+	opts.SetLimit(q.Limit + 1)
+	opts.SetSort(sortDocument(q.Sort, backwards))
+	opts.SetCollation(collation)
+	if fields := projection(q.Fields, q.Sort); fields != nil {
+		opts.SetProjection(fields)
 	}
-	if params.Email != nil {
-		filter["email"] = *params.Email
+
+	skip := int64(0)
+	if q.Cursor != nil && !q.Cursor.IsEnd() {
+		keyset, err := keysetFilter(q.Sort, q.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter["$or"] = keyset
+	} else if q.Cursor == nil && q.Skip > 0 {
+		skip = q.Skip
+		opts.SetSkip(skip)
 	}
 
 	collection := c.db.Collection(collectionUsers)
-	cursor, err := collection.Find(ctx, filter, opts)
+	result, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errRetrieveFailed, repositoryError(err))
+	}
+	defer result.Close(ctx)
+
+	documents := make([]bson.Raw, 0)
+	if err = result.All(ctx, &documents); err != nil {
+		return nil, fmt.Errorf("%s: %w", errCursorAllFailed, repositoryError(err))
+	}
+
+	return userPage(q, documents, skip)
+}
+
+// userPage returns the page of users found by GetUsers, given one more user than the limit when there are more
+func userPage(q repository.UserQuery, documents []bson.Raw, skip int64) (*repository.UserPage, error) {
+	backwards := q.Cursor != nil && q.Cursor.Before
+
+	more := int64(len(documents)) > q.Limit
+	if more {
+		documents = documents[:q.Limit]
+	}
+	if backwards {
+		for i, j := 0, len(documents)-1; i < j; i, j = i+1, j-1 {
+			documents[i], documents[j] = documents[j], documents[i]
+		}
+	}
+
+	users, err := decodeUsers(documents)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &repository.UserPage{Users: users}
+	if len(users) == 0 {
+		return page, nil
+	}
+
+	// going backwards, the page was reached from the one after it, and there are more users before it when the
+	// query found more. Going forwards it is the other way around.
+	hasNext, hasPrev := more, q.Cursor != nil || skip > 0
+	if backwards {
+		hasNext, hasPrev = !q.Cursor.IsEnd(), more
+	}
+	page.More = hasNext
+	if hasNext {
+		page.Next = userCursor(documents[len(documents)-1], q.Sort, false)
+	}
+	if hasPrev {
+		page.Prev = userCursor(documents[0], q.Sort, true)
+	}
+
+	return page, nil
+}
+
+// CountUsers returns the number of users matching the filter of a query
+func (c *Client) CountUsers(ctx context.Context, q repository.UserQuery) (int64, error) {
+	mongoFilter, collation, err := usersFilter(q.Filter)
+	if err != nil {
+		return 0, err
+	}
+	if !q.IncludeDeleted {
+		excludeDeleted(mongoFilter)
+	}
+
+	opts := options.Count().SetCollation(collation)
+	count, err := c.db.Collection(collectionUsers).CountDocuments(ctx, mongoFilter, opts)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", errCountFailed, repositoryError(err))
+	}
+
+	return count, nil
+}
+
+func decodeUsers(documents []bson.Raw) ([]api.User, error) {
+	users := make([]api.User, 0, len(documents))
+	for _, document := range documents {
+		user := api.User{}
+		if err := bson.Unmarshal(document, &user); err != nil {
+			return nil, fmt.Errorf("%s: %w", errCursorAllFailed, err)
+		}
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+// GetUser returns the user with the given id, with only the given fields unless none are given
+func (c *Client) GetUser(ctx context.Context, id string, fields []query.Field) (*api.User, error) {
+	pid, err := objectID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.FindOne()
+	if fields := projection(fields, nil); fields != nil {
+		// the version is read along with any field, telling which version of the user the fields are from
+		fields["version"] = 1
+		opts.SetProjection(fields)
+	}
+
+	result := c.db.Collection(collectionUsers).FindOne(ctx, userFilter(pid), opts)
+
+	user := &api.User{}
+	if err = result.Decode(user); err != nil {
+		return nil, fmt.Errorf("%s with id '%s': %w", errFindFailed, id, repositoryError(err))
+	}
+
+	return user, nil
+}
+
+// GetCredentialsByEmail returns the credentials of the user with the given email
+func (c *Client) GetCredentialsByEmail(ctx context.Context, email string) (*repository.Credentials, error) {
+	opts := options.FindOne().SetCollation(caseInsensitive)
+	result := c.db.Collection(collectionUsers).FindOne(ctx, excludeDeleted(bson.M{"email": email}), opts)
+
+	creds := &repository.Credentials{}
+	if err := result.Decode(creds); err != nil {
+		return nil, fmt.Errorf("%s with email '%s': %w", errFindFailed, email, repositoryError(err))
+	}
+
+	return creds, nil
+}
+
+// GetCredentialsByID returns the credentials of the user with the given id
+func (c *Client) GetCredentialsByID(ctx context.Context, id string) (*repository.Credentials, error) {
+	pid, err := objectID(id)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", errRetrieveFailed, err)
+		return nil, err
 	}
-	defer cursor.Close(ctx)
 
-	users := make([]api.User, 0)
-	if err = cursor.All(ctx, &users); err != nil {
-		return nil, fmt.Errorf("%s: %w", errCursorAllFailed, err)
+	result := c.db.Collection(collectionUsers).FindOne(ctx, userFilter(pid))
+
+	creds := &repository.Credentials{}
+	if err = result.Decode(creds); err != nil {
+		return nil, fmt.Errorf("%s with id '%s': %w", errFindFailed, id, repositoryError(err))
 	}
 
-	return &users, nil
+	return creds, nil
 }
 
 // CreateUser creates a new user
@@ -73,9 +258,9 @@ func (c *Client) CreateUser(ctx context.Context, user *api.UserCreateData) (stri
 	user.CreatedAt = &createdAt
 	user.UpdatedAt = &updatedAt
 
-	result, err := c.db.Collection(collectionUsers).InsertOne(ctx, user)
+	result, err := c.db.Collection(collectionUsers).InsertOne(ctx, createdUser(primitive.NilObjectID, user))
 	if err != nil {
-		return "", fmt.Errorf("%s: %w", errInsertFailed, err)
+		return "", fmt.Errorf("%s: %w", errInsertFailed, repositoryError(err))
 	}
 
 	oid, ok := result.InsertedID.(primitive.ObjectID)
@@ -86,41 +271,151 @@ func (c *Client) CreateUser(ctx context.Context, user *api.UserCreateData) (stri
 	return "", fmt.Errorf("%s: %s", errConvertInsertedObjectID, oid)
 }
 
-// UpdateUser updates a user
-func (c *Client) UpdateUser(ctx context.Context, id string, data *api.UserUpdateData) (*api.User, error) {
+// ReplaceUser replaces the editable fields of a user and returns the updated user
+func (c *Client) ReplaceUser(
+	ctx context.Context,
+	id string,
+	user *repository.UserReplacement,
+	versions []int64,
+) (*api.User, error) {
 	opts := options.FindOneAndUpdate()
-	opts.SetReturnDocument(1)
+	opts.SetReturnDocument(options.After)
 
-	pid, err := primitive.ObjectIDFromHex(id)
+	pid, err := objectID(id)
 	if err != nil {
-		return nil, fmt.Errorf("%s: %w", errConvertToObjectID, err)
+		return nil, err
 	}
 
-	updatedAt := time.Now().UTC()
-	data.UpdatedAt = &updatedAt
+	set := userReplacement{
+		UserReplacement: user,
+		UpdatedAt:       time.Now().UTC(),
+		SearchTerms:     searchTerms(user.Nickname, user.FirstName, user.LastName, user.Email),
+	}
+	update := bson.M{"$set": &set, "$inc": nextVersion}
+	if user.EmailChanged {
+		verified := false
+		set.EmailVerified = &verified
+		update["$unset"] = bson.M{"email_verified_at": ""}
+	}
+
+	filter := versionedFilter(pid, versions)
+	result := c.db.Collection(collectionUsers).FindOneAndUpdate(ctx, filter, update, opts)
+
+	updated := &api.User{}
+	if err = result.Decode(updated); err != nil {
+		return nil, fmt.Errorf("%s with id '%s': %w", errUpdateFailed, id, c.missedWrite(ctx, pid, versions, err))
+	}
 
-	result := c.db.Collection(collectionUsers).FindOneAndUpdate(ctx, bson.M{"_id": pid}, bson.M{"$set": data})
+	return updated, nil
+}
+
+// SetPassword replaces the password hash of a user
+func (c *Client) SetPassword(ctx context.Context, id, hash string) error {
+	pid, err := objectID(id)
+	if err != nil {
+		return err
+	}
+
+	update := bson.M{"$set": bson.M{"password": hash, "updated_at": time.Now().UTC()}, "$inc": nextVersion}
+
+	result, err := c.db.Collection(collectionUsers).UpdateOne(ctx, userFilter(pid), update)
+	if err != nil {
+		return fmt.Errorf("%s with id '%s': %w", errSetPasswordFailed, id, repositoryError(err))
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("%s with id '%s': %w", errSetPasswordFailed, id, repository.ErrNotFound)
+	}
+
+	return nil
+}
+
+// SetUserRoles replaces the roles of a user
+func (c *Client) SetUserRoles(ctx context.Context, id string, roles api.Roles) (*api.User, error) {
+	opts := options.FindOneAndUpdate()
+	opts.SetReturnDocument(options.After)
+
+	pid, err := objectID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	update := bson.M{"$set": bson.M{"roles": roles, "updated_at": time.Now().UTC()}, "$inc": nextVersion}
+	result := c.db.Collection(collectionUsers).FindOneAndUpdate(ctx, userFilter(pid), update, opts)
 
 	user := &api.User{}
 	if err = result.Decode(user); err != nil {
-		return nil, fmt.Errorf("%s with id '%s': %w", errUpdateFailed, id, err)
+		return nil, fmt.Errorf("%s with id '%s': %w", errUpdateFailed, id, repositoryError(err))
 	}
 
 	return user, nil
 }
 
-// DeleteUser deletes a user
-func (c *Client) DeleteUser(ctx context.Context, id string) error {
-	pid, err := primitive.ObjectIDFromHex(id)
+// DeleteUser soft deletes a user, who is kept until purged
+func (c *Client) DeleteUser(ctx context.Context, id string, versions []int64) error {
+	pid, err := objectID(id)
 	if err != nil {
-		return fmt.Errorf("%s: %w", errConvertToObjectID, err)
+		return err
 	}
 
-	result := c.db.Collection(collectionUsers).FindOneAndDelete(ctx, bson.M{"_id": pid})
+	now := time.Now().UTC()
+	update := bson.M{"$set": bson.M{"deleted_at": now, "updated_at": now}, "$inc": nextVersion}
+	result := c.db.Collection(collectionUsers).FindOneAndUpdate(ctx, versionedFilter(pid, versions), update)
 
 	deletedUser := &api.User{}
 	if err := result.Decode(deletedUser); err != nil {
-		return fmt.Errorf("%s with id '%s': %w", errDeleteFailed, id, err)
+		return fmt.Errorf("%s with id '%s': %w", errDeleteFailed, id, c.missedWrite(ctx, pid, versions, err))
+	}
+
+	return nil
+}
+
+// VerifyEmail marks the email of a user as verified, provided the user still has the given email
+func (c *Client) VerifyEmail(ctx context.Context, id, email string) error {
+	pid, err := objectID(id)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	filter := userFilter(pid)
+	filter["email"] = email
+	update := bson.M{
+		"$set": bson.M{"email_verified": true, "email_verified_at": now, "updated_at": now},
+		"$inc": nextVersion,
+	}
+
+	result, err := c.db.Collection(collectionUsers).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("%s with id '%s': %w", errVerifyEmailFailed, id, repositoryError(err))
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("%s with id '%s': %w", errVerifyEmailFailed, id, repository.ErrNotFound)
+	}
+
+	return nil
+}
+
+// RehashPassword replaces the password hash of a user, provided it is still the given current hash so that a
+// password changed in the meantime is not overwritten
+func (c *Client) RehashPassword(ctx context.Context, id, current, hash string) error {
+	pid, err := objectID(id)
+	if err != nil {
+		return err
+	}
+
+	filter := userFilter(pid)
+	filter["password"] = current
+	update := bson.M{"$set": bson.M{"password": hash}}
+
+	result, err := c.db.Collection(collectionUsers).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("%s with id '%s': %w", errRehashPasswordFailed, id, repositoryError(err))
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("%s with id '%s': %w", errRehashPasswordFailed, id, repository.ErrNotFound)
 	}
 
 	return nil
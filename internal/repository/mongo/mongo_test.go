@@ -7,6 +7,9 @@ import (
 	"time"
 
 	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/pagination"
+	"github.com/danielMensah/user-management/internal/query"
+	"github.com/danielMensah/user-management/internal/repository"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/bson"
@@ -37,8 +40,7 @@ func TestMain(m *testing.M) {
 	}
 
 	logrus.Info("dates initialized")
-	m.Run()
-	os.Exit(0)
+	os.Exit(m.Run())
 }
 
 func TestNew(t *testing.T) {
@@ -53,116 +55,163 @@ func TestClient_GetUsers(t *testing.T) {
 	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
 	defer mt.Close()
 
+	john := bson.D{
+		{"_id", hexID1},
+		{"first_name", "john"},
+		{"last_name", "doe"},
+		{"nickname", "jd"},
+		{"email", "jd@jd@mensah.com.com"},
+		{"country", "UK"},
+		{"created_at", createdAt},
+		{"updated_at", updatedAt},
+	}
+	jane := bson.D{
+		{"_id", hexID2},
+		{"first_name", "jane"},
+		{"last_name", "doe"},
+		{"nickname", "jd"},
+		{"email", "jd@jd@mensah.com.com"},
+		{"country", "UK"},
+		{"created_at", createdAt},
+		{"updated_at", updatedAt},
+	}
+	johnUser := api.User{
+		Id:        hexID1,
+		FirstName: "john",
+		LastName:  "doe",
+		Nickname:  "jd",
+		Email:     "jd@jd@mensah.com.com",
+		Country:   "UK",
+		CreatedAt: createdAt,
+		UpdatedAt: updatedAt,
+	}
+	janeUser := johnUser
+	janeUser.Id = hexID2
+	janeUser.FirstName = "jane"
+
+	cursorAt := func(id string, before bool) *pagination.Cursor {
+		return &pagination.Cursor{
+			Sort:   query.DefaultSort.String(),
+			Values: []string{"2020-01-01T00:00:00Z", id},
+			Before: before,
+		}
+	}
+
 	tests := []struct {
-		name          string
-		mockResponses []bson.D
-		params        api.GetUsersParams
-		mockError     bool
-		expected      *[]api.User
-		expectedErr   string
+		name           string
+		mockResponses  []bson.D
+		query          repository.UserQuery
+		mockError      bool
+		expected       *repository.UserPage
+		expectedOrder  int32
+		expectedKeyset bool
+		expectedErr    error
 	}{
 		{
-			name: "successfully gets single user",
-			params: api.GetUsersParams{
-				Page:  0,
-				Limit: 10,
+			name:          "successfully gets single user",
+			query:         repository.UserQuery{Sort: query.DefaultSort, Limit: 10},
+			mockResponses: []bson.D{john},
+			expected:      &repository.UserPage{Users: []api.User{johnUser}},
+			expectedOrder: -1,
+		},
+		{
+			name:          "successfully gets multiple users",
+			query:         repository.UserQuery{Sort: query.DefaultSort, Limit: 10},
+			mockResponses: []bson.D{john, jane},
+			expected:      &repository.UserPage{Users: []api.User{johnUser, janeUser}},
+			expectedOrder: -1,
+		},
+		{
+			name:          "points to the next page when there are more users",
+			query:         repository.UserQuery{Sort: query.DefaultSort, Limit: 1},
+			mockResponses: []bson.D{john, jane},
+			expected: &repository.UserPage{
+				Users: []api.User{johnUser},
+				More:  true,
+				Next:  cursorAt(hexID1, false),
 			},
-			mockResponses: []bson.D{
-				{
-					{"_id", hexID1},
-					{"first_name", "john"},
-					{"last_name", "doe"},
-					{"nickname", "jd"},
-					{"email", "jd@jd@mensah.com.com"},
-					{"country", "UK"},
-					{"created_at", createdAt},
-					{"updated_at", updatedAt},
-				},
+			expectedOrder: -1,
+		},
+		{
+			name:          "points to the previous page when skipping users",
+			query:         repository.UserQuery{Sort: query.DefaultSort, Skip: 10, Limit: 10},
+			mockResponses: []bson.D{john},
+			expected: &repository.UserPage{
+				Users: []api.User{johnUser},
+				Prev:  cursorAt(hexID1, true),
 			},
-			expected: &[]api.User{
-				{
-					Id:        hexID1,
-					FirstName: "john",
-					LastName:  "doe",
-					Nickname:  "jd",
-					Email:     "jd@jd@mensah.com.com",
-					Country:   "UK",
-					CreatedAt: createdAt,
-					UpdatedAt: updatedAt,
-				},
+			expectedOrder: -1,
+		},
+		{
+			name:          "gets the users after a cursor",
+			query:         repository.UserQuery{Sort: query.DefaultSort, Skip: 10, Limit: 1, Cursor: cursorAt(hexID1, false)},
+			mockResponses: []bson.D{jane},
+			expected: &repository.UserPage{
+				Users: []api.User{janeUser},
+				Prev:  cursorAt(hexID2, true),
 			},
+			expectedOrder:  -1,
+			expectedKeyset: true,
 		},
 		{
-			name: "successfully gets multiple users",
-			params: api.GetUsersParams{
-				Page:  0,
-				Limit: 10,
+			name:          "gets the users before a cursor",
+			query:         repository.UserQuery{Sort: query.DefaultSort, Limit: 1, Cursor: cursorAt(hexID2, true)},
+			mockResponses: []bson.D{john, jane},
+			expected: &repository.UserPage{
+				Users: []api.User{johnUser},
+				More:  true,
+				Next:  cursorAt(hexID1, false),
+				Prev:  cursorAt(hexID1, true),
 			},
-			mockResponses: []bson.D{
-				{
-					{"_id", hexID1},
-					{"first_name", "john"},
-					{"last_name", "doe"},
-					{"nickname", "jd"},
-					{"email", "jd@jd@mensah.com.com"},
-					{"country", "UK"},
-					{"created_at", createdAt},
-					{"updated_at", updatedAt},
-				},
-				{
-					{"_id", hexID2},
-					{"first_name", "jane"},
-					{"last_name", "doe"},
-					{"nickname", "jd"},
-					{"email", "jd@jd@mensah.com.com"},
-					{"country", "UK"},
-					{"created_at", createdAt},
-					{"updated_at", updatedAt},
-				},
+			expectedOrder:  1,
+			expectedKeyset: true,
+		},
+		{
+			name: "gets the last page",
+			query: repository.UserQuery{
+				Sort:   query.DefaultSort,
+				Limit:  1,
+				Cursor: pagination.End(query.DefaultSort.String()),
 			},
-			expected: &[]api.User{
-				{
-					Id:        hexID1,
-					FirstName: "john",
-					LastName:  "doe",
-					Nickname:  "jd",
-					Email:     "jd@jd@mensah.com.com",
-					Country:   "UK",
-					CreatedAt: createdAt,
-					UpdatedAt: updatedAt,
-				},
-				{
-					Id:        hexID2,
-					FirstName: "jane",
-					LastName:  "doe",
-					Nickname:  "jd",
-					Email:     "jd@jd@mensah.com.com",
-					Country:   "UK",
-					CreatedAt: createdAt,
-					UpdatedAt: updatedAt,
-				},
+			mockResponses: []bson.D{jane, john},
+			expected: &repository.UserPage{
+				Users: []api.User{janeUser},
+				Prev:  cursorAt(hexID2, true),
 			},
+			expectedOrder: 1,
+		},
+		{
+			name:        "invalid cursor id",
+			query:       repository.UserQuery{Sort: query.DefaultSort, Limit: 1, Cursor: cursorAt(nonHexID, false)},
+			expectedErr: pagination.ErrInvalidCursor,
 		},
 		{
-			name: "error retrieving users",
-			mockResponses: []bson.D{
-				{
-					{"ok", "0"},
-				},
+			name: "cursor of another sort",
+			query: repository.UserQuery{
+				Sort:   query.Sort{{Field: query.FieldID}},
+				Limit:  1,
+				Cursor: cursorAt(hexID1, false),
 			},
+			expectedErr: pagination.ErrInvalidCursor,
+		},
+		{
+			name:        "error retrieving users",
 			mockError:   true,
-			params:      api.GetUsersParams{},
+			query:       repository.UserQuery{Sort: query.DefaultSort},
 			expected:    nil,
-			expectedErr: errRetrieveFailed,
+			expectedErr: repository.ErrUnavailable,
 		},
 	}
 	for _, tt := range tests {
 		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
 			if tt.mockError {
 				mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
 					Code:    0,
 					Message: "error",
 					Name:    "foo.bar.error",
+					Labels:  []string{"NetworkError"},
 				}))
 			} else {
 				r := make([]bson.D, 0)
@@ -183,17 +232,292 @@ func TestClient_GetUsers(t *testing.T) {
 				db: mt.DB,
 			}
 
-			got, err := c.GetUsers(context.Background(), tt.params)
+			got, err := c.GetUsers(context.Background(), tt.query)
 
-			if tt.expectedErr != "" {
+			if tt.expectedErr != nil {
 				assert.Nil(t, got)
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+
+			command := mt.GetStartedEvent().Command
+			assert.Equal(t, tt.query.Limit+1, command.Lookup("limit").Int64())
+			assert.Equal(t, tt.expectedOrder, command.Lookup("sort", "created_at").Int32())
+			assert.Equal(t, tt.expectedOrder, command.Lookup("sort", "_id").Int32())
+
+			_, keyset := command.Lookup("filter").Document().LookupErr("$or")
+			assert.Equal(t, tt.expectedKeyset, keyset == nil)
+		})
+	}
+}
+
+func TestClient_CountUsers(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tests := []struct {
+		name              string
+		filter            query.Filter
+		includeDeleted    bool
+		mockResponse      bson.D
+		expected          int64
+		expectedCollation bool
+		expectedErr       string
+	}{
+		{
+			name:         "counts users of a country",
+			filter:       query.Filter{query.Eq(query.FieldCountry, "UK")},
+			mockResponse: mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{{"n", 3}}),
+			expected:     3,
+		},
+		{
+			name:           "counts deleted users too",
+			filter:         query.Filter{query.Eq(query.FieldCountry, "UK")},
+			includeDeleted: true,
+			mockResponse:   mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{{"n", 4}}),
+			expected:       4,
+		},
+		{
+			name:              "counts users by email ignoring case",
+			filter:            query.Filter{query.Eq(query.FieldEmail, "JD@example.com")},
+			mockResponse:      mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{{"n", 1}}),
+			expected:          1,
+			expectedCollation: true,
+		},
+		{
+			name:         "error counting users",
+			mockResponse: bson.D{{"ok", 0}},
+			expectedErr:  errCountFailed,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			c := &Client{
+				db: mt.DB,
+			}
+
+			got, err := c.CountUsers(context.Background(), repository.UserQuery{Filter: tt.filter, IncludeDeleted: tt.includeDeleted})
+
+			if tt.expectedErr != "" {
 				assert.Contains(t, err.Error(), tt.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+
+			command := mt.GetStartedEvent().Command
+			match := command.Lookup("pipeline").Array().Index(0).Value().Document().Lookup("$match").Document()
+			conditions, _ := match.Lookup("$and").Array().Values()
+			assert.Len(t, conditions, len(tt.filter))
+
+			_, deleted := match.LookupErr("deleted_at")
+			assert.Equal(t, tt.includeDeleted, deleted != nil)
+
+			_, collation := command.LookupErr("collation")
+			assert.Equal(t, tt.expectedCollation, collation == nil)
+		})
+	}
+}
+
+func TestClient_GetCredentialsByEmail(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tests := []struct {
+		name         string
+		email        string
+		mockResponse bson.D
+		expected     *repository.Credentials
+		expectedErr  error
+	}{
+		{
+			name:  "can get credentials",
+			email: "jd@jd@mensah.com.com",
+			mockResponse: mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{
+				{"_id", hexID1},
+				{"first_name", "john"},
+				{"email", "jd@jd@mensah.com.com"},
+				{"password", "hash"},
+			}),
+			expected: &repository.Credentials{
+				ID:       hexID1,
+				Email:    "jd@jd@mensah.com.com",
+				Password: "hash",
+			},
+		},
+		{
+			name:         "user not found",
+			email:        "jd@jd@mensah.com.com",
+			mockResponse: mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch),
+			expectedErr:  repository.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			c := &Client{
+				db: mt.DB,
+			}
+
+			got, err := c.GetCredentialsByEmail(context.Background(), tt.email)
+
+			if tt.expectedErr != nil {
+				assert.Nil(t, got)
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Contains(t, err.Error(), errFindFailed)
 			} else {
 				assert.NoError(t, err)
 				assert.Equal(t, tt.expected, got)
 			}
+		})
+	}
+}
+
+func TestClient_GetUser(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tests := []struct {
+		name         string
+		id           string
+		fields       []query.Field
+		mockResponse bson.D
+		expected     *api.User
+		expectedErr  error
+	}{
+		{
+			name: "can get user",
+			id:   hexID1,
+			mockResponse: mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{
+				{"_id", hexID1},
+				{"first_name", "john"},
+				{"email", "jd@jd@mensah.com.com"},
+				{"country", "UK"},
+				{"password", "hash"},
+			}),
+			expected: &api.User{
+				Id:        hexID1,
+				FirstName: "john",
+				Email:     "jd@jd@mensah.com.com",
+				Country:   "UK",
+			},
+		},
+		{
+			name:   "projects the given fields",
+			id:     hexID1,
+			fields: []query.Field{query.FieldID, query.FieldNickname},
+			mockResponse: mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{
+				{"_id", hexID1},
+				{"nickname", "jd"},
+			}),
+			expected: &api.User{Id: hexID1, Nickname: "jd"},
+		},
+		{
+			name:         "user not found",
+			id:           hexID1,
+			mockResponse: mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch),
+			expectedErr:  repository.ErrNotFound,
+		},
+		{
+			name:        "invalid id",
+			id:          nonHexID,
+			expectedErr: repository.ErrInvalidID,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			c := &Client{
+				db: mt.DB,
+			}
+
+			got, err := c.GetUser(context.Background(), tt.id, tt.fields)
+
+			if tt.expectedErr != nil {
+				assert.Nil(t, got)
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
 
-			teardown(mt)
+			_, projected := mt.GetStartedEvent().Command.LookupErr("projection")
+			assert.Equal(t, len(tt.fields) > 0, projected == nil)
+		})
+	}
+}
+
+func TestClient_GetCredentialsByID(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tests := []struct {
+		name         string
+		id           string
+		mockResponse bson.D
+		expected     *repository.Credentials
+		expectedErr  string
+	}{
+		{
+			name: "can get credentials",
+			id:   hexID1,
+			mockResponse: mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{
+				{"_id", hexID1},
+				{"email", "jd@jd@mensah.com.com"},
+				{"password", "hash"},
+			}),
+			expected: &repository.Credentials{
+				ID:       hexID1,
+				Email:    "jd@jd@mensah.com.com",
+				Password: "hash",
+			},
+		},
+		{
+			name:         "user not found",
+			id:           hexID1,
+			mockResponse: mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch),
+			expectedErr:  errFindFailed,
+		},
+		{
+			name:        "invalid id",
+			id:          nonHexID,
+			expectedErr: errConvertToObjectID,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			c := &Client{
+				db: mt.DB,
+			}
+
+			got, err := c.GetCredentialsByID(context.Background(), tt.id)
+
+			if tt.expectedErr != "" {
+				assert.Nil(t, got)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, got)
+			}
 		})
 	}
 }
@@ -257,14 +581,14 @@ func TestClient_CreateUser(t *testing.T) {
 	}
 }
 
-func TestClient_UpdateUser(t *testing.T) {
+func TestClient_ReplaceUser(t *testing.T) {
 	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
 	defer mt.Close()
 
 	tests := []struct {
 		name               string
 		id                 string
-		data               *api.UserUpdateData
+		user               *repository.UserReplacement
 		mockUpdateResponse bson.D
 		expected           *api.User
 		expectedErr        string
@@ -272,8 +596,8 @@ func TestClient_UpdateUser(t *testing.T) {
 		{
 			name: "can update user",
 			id:   hexID1,
-			data: &api.UserUpdateData{
-				FirstName: pstring("john"),
+			user: &repository.UserReplacement{
+				FirstName: "john",
 			},
 			mockUpdateResponse: bson.D{
 				{"ok", 1},
@@ -302,8 +626,8 @@ func TestClient_UpdateUser(t *testing.T) {
 		{
 			name: "cannot update user",
 			id:   hexID1,
-			data: &api.UserUpdateData{
-				FirstName: pstring("john"),
+			user: &repository.UserReplacement{
+				FirstName: "john",
 			},
 			mockUpdateResponse: bson.D{},
 			expectedErr:        errUpdateFailed,
@@ -311,7 +635,6 @@ func TestClient_UpdateUser(t *testing.T) {
 		{
 			name:               "invalid id",
 			id:                 nonHexID,
-			data:               nil,
 			mockUpdateResponse: bson.D{},
 			expectedErr:        errConvertToObjectID,
 		},
@@ -326,7 +649,82 @@ func TestClient_UpdateUser(t *testing.T) {
 				db: mt.DB,
 			}
 
-			got, err := c.UpdateUser(context.Background(), tt.id, tt.data)
+			got, err := c.ReplaceUser(context.Background(), tt.id, tt.user, nil)
+
+			if tt.expectedErr != "" {
+				assert.Nil(t, got)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestClient_SetUserRoles(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tests := []struct {
+		name         string
+		id           string
+		mockResponse bson.D
+		expected     *api.User
+		expectedErr  string
+	}{
+		{
+			name: "can set user roles",
+			id:   hexID1,
+			mockResponse: bson.D{
+				{"ok", 1},
+				{"value", bson.D{
+					{"_id", hexID1},
+					{"first_name", "john"},
+					{"last_name", "doe"},
+					{"nickname", "jd"},
+					{"email", "jd@jd@mensah.com.com"},
+					{"country", "UK"},
+					{"created_at", createdAt},
+					{"updated_at", updatedAt},
+					{"roles", bson.A{"admin"}},
+				}},
+			},
+			expected: &api.User{
+				Id:        hexID1,
+				FirstName: "john",
+				LastName:  "doe",
+				Nickname:  "jd",
+				Email:     "jd@jd@mensah.com.com",
+				Country:   "UK",
+				CreatedAt: createdAt,
+				UpdatedAt: updatedAt,
+				Roles:     &api.Roles{"admin"},
+			},
+		},
+		{
+			name:         "cannot set user roles",
+			id:           hexID1,
+			mockResponse: bson.D{},
+			expectedErr:  errUpdateFailed,
+		},
+		{
+			name:        "invalid id",
+			id:          nonHexID,
+			expectedErr: errConvertToObjectID,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			c := &Client{
+				db: mt.DB,
+			}
+
+			got, err := c.SetUserRoles(context.Background(), tt.id, api.Roles{"admin"})
 
 			if tt.expectedErr != "" {
 				assert.Nil(t, got)
@@ -350,7 +748,7 @@ func TestClient_DeleteUser(t *testing.T) {
 		expectedErr  string
 	}{
 		{
-			name: "can delete user",
+			name: "soft deletes user",
 			id:   hexID1,
 			mockResponse: bson.D{
 				{"ok", 1},
@@ -389,7 +787,222 @@ func TestClient_DeleteUser(t *testing.T) {
 				db: mt.DB,
 			}
 
-			err := c.DeleteUser(context.Background(), tt.id)
+			err := c.DeleteUser(context.Background(), tt.id, nil)
+
+			if tt.expectedErr != "" {
+				assert.Contains(t, err.Error(), tt.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+
+			command := mt.GetStartedEvent().Command
+			assert.Equal(t, bson.TypeNull, command.Lookup("query", "deleted_at").Type)
+			assert.Equal(t, bson.TypeDateTime, command.Lookup("update", "$set", "deleted_at").Type)
+			assert.Equal(t, int32(1), command.Lookup("update", "$inc", "version").Int32())
+		})
+	}
+}
+
+func TestClient_VerifyEmail(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tests := []struct {
+		name         string
+		id           string
+		mockResponse bson.D
+		expectedErr  string
+	}{
+		{
+			name:         "can verify email",
+			id:           hexID1,
+			mockResponse: bson.D{{"ok", 1}, {"n", 1}, {"nModified", 1}},
+		},
+		{
+			name:         "user or email not found",
+			id:           hexID1,
+			mockResponse: bson.D{{"ok", 1}, {"n", 0}, {"nModified", 0}},
+			expectedErr:  repository.ErrNotFound.Error(),
+		},
+		{
+			name:         "cannot verify email",
+			id:           hexID1,
+			mockResponse: bson.D{{"ok", 0}},
+			expectedErr:  errVerifyEmailFailed,
+		},
+		{
+			name:        "invalid id",
+			id:          nonHexID,
+			expectedErr: errConvertToObjectID,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			c := &Client{
+				db: mt.DB,
+			}
+
+			err := c.VerifyEmail(context.Background(), tt.id, "jd@jd@mensah.com.com")
+
+			if tt.expectedErr != "" {
+				assert.Contains(t, err.Error(), tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestClient_ReplaceUser_Fields(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("changing the email resets its verification", func(mt *mtest.T) {
+		defer teardown(mt)
+
+		mt.AddMockResponses(bson.D{{"ok", 1}, {"value", bson.D{{"_id", hexID1}}}})
+
+		c := &Client{
+			db: mt.DB,
+		}
+
+		user := &repository.UserReplacement{Email: "new@mensah.com", EmailChanged: true}
+		_, err := c.ReplaceUser(context.Background(), hexID1, user, nil)
+		assert.NoError(t, err)
+
+		update := mt.GetStartedEvent().Command.Lookup("update").Document()
+		assert.Equal(t, "new@mensah.com", update.Lookup("$set", "email").StringValue())
+		assert.False(t, update.Lookup("$set", "email_verified").Boolean())
+		assert.NotNil(t, update.Lookup("$unset", "email_verified_at").Value)
+	})
+
+	mt.Run("replaces fields with empty values", func(mt *mtest.T) {
+		defer teardown(mt)
+
+		mt.AddMockResponses(bson.D{{"ok", 1}, {"value", bson.D{{"_id", hexID1}}}})
+
+		c := &Client{
+			db: mt.DB,
+		}
+
+		user := &repository.UserReplacement{FirstName: "john", Email: "jd@mensah.com"}
+		_, err := c.ReplaceUser(context.Background(), hexID1, user, nil)
+		assert.NoError(t, err)
+
+		command := mt.GetStartedEvent().Command
+		set := command.Lookup("update", "$set").Document()
+		assert.Equal(t, "", set.Lookup("nickname").StringValue())
+		assert.Equal(t, "", set.Lookup("last_name").StringValue())
+		assert.Nil(t, set.Lookup("password").Value)
+		assert.Nil(t, set.Lookup("email_verified").Value)
+		assert.Nil(t, command.Lookup("update", "$unset").Value)
+		assert.True(t, command.Lookup("new").Boolean())
+	})
+}
+
+func TestClient_SetPassword(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tests := []struct {
+		name         string
+		id           string
+		mockResponse bson.D
+		expectedErr  string
+	}{
+		{
+			name:         "can set password",
+			id:           hexID1,
+			mockResponse: bson.D{{"ok", 1}, {"n", 1}, {"nModified", 1}},
+		},
+		{
+			name:         "user not found",
+			id:           hexID1,
+			mockResponse: bson.D{{"ok", 1}, {"n", 0}, {"nModified", 0}},
+			expectedErr:  repository.ErrNotFound.Error(),
+		},
+		{
+			name:         "cannot set password",
+			id:           hexID1,
+			mockResponse: bson.D{{"ok", 0}},
+			expectedErr:  errSetPasswordFailed,
+		},
+		{
+			name:        "invalid id",
+			id:          nonHexID,
+			expectedErr: errConvertToObjectID,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			c := &Client{
+				db: mt.DB,
+			}
+
+			err := c.SetPassword(context.Background(), tt.id, "hash")
+
+			if tt.expectedErr != "" {
+				assert.Contains(t, err.Error(), tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestClient_RehashPassword(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tests := []struct {
+		name         string
+		id           string
+		mockResponse bson.D
+		expectedErr  string
+	}{
+		{
+			name:         "can rehash password",
+			id:           hexID1,
+			mockResponse: bson.D{{"ok", 1}, {"n", 1}, {"nModified", 1}},
+		},
+		{
+			name:         "password changed in the meantime",
+			id:           hexID1,
+			mockResponse: bson.D{{"ok", 1}, {"n", 0}, {"nModified", 0}},
+			expectedErr:  repository.ErrNotFound.Error(),
+		},
+		{
+			name:         "cannot rehash password",
+			id:           hexID1,
+			mockResponse: bson.D{{"ok", 0}},
+			expectedErr:  errRehashPasswordFailed,
+		},
+		{
+			name:        "invalid id",
+			id:          nonHexID,
+			expectedErr: errConvertToObjectID,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			c := &Client{
+				db: mt.DB,
+			}
+
+			err := c.RehashPassword(context.Background(), tt.id, "current", "hash")
 
 			if tt.expectedErr != "" {
 				assert.Contains(t, err.Error(), tt.expectedErr)
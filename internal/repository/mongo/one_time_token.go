@@ -0,0 +1,69 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/danielMensah/user-management/internal/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	errInsertOneTimeTokenFailed = "failed to insert one-time token into mongo"
+	errUseOneTimeTokenFailed    = "failed to use one-time token in mongo"
+	errFindOneTimeTokenFailed   = "failed to find one-time token in mongo"
+)
+
+// CreateOneTimeToken stores a one-time token
+func (c *Client) CreateOneTimeToken(ctx context.Context, token *repository.OneTimeToken) error {
+	token.CreatedAt = time.Now().UTC()
+
+	if _, err := c.db.Collection(collectionOneTimeTokens).InsertOne(ctx, token); err != nil {
+		return fmt.Errorf("%s: %w", errInsertOneTimeTokenFailed, repositoryError(err))
+	}
+
+	return nil
+}
+
+// GetOneTimeToken returns the unused and unexpired token with the given hash and purpose without using it
+func (c *Client) GetOneTimeToken(ctx context.Context, hash, purpose string) (*repository.OneTimeToken, error) {
+	result := c.db.Collection(collectionOneTimeTokens).FindOne(ctx, usableOneTimeToken(hash, purpose, time.Now().UTC()))
+
+	token := &repository.OneTimeToken{}
+	if err := result.Decode(token); err != nil {
+		return nil, fmt.Errorf("%s: %w", errFindOneTimeTokenFailed, repositoryError(err))
+	}
+
+	return token, nil
+}
+
+// UseOneTimeToken atomically marks the unused and unexpired token with the given hash and purpose as used
+// and returns it. Tokens that were already used or have expired are reported as not found.
+func (c *Client) UseOneTimeToken(ctx context.Context, hash, purpose string) (*repository.OneTimeToken, error) {
+	opts := options.FindOneAndUpdate()
+	opts.SetReturnDocument(options.After)
+
+	now := time.Now().UTC()
+	update := bson.M{"$set": bson.M{"used_at": now}}
+
+	result := c.db.Collection(collectionOneTimeTokens).FindOneAndUpdate(ctx, usableOneTimeToken(hash, purpose, now), update, opts)
+
+	token := &repository.OneTimeToken{}
+	if err := result.Decode(token); err != nil {
+		return nil, fmt.Errorf("%s: %w", errUseOneTimeTokenFailed, repositoryError(err))
+	}
+
+	return token, nil
+}
+
+// usableOneTimeToken filters the unused and unexpired token with the given hash and purpose
+func usableOneTimeToken(hash, purpose string, now time.Time) bson.M {
+	return bson.M{
+		"hash":       hash,
+		"purpose":    purpose,
+		"used_at":    bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": now},
+	}
+}
@@ -0,0 +1,184 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/danielMensah/user-management/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestClient_CreateOneTimeToken(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tests := []struct {
+		name         string
+		mockResponse bson.D
+		expectedErr  string
+	}{
+		{
+			name:         "can create one-time token",
+			mockResponse: bson.D{{"ok", 1}},
+		},
+		{
+			name:         "error creating one-time token",
+			mockResponse: bson.D{{"ok", 0}},
+			expectedErr:  errInsertOneTimeTokenFailed,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			c := &Client{
+				db: mt.DB,
+			}
+
+			token := &repository.OneTimeToken{
+				Hash:      "hash",
+				Purpose:   repository.TokenPurposePasswordReset,
+				UserID:    hexID1,
+				ExpiresAt: time.Now().Add(time.Hour),
+			}
+			err := c.CreateOneTimeToken(context.Background(), token)
+
+			if tt.expectedErr != "" {
+				assert.Contains(t, err.Error(), tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.False(t, token.CreatedAt.IsZero())
+			}
+		})
+	}
+}
+
+func TestClient_GetOneTimeToken(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		mockResponse bson.D
+		expected     *repository.OneTimeToken
+		expectedErr  string
+	}{
+		{
+			name: "can get one-time token",
+			mockResponse: mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{
+				{"hash", "hash"},
+				{"purpose", repository.TokenPurposePasswordReset},
+				{"user_id", hexID1},
+				{"expires_at", expiresAt},
+			}),
+			expected: &repository.OneTimeToken{
+				Hash:      "hash",
+				Purpose:   repository.TokenPurposePasswordReset,
+				UserID:    hexID1,
+				ExpiresAt: expiresAt,
+			},
+		},
+		{
+			name:         "token not found, used or expired",
+			mockResponse: mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch),
+			expectedErr:  repository.ErrNotFound.Error(),
+		},
+		{
+			name:         "error finding one-time token",
+			mockResponse: bson.D{{"ok", 0}},
+			expectedErr:  errFindOneTimeTokenFailed,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			c := &Client{
+				db: mt.DB,
+			}
+
+			got, err := c.GetOneTimeToken(context.Background(), "hash", repository.TokenPurposePasswordReset)
+
+			if tt.expectedErr != "" {
+				assert.Nil(t, got)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestClient_UseOneTimeToken(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		mockResponse bson.D
+		expected     *repository.OneTimeToken
+		expectedErr  string
+	}{
+		{
+			name: "can use one-time token",
+			mockResponse: bson.D{
+				{"ok", 1},
+				{"value", bson.D{
+					{"hash", "hash"},
+					{"purpose", repository.TokenPurposePasswordReset},
+					{"user_id", hexID1},
+					{"expires_at", expiresAt},
+				}},
+			},
+			expected: &repository.OneTimeToken{
+				Hash:      "hash",
+				Purpose:   repository.TokenPurposePasswordReset,
+				UserID:    hexID1,
+				ExpiresAt: expiresAt,
+			},
+		},
+		{
+			name:         "token not found, used or expired",
+			mockResponse: bson.D{{"ok", 1}, {"value", nil}},
+			expectedErr:  repository.ErrNotFound.Error(),
+		},
+		{
+			name:         "error using one-time token",
+			mockResponse: bson.D{{"ok", 0}},
+			expectedErr:  errUseOneTimeTokenFailed,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			c := &Client{
+				db: mt.DB,
+			}
+
+			got, err := c.UseOneTimeToken(context.Background(), "hash", repository.TokenPurposePasswordReset)
+
+			if tt.expectedErr != "" {
+				assert.Nil(t, got)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, got)
+			}
+		})
+	}
+}
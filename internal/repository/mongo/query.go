@@ -0,0 +1,193 @@
+package mongo
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/danielMensah/user-management/internal/pagination"
+	"github.com/danielMensah/user-management/internal/query"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const errUnknownOperator = "unknown filter operator"
+
+// comparisons maps the range operators of filters to their mongo operator. Operators are never taken from requests
+// as they are, so that requests cannot inject operators of their own.
+var comparisons = map[query.Operator]string{
+	query.OperatorGt:  "$gt",
+	query.OperatorGte: "$gte",
+	query.OperatorLt:  "$lt",
+	query.OperatorLte: "$lte",
+}
+
+// usersFilter returns the mongo filter of the users matching all the conditions of a filter, along with the
+// collation it has to be applied with. Emails are compared ignoring case.
+func usersFilter(filter query.Filter) (bson.M, *options.Collation, error) {
+	var collation *options.Collation
+
+	conditions := make(bson.A, 0, len(filter))
+	for _, condition := range filter {
+		translated, err := conditionFilter(condition)
+		if err != nil {
+			return nil, nil, err
+		}
+		conditions = append(conditions, translated)
+
+		if condition.Field == query.FieldEmail {
+			collation = caseInsensitive
+		}
+	}
+
+	if len(conditions) == 0 {
+		return bson.M{}, collation, nil
+	}
+
+	return bson.M{"$and": conditions}, collation, nil
+}
+
+func conditionFilter(condition query.Condition) (bson.M, error) {
+	name := condition.Field.Name
+
+	values := make(bson.A, 0, len(condition.Values))
+	for _, value := range condition.Values {
+		converted, err := bsonValue(condition.Field, value)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, converted)
+	}
+
+	switch condition.Operator {
+	case query.OperatorEq:
+		return bson.M{name: values[0]}, nil
+	case query.OperatorIn:
+		return bson.M{name: bson.M{"$in": values}}, nil
+	case query.OperatorPrefix:
+		return bson.M{name: prefixRegex(condition.Field, fmt.Sprint(values[0]))}, nil
+	}
+
+	operator, ok := comparisons[condition.Operator]
+	if !ok {
+		return nil, fmt.Errorf("%s: %q", errUnknownOperator, condition.Operator)
+	}
+
+	return bson.M{name: bson.M{operator: values[0]}}, nil
+}
+
+// prefixRegex matches the values starting with the prefix, taken literally rather than as a pattern
+func prefixRegex(field query.Field, prefix string) primitive.Regex {
+	regex := primitive.Regex{Pattern: "^" + regexp.QuoteMeta(prefix)}
+	if field == query.FieldEmail {
+		regex.Options = "i"
+	}
+
+	return regex
+}
+
+// bsonValue returns the value of a field as it is stored
+func bsonValue(field query.Field, value interface{}) (interface{}, error) {
+	if field.Type != query.TypeID {
+		return value, nil
+	}
+
+	return objectID(fmt.Sprint(value))
+}
+
+// projection returns the mongo projection of the given fields and of the fields of the sort, which cursors are made
+// of. It returns nil when no fields are given, to return every field.
+func projection(fields []query.Field, sort query.Sort) bson.M {
+	if len(fields) == 0 {
+		return nil
+	}
+
+	projection := bson.M{}
+	for _, field := range fields {
+		projection[field.Name] = 1
+	}
+	for _, field := range sort {
+		projection[field.Field.Name] = 1
+	}
+
+	return projection
+}
+
+// sortDocument returns the mongo sort of users, reversed when listing them backwards
+func sortDocument(sort query.Sort, backwards bool) bson.D {
+	document := make(bson.D, 0, len(sort))
+	for _, field := range sort {
+		order := 1
+		if field.Desc != backwards {
+			order = -1
+		}
+		document = append(document, bson.E{Key: field.Field.Name, Value: order})
+	}
+
+	return document
+}
+
+// keysetFilter matches the users after the position of a cursor in the given sort, or before it when the cursor
+// points backwards. A user is after the position when the first sort field they differ from it by is after it.
+func keysetFilter(sort query.Sort, cursor *pagination.Cursor) (bson.A, error) {
+	if cursor.Sort != sort.String() || len(cursor.Values) != len(sort) {
+		return nil, pagination.ErrInvalidCursor
+	}
+
+	keyset := make(bson.A, 0, len(sort))
+	equal := bson.M{}
+	for i, field := range sort {
+		value, err := cursorValue(field.Field, cursor.Values[i])
+		if err != nil {
+			return nil, err
+		}
+
+		operator := "$gt"
+		if field.Desc != cursor.Before {
+			operator = "$lt"
+		}
+
+		condition := bson.M{field.Field.Name: bson.M{operator: value}}
+		for name, equalValue := range equal {
+			condition[name] = equalValue
+		}
+		keyset = append(keyset, condition)
+		equal[field.Field.Name] = value
+	}
+
+	return keyset, nil
+}
+
+func cursorValue(field query.Field, raw string) (interface{}, error) {
+	value, err := field.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", pagination.ErrInvalidCursor, err)
+	}
+
+	return bsonValue(field, value)
+}
+
+// userCursor returns a cursor pointing after the stored user in the given sort, or before them
+func userCursor(user bson.Raw, sort query.Sort, before bool) *pagination.Cursor {
+	values := make([]string, 0, len(sort))
+	for _, field := range sort {
+		values = append(values, rawString(field.Field, user.Lookup(field.Field.Name)))
+	}
+
+	return &pagination.Cursor{Sort: sort.String(), Values: values, Before: before}
+}
+
+// rawString returns a stored value of a field written as a string, the way the field parses it
+func rawString(field query.Field, value bson.RawValue) string {
+	switch value.Type {
+	case bsontype.ObjectID:
+		return value.ObjectID().Hex()
+	case bsontype.DateTime:
+		return field.Format(value.Time())
+	case bsontype.String:
+		return value.StringValue()
+	default:
+		return value.String()
+	}
+}
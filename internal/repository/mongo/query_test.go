@@ -0,0 +1,169 @@
+package mongo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/danielMensah/user-management/internal/pagination"
+	"github.com/danielMensah/user-management/internal/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestUsersFilter(t *testing.T) {
+	since := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	pid, err := primitive.ObjectIDFromHex(hexID1)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name              string
+		filter            query.Filter
+		expected          bson.M
+		expectedCollation *options.Collation
+		expectedErr       string
+	}{
+		{
+			name:     "no filter",
+			expected: bson.M{},
+		},
+		{
+			name: "translates conditions",
+			filter: query.Filter{
+				query.Eq(query.FieldCountry, "UK"),
+				{Field: query.FieldNickname, Operator: query.OperatorPrefix, Values: []interface{}{"j.d*"}},
+				{Field: query.FieldID, Operator: query.OperatorIn, Values: []interface{}{hexID1}},
+				{Field: query.FieldCreatedAt, Operator: query.OperatorGte, Values: []interface{}{since}},
+			},
+			expected: bson.M{"$and": bson.A{
+				bson.M{"country": "UK"},
+				bson.M{"nickname": primitive.Regex{Pattern: `^j\.d\*`}},
+				bson.M{"_id": bson.M{"$in": bson.A{pid}}},
+				bson.M{"created_at": bson.M{"$gte": since}},
+			}},
+		},
+		{
+			name: "compares emails ignoring case",
+			filter: query.Filter{
+				{Field: query.FieldEmail, Operator: query.OperatorPrefix, Values: []interface{}{"JD@"}},
+			},
+			expected:          bson.M{"$and": bson.A{bson.M{"email": primitive.Regex{Pattern: "^JD@", Options: "i"}}}},
+			expectedCollation: caseInsensitive,
+		},
+		{
+			name: "unknown operator",
+			filter: query.Filter{
+				{Field: query.FieldCountry, Operator: "$where", Values: []interface{}{"UK"}},
+			},
+			expectedErr: errUnknownOperator,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, collation, err := usersFilter(tt.filter)
+
+			if tt.expectedErr != "" {
+				assert.ErrorContains(t, err, tt.expectedErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, filter)
+			assert.Equal(t, tt.expectedCollation, collation)
+		})
+	}
+}
+
+func TestProjection(t *testing.T) {
+	assert.Nil(t, projection(nil, query.DefaultSort))
+
+	fields := []query.Field{query.FieldNickname, query.FieldCountry}
+	expected := bson.M{"nickname": 1, "country": 1, "created_at": 1, "_id": 1}
+	assert.Equal(t, expected, projection(fields, query.DefaultSort))
+}
+
+func TestSortDocument(t *testing.T) {
+	sort, err := query.ParseSort("-updated_at,nickname")
+	require.NoError(t, err)
+
+	assert.Equal(t, bson.D{{"updated_at", -1}, {"nickname", 1}, {"_id", 1}}, sortDocument(sort, false))
+	assert.Equal(t, bson.D{{"updated_at", 1}, {"nickname", -1}, {"_id", -1}}, sortDocument(sort, true))
+}
+
+func TestKeysetFilter(t *testing.T) {
+	sort, err := query.ParseSort("-updated_at,nickname")
+	require.NoError(t, err)
+
+	pid, err := primitive.ObjectIDFromHex(hexID1)
+	require.NoError(t, err)
+	at := time.Date(2022, 9, 1, 10, 0, 0, 0, time.UTC)
+	values := []string{"2022-09-01T10:00:00Z", "jd", hexID1}
+
+	tests := []struct {
+		name        string
+		cursor      *pagination.Cursor
+		expected    bson.A
+		expectedErr error
+	}{
+		{
+			name:   "users after the cursor",
+			cursor: &pagination.Cursor{Sort: sort.String(), Values: values},
+			expected: bson.A{
+				bson.M{"updated_at": bson.M{"$lt": at}},
+				bson.M{"updated_at": at, "nickname": bson.M{"$gt": "jd"}},
+				bson.M{"updated_at": at, "nickname": "jd", "_id": bson.M{"$gt": pid}},
+			},
+		},
+		{
+			name:   "users before the cursor",
+			cursor: &pagination.Cursor{Sort: sort.String(), Values: values, Before: true},
+			expected: bson.A{
+				bson.M{"updated_at": bson.M{"$gt": at}},
+				bson.M{"updated_at": at, "nickname": bson.M{"$lt": "jd"}},
+				bson.M{"updated_at": at, "nickname": "jd", "_id": bson.M{"$lt": pid}},
+			},
+		},
+		{
+			name:        "cursor of another sort",
+			cursor:      &pagination.Cursor{Sort: query.DefaultSort.String(), Values: values},
+			expectedErr: pagination.ErrInvalidCursor,
+		},
+		{
+			name:        "invalid value",
+			cursor:      &pagination.Cursor{Sort: sort.String(), Values: []string{"yesterday", "jd", hexID1}},
+			expectedErr: pagination.ErrInvalidCursor,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyset, err := keysetFilter(sort, tt.cursor)
+
+			assert.ErrorIs(t, err, tt.expectedErr)
+			assert.Equal(t, tt.expected, keyset)
+		})
+	}
+}
+
+func TestUserCursor(t *testing.T) {
+	pid, err := primitive.ObjectIDFromHex(hexID1)
+	require.NoError(t, err)
+
+	user, err := bson.Marshal(bson.D{
+		{"_id", pid},
+		{"nickname", "jd"},
+		{"updated_at", time.Date(2022, 9, 1, 10, 0, 0, 0, time.UTC)},
+	})
+	require.NoError(t, err)
+
+	sort, err := query.ParseSort("-updated_at,nickname")
+	require.NoError(t, err)
+
+	expected := &pagination.Cursor{
+		Sort:   "-updated_at,nickname,_id",
+		Values: []string{"2022-09-01T10:00:00Z", "jd", hexID1},
+		Before: true,
+	}
+	assert.Equal(t, expected, userCursor(user, sort, true))
+}
@@ -0,0 +1,88 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/danielMensah/user-management/internal/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	errInsertRefreshTokenFailed = "failed to insert refresh token into mongo"
+	errFindRefreshTokenFailed   = "failed to find refresh token in mongo"
+	errUseRefreshTokenFailed    = "failed to use refresh token in mongo"
+	errRevokeRefreshTokenFailed = "failed to revoke refresh token family in mongo"
+	errRevokeUserTokensFailed   = "failed to revoke refresh tokens in mongo"
+)
+
+// CreateRefreshToken stores a refresh token. A new token family is started when the token has no family.
+func (c *Client) CreateRefreshToken(ctx context.Context, token *repository.RefreshToken) error {
+	if token.FamilyID == "" {
+		token.FamilyID = primitive.NewObjectID().Hex()
+	}
+	token.CreatedAt = time.Now().UTC()
+
+	if _, err := c.db.Collection(collectionRefreshTokens).InsertOne(ctx, token); err != nil {
+		return fmt.Errorf("%s: %w", errInsertRefreshTokenFailed, repositoryError(err))
+	}
+
+	return nil
+}
+
+// GetRefreshToken returns the refresh token with the given hash
+func (c *Client) GetRefreshToken(ctx context.Context, hash string) (*repository.RefreshToken, error) {
+	result := c.db.Collection(collectionRefreshTokens).FindOne(ctx, bson.M{"hash": hash})
+
+	token := &repository.RefreshToken{}
+	if err := result.Decode(token); err != nil {
+		return nil, fmt.Errorf("%s: %w", errFindRefreshTokenFailed, repositoryError(err))
+	}
+
+	return token, nil
+}
+
+// UseRefreshToken atomically marks the refresh token with the given hash as used and returns it as it was
+// before, so that callers can detect tokens that had already been used
+func (c *Client) UseRefreshToken(ctx context.Context, hash string) (*repository.RefreshToken, error) {
+	opts := options.FindOneAndUpdate()
+	opts.SetReturnDocument(options.Before)
+
+	// $min keeps the time of the first use when a token is presented again
+	update := bson.M{"$min": bson.M{"used_at": time.Now().UTC()}}
+	result := c.db.Collection(collectionRefreshTokens).FindOneAndUpdate(ctx, bson.M{"hash": hash}, update, opts)
+
+	token := &repository.RefreshToken{}
+	if err := result.Decode(token); err != nil {
+		return nil, fmt.Errorf("%s: %w", errUseRefreshTokenFailed, repositoryError(err))
+	}
+
+	return token, nil
+}
+
+// RevokeRefreshTokenFamily revokes every refresh token of the given family
+func (c *Client) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	filter := bson.M{"family_id": familyID, "revoked_at": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"revoked_at": time.Now().UTC()}}
+
+	if _, err := c.db.Collection(collectionRefreshTokens).UpdateMany(ctx, filter, update); err != nil {
+		return fmt.Errorf("%s '%s': %w", errRevokeRefreshTokenFailed, familyID, repositoryError(err))
+	}
+
+	return nil
+}
+
+// RevokeUserRefreshTokens revokes every refresh token of the given user
+func (c *Client) RevokeUserRefreshTokens(ctx context.Context, userID string) error {
+	filter := bson.M{"user_id": userID, "revoked_at": bson.M{"$exists": false}}
+	update := bson.M{"$set": bson.M{"revoked_at": time.Now().UTC()}}
+
+	if _, err := c.db.Collection(collectionRefreshTokens).UpdateMany(ctx, filter, update); err != nil {
+		return fmt.Errorf("%s of user '%s': %w", errRevokeUserTokensFailed, userID, repositoryError(err))
+	}
+
+	return nil
+}
@@ -0,0 +1,303 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/danielMensah/user-management/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestClient_CreateRefreshToken(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tests := []struct {
+		name             string
+		token            *repository.RefreshToken
+		mockResponse     bson.D
+		expectedFamilyID string
+		expectedErr      string
+	}{
+		{
+			name: "starts a new family",
+			token: &repository.RefreshToken{
+				Hash:   "hash",
+				UserID: hexID1,
+			},
+			mockResponse: bson.D{{"ok", 1}},
+		},
+		{
+			name: "keeps an existing family",
+			token: &repository.RefreshToken{
+				Hash:     "hash",
+				FamilyID: "family",
+				UserID:   hexID1,
+			},
+			mockResponse:     bson.D{{"ok", 1}},
+			expectedFamilyID: "family",
+		},
+		{
+			name: "error creating refresh token",
+			token: &repository.RefreshToken{
+				Hash:   "hash",
+				UserID: hexID1,
+			},
+			mockResponse: bson.D{{"ok", 0}},
+			expectedErr:  errInsertRefreshTokenFailed,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			c := &Client{
+				db: mt.DB,
+			}
+
+			err := c.CreateRefreshToken(context.Background(), tt.token)
+
+			if tt.expectedErr != "" {
+				assert.Contains(t, err.Error(), tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.NotEmpty(t, tt.token.FamilyID)
+				assert.False(t, tt.token.CreatedAt.IsZero())
+
+				if tt.expectedFamilyID != "" {
+					assert.Equal(t, tt.expectedFamilyID, tt.token.FamilyID)
+				}
+			}
+		})
+	}
+}
+
+func TestClient_GetRefreshToken(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tests := []struct {
+		name         string
+		mockResponse bson.D
+		expected     *repository.RefreshToken
+		expectedErr  error
+	}{
+		{
+			name: "can get refresh token",
+			mockResponse: mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{
+				{"_id", hexID1},
+				{"hash", "hash"},
+				{"family_id", "family"},
+				{"user_id", hexID2},
+				{"created_at", createdAt},
+				{"expires_at", updatedAt},
+			}),
+			expected: &repository.RefreshToken{
+				ID:        hexID1,
+				Hash:      "hash",
+				FamilyID:  "family",
+				UserID:    hexID2,
+				CreatedAt: createdAt,
+				ExpiresAt: updatedAt,
+			},
+		},
+		{
+			name:         "refresh token not found",
+			mockResponse: mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch),
+			expectedErr:  repository.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			c := &Client{
+				db: mt.DB,
+			}
+
+			got, err := c.GetRefreshToken(context.Background(), "hash")
+
+			if tt.expectedErr != nil {
+				assert.Nil(t, got)
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestClient_UseRefreshToken(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	usedAt := updatedAt.Add(time.Hour)
+
+	tests := []struct {
+		name         string
+		mockResponse bson.D
+		expected     *repository.RefreshToken
+		expectedErr  error
+	}{
+		{
+			name: "returns unused refresh token",
+			mockResponse: bson.D{
+				{"ok", 1},
+				{"value", bson.D{
+					{"_id", hexID1},
+					{"hash", "hash"},
+					{"family_id", "family"},
+					{"user_id", hexID2},
+					{"created_at", createdAt},
+					{"expires_at", updatedAt},
+				}},
+			},
+			expected: &repository.RefreshToken{
+				ID:        hexID1,
+				Hash:      "hash",
+				FamilyID:  "family",
+				UserID:    hexID2,
+				CreatedAt: createdAt,
+				ExpiresAt: updatedAt,
+			},
+		},
+		{
+			name: "returns previously used refresh token",
+			mockResponse: bson.D{
+				{"ok", 1},
+				{"value", bson.D{
+					{"_id", hexID1},
+					{"hash", "hash"},
+					{"family_id", "family"},
+					{"user_id", hexID2},
+					{"created_at", createdAt},
+					{"expires_at", updatedAt},
+					{"used_at", usedAt},
+				}},
+			},
+			expected: &repository.RefreshToken{
+				ID:        hexID1,
+				Hash:      "hash",
+				FamilyID:  "family",
+				UserID:    hexID2,
+				CreatedAt: createdAt,
+				ExpiresAt: updatedAt,
+				UsedAt:    &usedAt,
+			},
+		},
+		{
+			name:         "refresh token not found",
+			mockResponse: bson.D{{"ok", 1}, {"value", nil}},
+			expectedErr:  repository.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			c := &Client{
+				db: mt.DB,
+			}
+
+			got, err := c.UseRefreshToken(context.Background(), "hash")
+
+			if tt.expectedErr != nil {
+				assert.Nil(t, got)
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestClient_RevokeRefreshTokenFamily(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tests := []struct {
+		name         string
+		mockResponse bson.D
+		expectedErr  string
+	}{
+		{
+			name:         "can revoke refresh token family",
+			mockResponse: bson.D{{"ok", 1}, {"n", 2}, {"nModified", 2}},
+		},
+		{
+			name:         "error revoking refresh token family",
+			mockResponse: bson.D{{"ok", 0}},
+			expectedErr:  errRevokeRefreshTokenFailed,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			c := &Client{
+				db: mt.DB,
+			}
+
+			err := c.RevokeRefreshTokenFamily(context.Background(), "family")
+
+			if tt.expectedErr != "" {
+				assert.Contains(t, err.Error(), tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestClient_RevokeUserRefreshTokens(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tests := []struct {
+		name         string
+		mockResponse bson.D
+		expectedErr  string
+	}{
+		{
+			name:         "can revoke user refresh tokens",
+			mockResponse: bson.D{{"ok", 1}, {"n", 2}, {"nModified", 2}},
+		},
+		{
+			name:         "error revoking user refresh tokens",
+			mockResponse: bson.D{{"ok", 0}},
+			expectedErr:  errRevokeUserTokensFailed,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			c := &Client{
+				db: mt.DB,
+			}
+
+			err := c.RevokeUserRefreshTokens(context.Background(), hexID1)
+
+			if tt.expectedErr != "" {
+				assert.Contains(t, err.Error(), tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
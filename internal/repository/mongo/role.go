@@ -0,0 +1,101 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	errRetrieveRolesFailed = "failed to retrieve roles from mongo"
+	errInsertRoleFailed    = "failed to insert role into mongo"
+	errUpdateRoleFailed    = "failed to update role in mongo"
+	errDeleteRoleFailed    = "failed to delete role from mongo"
+)
+
+// GetRoles returns every custom role
+func (c *Client) GetRoles(ctx context.Context) ([]api.RoleDefinition, error) {
+	return c.findRoles(ctx, bson.M{})
+}
+
+// GetRolesByName returns the custom roles with the given names
+func (c *Client) GetRolesByName(ctx context.Context, names []string) ([]api.RoleDefinition, error) {
+	return c.findRoles(ctx, bson.M{"_id": bson.M{"$in": names}})
+}
+
+func (c *Client) findRoles(ctx context.Context, filter bson.M) ([]api.RoleDefinition, error) {
+	opts := options.Find()
+	opts.SetSort(bson.M{"_id": 1})
+
+	cursor, err := c.db.Collection(collectionRoles).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errRetrieveRolesFailed, repositoryError(err))
+	}
+	defer cursor.Close(ctx)
+
+	roles := make([]api.RoleDefinition, 0)
+	if err = cursor.All(ctx, &roles); err != nil {
+		return nil, fmt.Errorf("%s: %w", errCursorAllFailed, repositoryError(err))
+	}
+
+	return roles, nil
+}
+
+// CreateRole creates a new custom role
+func (c *Client) CreateRole(ctx context.Context, data *api.RoleCreateData) (*api.RoleDefinition, error) {
+	now := time.Now().UTC()
+	role := &api.RoleDefinition{
+		Name:        data.Name,
+		Description: data.Description,
+		Permissions: data.Permissions,
+		CreatedAt:   &now,
+		UpdatedAt:   &now,
+	}
+
+	if _, err := c.db.Collection(collectionRoles).InsertOne(ctx, role); err != nil {
+		return nil, fmt.Errorf("%s with name '%s': %w", errInsertRoleFailed, data.Name, repositoryError(err))
+	}
+
+	return role, nil
+}
+
+// UpdateRole replaces the description and permissions of a custom role
+func (c *Client) UpdateRole(ctx context.Context, name string, data *api.RoleUpdateData) (*api.RoleDefinition, error) {
+	opts := options.FindOneAndUpdate()
+	opts.SetReturnDocument(options.After)
+
+	set := bson.M{"permissions": data.Permissions, "updated_at": time.Now().UTC()}
+	update := bson.M{"$set": set, "$unset": bson.M{"description": ""}}
+	if data.Description != nil {
+		set["description"] = *data.Description
+		delete(update, "$unset")
+	}
+
+	result := c.db.Collection(collectionRoles).FindOneAndUpdate(ctx, bson.M{"_id": name}, update, opts)
+
+	role := &api.RoleDefinition{}
+	if err := result.Decode(role); err != nil {
+		return nil, fmt.Errorf("%s with name '%s': %w", errUpdateRoleFailed, name, repositoryError(err))
+	}
+
+	return role, nil
+}
+
+// DeleteRole deletes a custom role
+func (c *Client) DeleteRole(ctx context.Context, name string) error {
+	result, err := c.db.Collection(collectionRoles).DeleteOne(ctx, bson.M{"_id": name})
+	if err != nil {
+		return fmt.Errorf("%s with name '%s': %w", errDeleteRoleFailed, name, repositoryError(err))
+	}
+
+	if result.DeletedCount == 0 {
+		return fmt.Errorf("%s with name '%s': %w", errDeleteRoleFailed, name, repository.ErrNotFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,223 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestClient_GetRolesByName(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tests := []struct {
+		name         string
+		mockResponse bson.D
+		expected     []api.RoleDefinition
+		expectedErr  string
+	}{
+		{
+			name: "can get roles",
+			mockResponse: mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{
+				{"_id", "support-agent"},
+				{"description", "support"},
+				{"permissions", bson.A{"users:read", "users:update:profile"}},
+			}),
+			expected: []api.RoleDefinition{
+				{
+					Name:        "support-agent",
+					Description: pstring("support"),
+					Permissions: []api.Permission{"users:read", "users:update:profile"},
+				},
+			},
+		},
+		{
+			name:         "no roles",
+			mockResponse: mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch),
+			expected:     []api.RoleDefinition{},
+		},
+		{
+			name:         "cannot get roles",
+			mockResponse: bson.D{{"ok", 0}},
+			expectedErr:  errRetrieveRolesFailed,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			c := &Client{
+				db: mt.DB,
+			}
+
+			got, err := c.GetRolesByName(context.Background(), []string{"support-agent"})
+
+			if tt.expectedErr != "" {
+				assert.Nil(t, got)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestClient_CreateRole(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tests := []struct {
+		name         string
+		mockResponse bson.D
+		expectedErr  error
+	}{
+		{
+			name:         "can create role",
+			mockResponse: bson.D{{"ok", 1}},
+		},
+		{
+			name: "duplicate role",
+			mockResponse: mtest.CreateWriteErrorsResponse(mtest.WriteError{
+				Index:   0,
+				Code:    11000,
+				Message: "duplicate key error",
+			}),
+			expectedErr: repository.ErrConflict,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			c := &Client{
+				db: mt.DB,
+			}
+
+			got, err := c.CreateRole(context.Background(), &api.RoleCreateData{
+				Name:        "support-agent",
+				Permissions: []api.Permission{"users:read"},
+			})
+
+			if tt.expectedErr != nil {
+				assert.Nil(t, got)
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "support-agent", got.Name)
+				assert.NotNil(t, got.CreatedAt)
+			}
+		})
+	}
+}
+
+func TestClient_UpdateRole(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tests := []struct {
+		name         string
+		mockResponse bson.D
+		expected     *api.RoleDefinition
+		expectedErr  string
+	}{
+		{
+			name: "can update role",
+			mockResponse: bson.D{
+				{"ok", 1},
+				{"value", bson.D{
+					{"_id", "support-agent"},
+					{"permissions", bson.A{"users:read"}},
+				}},
+			},
+			expected: &api.RoleDefinition{Name: "support-agent", Permissions: []api.Permission{"users:read"}},
+		},
+		{
+			name:         "role not found",
+			mockResponse: bson.D{{"ok", 1}, {"value", nil}},
+			expectedErr:  repository.ErrNotFound.Error(),
+		},
+		{
+			name:         "cannot update role",
+			mockResponse: bson.D{{"ok", 0}},
+			expectedErr:  errUpdateRoleFailed,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			c := &Client{
+				db: mt.DB,
+			}
+
+			got, err := c.UpdateRole(context.Background(), "support-agent", &api.RoleUpdateData{
+				Permissions: []api.Permission{"users:read"},
+			})
+
+			if tt.expectedErr != "" {
+				assert.Nil(t, got)
+				assert.Contains(t, err.Error(), tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestClient_DeleteRole(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	tests := []struct {
+		name         string
+		mockResponse bson.D
+		expectedErr  string
+	}{
+		{
+			name:         "can delete role",
+			mockResponse: bson.D{{"ok", 1}, {"acknowledged", true}, {"n", 1}},
+		},
+		{
+			name:         "role not found",
+			mockResponse: bson.D{{"ok", 1}, {"acknowledged", true}, {"n", 0}},
+			expectedErr:  repository.ErrNotFound.Error(),
+		},
+		{
+			name:         "cannot delete role",
+			mockResponse: bson.D{{"ok", 0}},
+			expectedErr:  errDeleteRoleFailed,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			mt.AddMockResponses(tt.mockResponse)
+
+			c := &Client{
+				db: mt.DB,
+			}
+
+			err := c.DeleteRole(context.Background(), "support-agent")
+
+			if tt.expectedErr != "" {
+				assert.Contains(t, err.Error(), tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// minTextSearchLength is the length a word of a search must have for the search to use the text index. Searches
+	// made of shorter words only match the users having a search term starting with each of them instead.
+	minTextSearchLength = minPrefixLength
+
+	errSearchFailed = "failed to search users in mongo"
+)
+
+// textScore is the relevance of a user to a text search
+var textScore = bson.M{"$meta": "textScore"}
+
+// SearchUsers returns a page of the users matching a search and its filter. Text searches are ranked by relevance,
+// while searches of short words are sorted by nickname. Ties are broken by id, so that pages do not overlap.
+func (c *Client) SearchUsers(ctx context.Context, search repository.UserSearch) (*repository.UserPage, error) {
+	filter, collation, err := searchFilter(search)
+	if err != nil {
+		return nil, err
+	}
+
+	// one more user than asked for tells whether there is another page
+	opts := options.Find()
+	opts.SetLimit(search.Limit + 1)
+	opts.SetSkip(search.Skip)
+	opts.SetCollation(collation)
+	if isTextSearch(splitWords(search.Text)) {
+		opts.SetProjection(bson.M{"score": textScore, fieldSearchTerms: 0})
+		opts.SetSort(bson.D{{Key: "score", Value: textScore}, {Key: "_id", Value: 1}})
+	} else {
+		opts.SetProjection(bson.M{fieldSearchTerms: 0})
+		opts.SetSort(bson.D{{Key: "nickname", Value: 1}, {Key: "_id", Value: 1}})
+	}
+
+	result, err := c.db.Collection(collectionUsers).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", errSearchFailed, repositoryError(err))
+	}
+	defer result.Close(ctx)
+
+	users := make([]api.User, 0)
+	if err = result.All(ctx, &users); err != nil {
+		return nil, fmt.Errorf("%s: %w", errCursorAllFailed, repositoryError(err))
+	}
+
+	more := int64(len(users)) > search.Limit
+	if more {
+		users = users[:search.Limit]
+	}
+
+	return &repository.UserPage{Users: users, More: more}, nil
+}
+
+// CountSearchedUsers returns the number of users matching a search and its filter
+func (c *Client) CountSearchedUsers(ctx context.Context, search repository.UserSearch) (int64, error) {
+	filter, collation, err := searchFilter(search)
+	if err != nil {
+		return 0, err
+	}
+
+	opts := options.Count().SetCollation(collation)
+	count, err := c.db.Collection(collectionUsers).CountDocuments(ctx, filter, opts)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", errCountFailed, repositoryError(err))
+	}
+
+	return count, nil
+}
+
+// searchFilter returns the mongo filter of the users matching a search, along with the collation it has to be
+// applied with. Searches having a word long enough use the text index, matching the users having any of the words, or
+// a word sounding alike, in a searched field or in their search terms. Otherwise every word of the search must start
+// a search term of the user, so that partial words match as they are typed. Soft deleted users are never searched.
+func searchFilter(search repository.UserSearch) (bson.M, *options.Collation, error) {
+	filter, collation, err := usersFilter(search.Filter)
+	if err != nil {
+		return nil, nil, err
+	}
+	excludeDeleted(filter)
+
+	words := splitWords(search.Text)
+	if len(words) == 0 {
+		return filter, collation, nil
+	}
+
+	if isTextSearch(words) {
+		filter["$text"] = bson.M{"$search": strings.Join(queryTerms(words), " ")}
+		return filter, collation, nil
+	}
+
+	// search terms are lowercase, so that anchored regexes can use their index
+	prefixes := make(bson.A, 0, len(words))
+	for _, word := range words {
+		prefixes = append(prefixes, primitive.Regex{Pattern: "^" + regexp.QuoteMeta(word)})
+	}
+	filter[fieldSearchTerms] = bson.M{"$all": prefixes}
+
+	return filter, collation, nil
+}
+
+// isTextSearch reports whether a search of the given words has a word long enough to use the text index
+func isTextSearch(words []string) bool {
+	for _, word := range words {
+		if utf8.RuneCountInString(word) >= minTextSearchLength {
+			return true
+		}
+	}
+
+	return false
+}
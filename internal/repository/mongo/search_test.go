@@ -0,0 +1,250 @@
+package mongo
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/query"
+	"github.com/danielMensah/user-management/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestClient_SearchUsers(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	john := bson.D{{"_id", hexID1}, {"first_name", "john"}, {"nickname", "jd"}}
+	jane := bson.D{{"_id", hexID2}, {"first_name", "jane"}, {"nickname", "jd"}}
+	johnUser := api.User{Id: hexID1, FirstName: "john", Nickname: "jd"}
+	janeUser := api.User{Id: hexID2, FirstName: "jane", Nickname: "jd"}
+
+	tests := []struct {
+		name           string
+		search         repository.UserSearch
+		mockResponses  []bson.D
+		mockError      bool
+		expected       *repository.UserPage
+		expectedText   string
+		expectedPrefix []string
+		expectedErr    error
+	}{
+		{
+			name:          "ranks users by relevance to the words and how they sound",
+			search:        repository.UserSearch{Text: " Jon  do ", Limit: 10},
+			mockResponses: []bson.D{append(john, bson.E{Key: "score", Value: 1.5}), jane},
+			expected:      &repository.UserPage{Users: []api.User{johnUser, janeUser}},
+			expectedText:  "jon j500 do d000",
+		},
+		{
+			name:          "tells there are more results",
+			search:        repository.UserSearch{Text: "john", Limit: 1, Skip: 1},
+			mockResponses: []bson.D{john, jane},
+			expected:      &repository.UserPage{Users: []api.User{johnUser}, More: true},
+			expectedText:  "john j500",
+		},
+		{
+			name: "matches short words as prefixes of search terms along with the filter",
+			search: repository.UserSearch{
+				Text:   "J. d",
+				Filter: query.Filter{query.Eq(query.FieldCountry, "UK")},
+				Limit:  10,
+			},
+			mockResponses:  []bson.D{jane},
+			expected:       &repository.UserPage{Users: []api.User{janeUser}},
+			expectedPrefix: []string{"^j", "^d"},
+		},
+		{
+			name:        "error searching users",
+			search:      repository.UserSearch{Text: "jon", Limit: 10},
+			mockError:   true,
+			expectedErr: repository.ErrUnavailable,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			defer teardown(mt)
+
+			if tt.mockError {
+				mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{
+					Code:    0,
+					Message: "error",
+					Name:    "foo.bar.error",
+					Labels:  []string{"NetworkError"},
+				}))
+			} else {
+				r := make([]bson.D, 0)
+				for i, b := range tt.mockResponses {
+					id := mtest.FirstBatch
+					if i > 0 {
+						id = mtest.NextBatch
+					}
+
+					r = append(r, mtest.CreateCursorResponse(1, "foo.bar", id, b))
+				}
+
+				r = append(r, mtest.CreateCursorResponse(0, "foo.bar", mtest.NextBatch))
+				mt.AddMockResponses(r...)
+			}
+
+			c := &Client{
+				db: mt.DB,
+			}
+
+			got, err := c.SearchUsers(context.Background(), tt.search)
+
+			if tt.expectedErr != nil {
+				assert.Nil(t, got)
+				assert.ErrorIs(t, err, tt.expectedErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+
+			command := mt.GetStartedEvent().Command
+			filter := command.Lookup("filter").Document()
+			assert.Equal(t, tt.search.Limit+1, command.Lookup("limit").Int64())
+			assert.Equal(t, bson.TypeNull, filter.Lookup("deleted_at").Type)
+			assert.Equal(t, int32(0), command.Lookup("projection", fieldSearchTerms).Int32())
+
+			if tt.expectedText != "" {
+				assert.Equal(t, tt.expectedText, filter.Lookup("$text", "$search").StringValue())
+				assert.Equal(t, "textScore", command.Lookup("sort", "score", "$meta").StringValue())
+				assert.Equal(t, "textScore", command.Lookup("projection", "score", "$meta").StringValue())
+				return
+			}
+
+			assert.Equal(t, int32(1), command.Lookup("sort", "nickname").Int32())
+			conditions, err := filter.Lookup("$and").Array().Values()
+			require.NoError(t, err)
+			assert.Len(t, conditions, len(tt.search.Filter))
+
+			prefixes, err := filter.Lookup(fieldSearchTerms, "$all").Array().Values()
+			require.NoError(t, err)
+			require.Len(t, prefixes, len(tt.expectedPrefix))
+			for i, expected := range tt.expectedPrefix {
+				pattern, options := prefixes[i].Regex()
+				assert.Equal(t, expected, pattern)
+				assert.Empty(t, options)
+			}
+		})
+	}
+}
+
+func TestSearchFilter_SearchTerms(t *testing.T) {
+	users := map[string][]string{
+		"jonathan": searchTerms("jd", "Jonathan", "Doe", "jd@mensah.com"),
+		"john":     searchTerms("johnny", "John", "Doe", "john@mensah.com"),
+		"mary":     searchTerms("ms", "Mary Ann", "Smith", "mary@mensah.com"),
+	}
+
+	tests := []struct {
+		text     string
+		expected []string
+	}{
+		{text: "jon", expected: []string{"jonathan", "john"}},
+		{text: "jon do", expected: []string{"jonathan", "john"}},
+		{text: "JOH", expected: []string{"john"}},
+		{text: "ann", expected: []string{"mary"}},
+		{text: "mensah.c", expected: []string{"jonathan", "john", "mary"}},
+		{text: "ensah", expected: []string{}},
+		{text: "j d", expected: []string{"jonathan", "john"}},
+		{text: "m s", expected: []string{"mary"}},
+		{text: "j s", expected: []string{}},
+		{text: "jo", expected: []string{"jonathan", "john"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			filter, _, err := searchFilter(repository.UserSearch{Text: tt.text})
+			require.NoError(t, err)
+
+			matched := make([]string, 0)
+			for _, name := range []string{"jonathan", "john", "mary"} {
+				if matchesSearch(t, filter, users[name]) {
+					matched = append(matched, name)
+				}
+			}
+
+			assert.Equal(t, tt.expected, matched)
+		})
+	}
+}
+
+// matchesSearch evaluates a search filter against the search terms of a user, the way mongo would. Text searches
+// match the users having any of the terms looked up, while prefix searches match the users having a term starting
+// with every prefix.
+func matchesSearch(t *testing.T, filter bson.M, terms []string) bool {
+	if text, ok := filter["$text"].(bson.M); ok {
+		for _, term := range strings.Fields(text["$search"].(string)) {
+			if contains(terms, term) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for _, prefix := range filter[fieldSearchTerms].(bson.M)["$all"].(bson.A) {
+		regex := prefix.(primitive.Regex)
+		require.Empty(t, regex.Options)
+
+		matched := false
+		for _, term := range terms {
+			matched = matched || regexp.MustCompile(regex.Pattern).MatchString(term)
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestClient_CountSearchedUsers(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("counts users matching the text", func(mt *mtest.T) {
+		defer teardown(mt)
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{{"n", 2}}))
+
+		c := &Client{db: mt.DB}
+
+		got, err := c.CountSearchedUsers(context.Background(), repository.UserSearch{Text: "jon do"})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), got)
+
+		command := mt.GetStartedEvent().Command
+		match := command.Lookup("pipeline").Array().Index(0).Value().Document().Lookup("$match").Document()
+		assert.Equal(t, "jon j500 do d000", match.Lookup("$text", "$search").StringValue())
+	})
+
+	mt.Run("error counting users", func(mt *mtest.T) {
+		defer teardown(mt)
+
+		mt.AddMockResponses(bson.D{{"ok", 0}})
+
+		c := &Client{db: mt.DB}
+
+		_, err := c.CountSearchedUsers(context.Background(), repository.UserSearch{Text: "jon do"})
+		assert.ErrorContains(t, err, errCountFailed)
+	})
+}
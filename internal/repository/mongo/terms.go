@@ -0,0 +1,198 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// fieldSearchTerms is the field of users holding the terms they are searched by
+	fieldSearchTerms = "search_terms"
+
+	// minPrefixLength is the length of the shortest prefixes of words stored as search terms. Shorter prefixes are
+	// matched against the stored terms with a regex instead.
+	minPrefixLength = 3
+	// maxPrefixLength is the length of the longest prefixes of words stored as search terms, bounding the terms of
+	// long words such as the domains of emails
+	maxPrefixLength = 20
+
+	soundexLength  = 4
+	soundexSkipped = '-'
+
+	// backfillBatchSize is the most users given their search terms by a single BulkWrite
+	backfillBatchSize = 500
+
+	errBackfillFailed = "failed to store the search terms of users in mongo"
+)
+
+// soundexCodes maps the consonants of words to their soundex digit, h and w being skipped. Other characters, such as
+// vowels, separate consonants.
+var soundexCodes = map[rune]byte{
+	'b': '1', 'f': '1', 'p': '1', 'v': '1',
+	'c': '2', 'g': '2', 'j': '2', 'k': '2', 'q': '2', 's': '2', 'x': '2', 'z': '2',
+	'd': '3', 't': '3',
+	'l': '4',
+	'm': '5', 'n': '5',
+	'r': '6',
+	'h': soundexSkipped, 'w': soundexSkipped,
+}
+
+// searchedUser represents the fields of a user their search terms are made of
+type searchedUser struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	Nickname  string             `bson:"nickname"`
+	FirstName string             `bson:"first_name"`
+	LastName  string             `bson:"last_name"`
+	Email     string             `bson:"email"`
+}
+
+// backfillSearchTerms stores the search terms of the users stored before users had them. Users updated meanwhile
+// already have theirs and are left untouched, as are the versions of users, since their terms are not part of them.
+func (c *Client) backfillSearchTerms(ctx context.Context) error {
+	missing := bson.M{fieldSearchTerms: bson.M{"$exists": false}}
+	opts := options.Find().SetProjection(bson.M{"nickname": 1, "first_name": 1, "last_name": 1, "email": 1})
+
+	cursor, err := c.db.Collection(collectionUsers).Find(ctx, missing, opts)
+	if err != nil {
+		return fmt.Errorf("%s: %w", errBackfillFailed, repositoryError(err))
+	}
+	defer cursor.Close(ctx)
+
+	models := make([]mongo.WriteModel, 0, backfillBatchSize)
+	for cursor.Next(ctx) {
+		var user searchedUser
+		if err = cursor.Decode(&user); err != nil {
+			return fmt.Errorf("%s: %w", errBackfillFailed, err)
+		}
+
+		terms := searchTerms(user.Nickname, user.FirstName, user.LastName, user.Email)
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": user.ID, fieldSearchTerms: bson.M{"$exists": false}}).
+			SetUpdate(bson.M{"$set": bson.M{fieldSearchTerms: terms}}))
+
+		if len(models) == backfillBatchSize {
+			if err = c.writeSearchTerms(ctx, models); err != nil {
+				return err
+			}
+			models = models[:0]
+		}
+	}
+	if err = cursor.Err(); err != nil {
+		return fmt.Errorf("%s: %w", errBackfillFailed, repositoryError(err))
+	}
+
+	return c.writeSearchTerms(ctx, models)
+}
+
+// writeSearchTerms applies a batch of updates storing the search terms of users
+func (c *Client) writeSearchTerms(ctx context.Context, models []mongo.WriteModel) error {
+	if len(models) == 0 {
+		return nil
+	}
+
+	opts := options.BulkWrite().SetOrdered(false)
+	if _, err := c.db.Collection(collectionUsers).BulkWrite(ctx, models, opts); err != nil {
+		return fmt.Errorf("%s: %w", errBackfillFailed, repositoryError(err))
+	}
+
+	return nil
+}
+
+// searchTerms returns the terms a user is searched by, given the values of their searched fields: the words of the
+// values, the prefixes of these words so that partial words match as they are typed, and the soundex code of the words
+// so that words spelled differently but sounding alike match, such as "jon" and "John". Terms are lowercase and unique.
+func searchTerms(values ...string) []string {
+	terms := make([]string, 0)
+	seen := make(map[string]bool)
+
+	addTerm := func(term string) {
+		if term != "" && !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+
+	for _, value := range values {
+		for _, word := range splitWords(value) {
+			addTerm(word)
+			for _, prefix := range wordPrefixes(word) {
+				addTerm(prefix)
+			}
+			addTerm(soundex(word))
+		}
+	}
+
+	return terms
+}
+
+// queryTerms returns the terms a text search of the given words looks up: the words and their soundex code
+func queryTerms(words []string) []string {
+	terms := make([]string, 0, 2*len(words))
+	for _, word := range words {
+		terms = append(terms, word)
+		if code := soundex(word); code != "" && code != word {
+			terms = append(terms, code)
+		}
+	}
+
+	return terms
+}
+
+// splitWords returns the lowercase words of a value, words being separated by anything but letters and digits such as
+// spaces, dots or the @ of emails, the way text indexes split them
+func splitWords(value string) []string {
+	return strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// wordPrefixes returns the prefixes of a word from minPrefixLength to maxPrefixLength characters, shorter than the word
+func wordPrefixes(word string) []string {
+	prefixes := make([]string, 0)
+
+	length := 0
+	for i := range word {
+		if length >= minPrefixLength && length <= maxPrefixLength {
+			prefixes = append(prefixes, word[:i])
+		}
+		length++
+	}
+
+	return prefixes
+}
+
+// soundex returns the soundex code of a lowercase word: its first letter followed by three digits encoding its
+// consonants, such as "j500" for both "jon" and "john". Words not starting with a latin letter have no code.
+func soundex(word string) string {
+	first, size := utf8.DecodeRuneInString(word)
+	if first < 'a' || first > 'z' {
+		return ""
+	}
+
+	code := []byte{byte(first)}
+	last := soundexCodes[first]
+	for _, r := range word[size:] {
+		digit, ok := soundexCodes[r]
+		if !ok {
+			last = 0
+			continue
+		}
+		if digit == soundexSkipped || digit == last {
+			continue
+		}
+
+		code = append(code, digit)
+		last = digit
+	}
+
+	// codes are padded with zeros
+	return string(append(code, "000"...)[:soundexLength])
+}
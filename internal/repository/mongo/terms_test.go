@@ -0,0 +1,172 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestSearchTerms(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []string
+		expected []string
+	}{
+		{
+			name:     "words, prefixes and soundex codes",
+			values:   []string{"Jonathan", "Doe"},
+			expected: []string{"jonathan", "jon", "jona", "jonat", "jonath", "jonatha", "j535", "doe", "d000"},
+		},
+		{
+			name:     "words of emails",
+			values:   []string{"jd@mensah.com"},
+			expected: []string{"jd", "j300", "mensah", "men", "mens", "mensa", "m520", "com", "c500"},
+		},
+		{
+			name:     "unique terms",
+			values:   []string{"Ann", "ann"},
+			expected: []string{"ann", "a500"},
+		},
+		{
+			name:     "words not starting with a latin letter",
+			values:   []string{"Émile 42"},
+			expected: []string{"émile", "émi", "émil", "42"},
+		},
+		{
+			name:     "no words",
+			values:   []string{"", " .@ "},
+			expected: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, searchTerms(tt.values...))
+		})
+	}
+}
+
+func TestWordPrefixes(t *testing.T) {
+	assert.Empty(t, wordPrefixes("jon"))
+	assert.Equal(t, []string{"joh"}, wordPrefixes("john"))
+
+	prefixes := wordPrefixes("abcdefghijklmnopqrstuvwxyz")
+	assert.Equal(t, "abc", prefixes[0])
+	assert.Equal(t, "abcdefghijklmnopqrst", prefixes[len(prefixes)-1])
+}
+
+func TestSoundex(t *testing.T) {
+	tests := map[string]string{
+		"jon":      "j500",
+		"john":     "j500",
+		"do":       "d000",
+		"doe":      "d000",
+		"robert":   "r163",
+		"rupert":   "r163",
+		"ashcraft": "a261",
+		"tymczak":  "t522",
+		"pfister":  "p236",
+		"émile":    "",
+		"42":       "",
+	}
+	for word, expected := range tests {
+		t.Run(word, func(t *testing.T) {
+			assert.Equal(t, expected, soundex(word))
+		})
+	}
+}
+
+func TestClient_backfillSearchTerms(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	mt.Run("stores the search terms of users without them", func(mt *mtest.T) {
+		defer teardown(mt)
+
+		oid := primitive.NewObjectID()
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{
+				{"_id", oid}, {"nickname", "jd"}, {"first_name", "John"}, {"last_name", "Doe"},
+			}),
+			mtest.CreateSuccessResponse(),
+		)
+
+		c := &Client{db: mt.DB}
+
+		require.NoError(t, c.backfillSearchTerms(context.Background()))
+
+		mt.GetStartedEvent()
+		update := mt.GetStartedEvent().Command
+		assert.Equal(t, collectionUsers, update.Lookup("update").StringValue())
+
+		statement := update.Lookup("updates").Array().Index(0).Value().Document()
+		assert.Equal(t, oid, statement.Lookup("q", "_id").ObjectID())
+		assert.False(t, statement.Lookup("q", fieldSearchTerms, "$exists").Boolean())
+
+		var terms []string
+		require.NoError(t, statement.Lookup("u", "$set", fieldSearchTerms).Unmarshal(&terms))
+		assert.Equal(t, searchTerms("jd", "John", "Doe", ""), terms)
+	})
+
+	mt.Run("error storing search terms", func(mt *mtest.T) {
+		defer teardown(mt)
+
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{{"_id", primitive.NewObjectID()}}),
+			bson.D{{"ok", 0}},
+		)
+
+		c := &Client{db: mt.DB}
+
+		assert.ErrorContains(t, c.backfillSearchTerms(context.Background()), errBackfillFailed)
+	})
+}
+
+func TestClient_SearchTermsOnWrite(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	expected := searchTerms("jd", "John", "Doe", "jd@mensah.com")
+
+	mt.Run("creating a user stores their search terms", func(mt *mtest.T) {
+		defer teardown(mt)
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+
+		c := &Client{db: mt.DB}
+
+		_, err := c.CreateUser(context.Background(), &api.UserCreateData{
+			Nickname: "jd", FirstName: "John", LastName: "Doe", Email: "jd@mensah.com",
+		})
+		require.NoError(t, err)
+
+		var terms []string
+		document := mt.GetStartedEvent().Command.Lookup("documents").Array().Index(0).Value()
+		require.NoError(t, document.Document().Lookup(fieldSearchTerms).Unmarshal(&terms))
+		assert.Equal(t, expected, terms)
+	})
+
+	mt.Run("replacing a user stores their search terms", func(mt *mtest.T) {
+		defer teardown(mt)
+
+		mt.AddMockResponses(bson.D{{"ok", 1}, {"value", bson.D{{"_id", hexID1}}}})
+
+		c := &Client{db: mt.DB}
+
+		_, err := c.ReplaceUser(context.Background(), hexID1, &repository.UserReplacement{
+			Nickname: "jd", FirstName: "John", LastName: "Doe", Email: "jd@mensah.com",
+		}, nil)
+		require.NoError(t, err)
+
+		var terms []string
+		update := mt.GetStartedEvent().Command.Lookup("update")
+		require.NoError(t, update.Document().Lookup("$set", fieldSearchTerms).Unmarshal(&terms))
+		assert.Equal(t, expected, terms)
+	})
+}
@@ -0,0 +1,54 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+
+	"github.com/danielMensah/user-management/internal/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// nextVersion increases the version of a user, starting users stored before they were versioned at version 1
+var nextVersion = bson.M{"version": 1}
+
+// versionedFilter returns the filter of the user with the given id, only matching the user at one of the given
+// versions unless none are given. Users stored before they were versioned have no version and are at version 0.
+func versionedFilter(pid primitive.ObjectID, versions []int64) bson.M {
+	filter := userFilter(pid)
+	if len(versions) == 0 {
+		return filter
+	}
+
+	in := bson.A{}
+	for _, version := range versions {
+		in = append(in, version)
+		if version == 0 {
+			in = append(in, nil)
+		}
+	}
+	filter["version"] = bson.M{"$in": in}
+
+	return filter
+}
+
+// missedWrite returns why a write to the user with the given id matched no user: either the user does not exist, or
+// it is at another version than the ones the write was conditioned on
+func (c *Client) missedWrite(ctx context.Context, pid primitive.ObjectID, versions []int64, err error) error {
+	if !errors.Is(err, mongo.ErrNoDocuments) || len(versions) == 0 {
+		return repositoryError(err)
+	}
+
+	count, err := c.db.Collection(collectionUsers).CountDocuments(ctx, userFilter(pid), options.Count().SetLimit(1))
+	if err != nil {
+		return repositoryError(err)
+	}
+
+	if count == 0 {
+		return repository.ErrNotFound
+	}
+
+	return repository.ErrVersionMismatch
+}
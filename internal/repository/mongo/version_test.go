@@ -0,0 +1,94 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/danielMensah/user-management/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestVersionedFilter(t *testing.T) {
+	pid, err := primitive.ObjectIDFromHex(hexID1)
+	require.NoError(t, err)
+
+	assert.Equal(t, bson.M{"_id": pid, "deleted_at": nil}, versionedFilter(pid, nil))
+	assert.Equal(t,
+		bson.M{"_id": pid, "deleted_at": nil, "version": bson.M{"$in": bson.A{int64(3), int64(4)}}},
+		versionedFilter(pid, []int64{3, 4}),
+	)
+	assert.Equal(t,
+		bson.M{"_id": pid, "deleted_at": nil, "version": bson.M{"$in": bson.A{int64(0), nil}}},
+		versionedFilter(pid, []int64{0}),
+	)
+}
+
+func TestClient_VersionedWrites(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	defer mt.Close()
+
+	count := func(n int) bson.D {
+		return mtest.CreateCursorResponse(0, "foo.bar", mtest.FirstBatch, bson.D{{"n", n}})
+	}
+	missed := bson.D{{"ok", 1}, {"value", nil}}
+
+	writes := map[string]func(c *Client) error{
+		"replace": func(c *Client) error {
+			_, err := c.ReplaceUser(context.Background(), hexID1, &repository.UserReplacement{}, []int64{3})
+			return err
+		},
+		"delete": func(c *Client) error {
+			return c.DeleteUser(context.Background(), hexID1, []int64{3})
+		},
+	}
+
+	tests := []struct {
+		name          string
+		mockResponses []bson.D
+		expectedErr   error
+	}{
+		{
+			name:          "user at the version",
+			mockResponses: []bson.D{{{"ok", 1}, {"value", bson.D{{"_id", hexID1}, {"version", 4}}}}},
+		},
+		{
+			name:          "user at another version",
+			mockResponses: []bson.D{missed, count(1)},
+			expectedErr:   repository.ErrVersionMismatch,
+		},
+		{
+			name:          "user not found",
+			mockResponses: []bson.D{missed, count(0)},
+			expectedErr:   repository.ErrNotFound,
+		},
+	}
+	for write, apply := range writes {
+		for _, tt := range tests {
+			mt.Run(write+" "+tt.name, func(mt *mtest.T) {
+				defer teardown(mt)
+
+				mt.AddMockResponses(tt.mockResponses...)
+
+				c := &Client{db: mt.DB}
+
+				err := apply(c)
+				if tt.expectedErr != nil {
+					assert.ErrorIs(t, err, tt.expectedErr)
+				} else {
+					assert.NoError(t, err)
+				}
+
+				command := mt.GetStartedEvent().Command
+				versions, err := command.Lookup("query", "version", "$in").Array().Values()
+				require.NoError(t, err)
+				assert.Equal(t, int64(3), versions[0].Int64())
+
+				assert.Equal(t, int32(1), command.Lookup("update", "$inc", "version").Int32())
+			})
+		}
+	}
+}
@@ -2,14 +2,242 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/danielMensah/user-management/internal/api"
+	"github.com/danielMensah/user-management/internal/pagination"
+	"github.com/danielMensah/user-management/internal/query"
 )
 
-// UserRepository represents the user repository contract
+var (
+	// ErrNotFound is returned when the requested document does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidID is returned when the given id is not a valid document id
+	ErrInvalidID = errors.New("invalid id")
+	// ErrConflict is returned when a document conflicts with an existing one. The error is a *ConflictError
+	// when the conflicting field is known.
+	ErrConflict = errors.New("conflict")
+	// ErrUnavailable is returned when the database cannot be reached or does not respond in time
+	ErrUnavailable = errors.New("unavailable")
+	// ErrVersionMismatch is returned when a write is conditioned on versions of a document and the document is at
+	// another version
+	ErrVersionMismatch = errors.New("version mismatch")
+)
+
+// ConflictError is returned when a document has the same value as an existing one for a field that must be unique
+type ConflictError struct {
+	Field string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s: %s already exists", ErrConflict, e.Field)
+}
+
+// Is reports that a ConflictError is an ErrConflict
+func (e *ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
+
+// DefaultRoles are the roles given to newly created users
+var DefaultRoles = api.Roles{"user"}
+
+// InitialVersion is the version of newly created users. Every change to a user increases its version, and users stored
+// before they were versioned are at version 0.
+const InitialVersion int64 = 1
+
+// Credentials represents the stored data needed to authenticate a user
+type Credentials struct {
+	ID       string    `bson:"_id"`
+	Email    string    `bson:"email"`
+	Password string    `bson:"password"`
+	Nickname string    `bson:"nickname"`
+	Country  string    `bson:"country"`
+	Roles    api.Roles `bson:"roles"`
+
+	EmailVerified bool    `bson:"email_verified"`
+	MFA           MFA     `bson:"mfa"`
+	Lockout       Lockout `bson:"lockout"`
+}
+
+// UserReplacement represents the editable fields of a user, which replace the stored ones even when empty. The
+// password is a hash, only replaced when given. A changed email is no longer verified.
+type UserReplacement struct {
+	FirstName string `bson:"first_name"`
+	LastName  string `bson:"last_name"`
+	Nickname  string `bson:"nickname"`
+	Email     string `bson:"email"`
+	Country   string `bson:"country"`
+	Password  string `bson:"password,omitempty"`
+
+	EmailChanged bool `bson:"-"`
+}
+
+// Lockout represents the failed logins of a user and whether they are locked out because of them
+type Lockout struct {
+	FailedAttempts int        `bson:"failed_attempts"`
+	LastFailedAt   *time.Time `bson:"last_failed_at,omitempty"`
+	LockedUntil    *time.Time `bson:"locked_until,omitempty"`
+	Lockouts       int        `bson:"lockouts"`
+}
+
+// MFA represents the multi-factor authentication settings of a user. Secrets are stored encrypted
+// and recovery codes hashed.
+type MFA struct {
+	Enabled       bool     `bson:"enabled"`
+	Secret        string   `bson:"secret,omitempty"`
+	PendingSecret string   `bson:"pending_secret,omitempty"`
+	RecoveryCodes []string `bson:"recovery_codes,omitempty"`
+	LastUsedStep  int64    `bson:"last_used_step,omitempty"`
+}
+
+// RefreshToken represents a stored refresh token. Tokens issued by rotating a refresh token
+// belong to the same family as the token they replace.
+type RefreshToken struct {
+	ID        string     `bson:"_id,omitempty"`
+	Hash      string     `bson:"hash"`
+	FamilyID  string     `bson:"family_id"`
+	UserID    string     `bson:"user_id"`
+	CreatedAt time.Time  `bson:"created_at"`
+	ExpiresAt time.Time  `bson:"expires_at"`
+	UsedAt    *time.Time `bson:"used_at,omitempty"`
+	RevokedAt *time.Time `bson:"revoked_at,omitempty"`
+}
+
+// Purposes of the one-time tokens emailed to users
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailVerification = "email_verification"
+)
+
+// OneTimeToken represents a stored single-use token sent to a user for the given purpose
+type OneTimeToken struct {
+	ID        string     `bson:"_id,omitempty"`
+	Hash      string     `bson:"hash"`
+	Purpose   string     `bson:"purpose"`
+	UserID    string     `bson:"user_id"`
+	Email     string     `bson:"email,omitempty"`
+	CreatedAt time.Time  `bson:"created_at"`
+	ExpiresAt time.Time  `bson:"expires_at"`
+	UsedAt    *time.Time `bson:"used_at,omitempty"`
+}
+
+// UserQuery represents the users GetUsers lists, the order they are listed in and the page of them to return. Given a
+// cursor issued for the same sort, the page starts right after or before the user it points to, otherwise it skips
+// Skip users. Users only have the given fields, and every field when none are given. Soft deleted users are left out
+// unless IncludeDeleted is set.
+type UserQuery struct {
+	Filter         query.Filter
+	Sort           query.Sort
+	Fields         []query.Field
+	Limit          int64
+	Skip           int64
+	Cursor         *pagination.Cursor
+	IncludeDeleted bool
+}
+
+// UserSearch represents a search of the users matching a filter by text, and the page of results to return
+type UserSearch struct {
+	Text   string
+	Filter query.Filter
+	Limit  int64
+	Skip   int64
+}
+
+// CreatedUser represents the outcome of creating one of several users at once, either the id of the created user or
+// the error it could not be created with
+type CreatedUser struct {
+	ID  string
+	Err error
+}
+
+// UserPage represents a page of users in the order they were listed in, whether there are more users after it and
+// the cursors of the pages around it when there are any
+type UserPage struct {
+	Users []api.User
+	More  bool
+	Next  *pagination.Cursor
+	Prev  *pagination.Cursor
+}
+
+// Repository represents the contract of every repository of the service
+type Repository interface {
+	UserRepository
+	RefreshTokenRepository
+	RoleRepository
+	OneTimeTokenRepository
+	MFARepository
+	LockoutRepository
+
+	// EnsureIndexes creates the indexes the repository relies on, such as the unique and search indexes of users.
+	// Nicknames are only unique when asked to.
+	EnsureIndexes(ctx context.Context, uniqueNicknames bool) error
+}
+
+// UserRepository represents the user repository contract. Writes given versions of a user only apply when the user
+// is at one of them, and fail with ErrVersionMismatch otherwise. Writes given no version always apply.
+//
+// Deleting a user soft deletes them: soft deleted users are not found by any read or write but listing users including
+// them, restoring them and purging them.
 type UserRepository interface {
-	GetUsers(ctx context.Context, params api.GetUsersParams) (*[]api.User, error)
+	GetUsers(ctx context.Context, q UserQuery) (*UserPage, error)
+	CountUsers(ctx context.Context, q UserQuery) (int64, error)
+	ExportUsers(ctx context.Context, q UserQuery, each func(user *api.User) error) error
+	SearchUsers(ctx context.Context, search UserSearch) (*UserPage, error)
+	CountSearchedUsers(ctx context.Context, search UserSearch) (int64, error)
+	GetUser(ctx context.Context, id string, fields []query.Field) (*api.User, error)
+	GetCredentialsByEmail(ctx context.Context, email string) (*Credentials, error)
+	GetCredentialsByID(ctx context.Context, id string) (*Credentials, error)
 	CreateUser(ctx context.Context, user *api.UserCreateData) (string, error)
-	UpdateUser(ctx context.Context, id string, data *api.UserUpdateData) (*api.User, error)
-	DeleteUser(ctx context.Context, id string) error
+	CreateUsers(ctx context.Context, users []*api.UserCreateData) ([]CreatedUser, error)
+	ReplaceUser(ctx context.Context, id string, user *UserReplacement, versions []int64) (*api.User, error)
+	SetPassword(ctx context.Context, id, hash string) error
+	DeleteUser(ctx context.Context, id string, versions []int64) error
+	RestoreUser(ctx context.Context, id string) (*api.User, error)
+	PurgeDeletedUsers(ctx context.Context, before time.Time) (int64, error)
+	SetUserRoles(ctx context.Context, id string, roles api.Roles) (*api.User, error)
+	VerifyEmail(ctx context.Context, id, email string) error
+	RehashPassword(ctx context.Context, id, current, hash string) error
+}
+
+// RefreshTokenRepository represents the refresh token repository contract
+type RefreshTokenRepository interface {
+	CreateRefreshToken(ctx context.Context, token *RefreshToken) error
+	GetRefreshToken(ctx context.Context, hash string) (*RefreshToken, error)
+	UseRefreshToken(ctx context.Context, hash string) (*RefreshToken, error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	RevokeUserRefreshTokens(ctx context.Context, userID string) error
+}
+
+// RoleRepository represents the custom role repository contract
+type RoleRepository interface {
+	GetRoles(ctx context.Context) ([]api.RoleDefinition, error)
+	GetRolesByName(ctx context.Context, names []string) ([]api.RoleDefinition, error)
+	CreateRole(ctx context.Context, role *api.RoleCreateData) (*api.RoleDefinition, error)
+	UpdateRole(ctx context.Context, name string, data *api.RoleUpdateData) (*api.RoleDefinition, error)
+	DeleteRole(ctx context.Context, name string) error
+}
+
+// OneTimeTokenRepository represents the one-time token repository contract
+type OneTimeTokenRepository interface {
+	CreateOneTimeToken(ctx context.Context, token *OneTimeToken) error
+	GetOneTimeToken(ctx context.Context, hash, purpose string) (*OneTimeToken, error)
+	UseOneTimeToken(ctx context.Context, hash, purpose string) (*OneTimeToken, error)
+}
+
+// MFARepository represents the multi-factor authentication repository contract
+type MFARepository interface {
+	SetPendingMFASecret(ctx context.Context, userID, secret string) error
+	EnableMFA(ctx context.Context, userID, secret string, step int64, recoveryCodes []string) error
+	SetMFARecoveryCodes(ctx context.Context, userID string, recoveryCodes []string) error
+	UseMFARecoveryCode(ctx context.Context, userID, recoveryCode string) error
+	UseMFAStep(ctx context.Context, userID string, step int64) error
+}
+
+// LockoutRepository represents the failed login repository contract
+type LockoutRepository interface {
+	RecordFailedLogin(ctx context.Context, userID string, since time.Time) (int, error)
+	LockUser(ctx context.Context, userID string, until time.Time) error
+	UnlockUser(ctx context.Context, userID string) error
 }